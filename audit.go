@@ -3,13 +3,10 @@
 package axonflow
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
 )
@@ -163,26 +160,17 @@ func (c *AxonFlowClient) SearchAuditLogs(ctx context.Context, req *AuditSearchRe
 		reqBody["offset"] = req.Offset
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal audit search request: %w", err)
-	}
-
 	fullURL := c.config.Endpoint + "/api/v1/audit/search"
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewReader(bodyBytes))
+	httpReq, err := newJSONRequest("POST", fullURL, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create audit search request: %w", err)
+		return nil, fmt.Errorf("failed to marshal audit search request: %w", err)
 	}
+	httpReq.retrySafe = true // search is read-only
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	c.addAuthHeaders(httpReq)
+	c.debugf("Audit search - Limit: %d, Offset: %d", req.Limit, req.Offset)
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Audit search - Limit: %d, Offset: %d", req.Limit, req.Offset)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("audit search request failed: %w", err)
 	}
@@ -194,10 +182,7 @@ func (c *AxonFlowClient) SearchAuditLogs(ctx context.Context, req *AuditSearchRe
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	// The API returns an array directly, wrap it in a response
@@ -206,9 +191,7 @@ func (c *AxonFlowClient) SearchAuditLogs(ctx context.Context, req *AuditSearchRe
 		// Try parsing as wrapped response
 		var wrappedResp AuditSearchResponse
 		if wrapErr := json.Unmarshal(body, &wrappedResp); wrapErr == nil {
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Audit search returned %d entries", len(wrappedResp.Entries))
-			}
+			c.debugf("Audit search returned %d entries", len(wrappedResp.Entries))
 			return &wrappedResp, nil
 		}
 		return nil, fmt.Errorf("failed to unmarshal audit search response: %w", err)
@@ -221,9 +204,7 @@ func (c *AxonFlowClient) SearchAuditLogs(ctx context.Context, req *AuditSearchRe
 		Offset:  req.Offset,
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Audit search returned %d entries", len(result.Entries))
-	}
+	c.debugf("Audit search returned %d entries", len(result.Entries))
 
 	return result, nil
 }
@@ -254,7 +235,7 @@ func (c *AxonFlowClient) SearchAuditLogs(ctx context.Context, req *AuditSearchRe
 //	result, err = client.GetAuditLogsByTenant(context.Background(), "tenant-abc", opts)
 func (c *AxonFlowClient) GetAuditLogsByTenant(ctx context.Context, tenantID string, opts *AuditQueryOptions) (*AuditSearchResponse, error) {
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenantID is required")
+		return nil, newValidationError("tenantID", "tenantID is required")
 	}
 
 	// Apply defaults
@@ -275,19 +256,12 @@ func (c *AxonFlowClient) GetAuditLogsByTenant(ctx context.Context, tenantID stri
 	fullURL := fmt.Sprintf("%s/api/v1/audit/tenant/%s?limit=%d&offset=%d",
 		c.config.Endpoint, tenantID, limit, offset)
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create tenant audit request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	c.addAuthHeaders(httpReq)
+	httpReq := newRequest("GET", fullURL)
+	httpReq.contentType = "application/json"
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Get audit logs for tenant: %s (limit: %d, offset: %d)", tenantID, limit, offset)
-	}
+	c.debugf("Get audit logs for tenant: %s (limit: %d, offset: %d)", tenantID, limit, offset)
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("tenant audit request failed: %w", err)
 	}
@@ -299,10 +273,7 @@ func (c *AxonFlowClient) GetAuditLogsByTenant(ctx context.Context, tenantID stri
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	// The API returns an array directly, wrap it in a response
@@ -311,9 +282,7 @@ func (c *AxonFlowClient) GetAuditLogsByTenant(ctx context.Context, tenantID stri
 		// Try parsing as wrapped response
 		var wrappedResp AuditSearchResponse
 		if wrapErr := json.Unmarshal(body, &wrappedResp); wrapErr == nil {
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Tenant audit returned %d entries", len(wrappedResp.Entries))
-			}
+			c.debugf("Tenant audit returned %d entries", len(wrappedResp.Entries))
 			return &wrappedResp, nil
 		}
 		return nil, fmt.Errorf("failed to unmarshal tenant audit response: %w", err)
@@ -326,26 +295,7 @@ func (c *AxonFlowClient) GetAuditLogsByTenant(ctx context.Context, tenantID stri
 		Offset:  offset,
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Tenant audit returned %d entries", len(result.Entries))
-	}
+	c.debugf("Tenant audit returned %d entries", len(result.Entries))
 
 	return result, nil
 }
-
-// addAuthHeaders adds OAuth2-style authentication headers to the request.
-// Sets X-Tenant-ID for all policy APIs (uses ClientID).
-// Sets Authorization: Basic base64(clientId:clientSecret) when credentials are complete.
-func (c *AxonFlowClient) addAuthHeaders(req *http.Request) {
-	// ClientID is required for policy APIs - it sets the tenant context
-	if c.config.ClientID != "" {
-		req.Header.Set("X-Tenant-ID", c.config.ClientID)
-		// ClientSecret is optional for community mode but required for enterprise
-		if c.config.ClientSecret != "" {
-			credentials := base64.StdEncoding.EncodeToString(
-				[]byte(c.config.ClientID + ":" + c.config.ClientSecret),
-			)
-			req.Header.Set("Authorization", "Basic "+credentials)
-		}
-	}
-}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -197,12 +198,12 @@ func TestSearchAuditLogs(t *testing.T) {
 			t.Fatal("expected error for 400 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 400 {
-			t.Errorf("expected status 400, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 400 {
+			t.Errorf("expected status 400, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -222,12 +223,12 @@ func TestSearchAuditLogs(t *testing.T) {
 			t.Fatal("expected error for 401 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 401 {
-			t.Errorf("expected status 401, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 401 {
+			t.Errorf("expected status 401, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -240,6 +241,7 @@ func TestSearchAuditLogs(t *testing.T) {
 
 		client := NewClient(AxonFlowConfig{
 			Endpoint: server.URL,
+			Retry:    noRetry,
 		})
 
 		_, err := client.SearchAuditLogs(context.Background(), nil)
@@ -247,12 +249,12 @@ func TestSearchAuditLogs(t *testing.T) {
 			t.Fatal("expected error for 500 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 500 {
-			t.Errorf("expected status 500, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 500 {
+			t.Errorf("expected status 500, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -480,12 +482,12 @@ func TestGetAuditLogsByTenant(t *testing.T) {
 			t.Fatal("expected error for 400 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 400 {
-			t.Errorf("expected status 400, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 400 {
+			t.Errorf("expected status 400, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -505,12 +507,12 @@ func TestGetAuditLogsByTenant(t *testing.T) {
 			t.Fatal("expected error for 403 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 403 {
-			t.Errorf("expected status 403, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 403 {
+			t.Errorf("expected status 403, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -530,12 +532,12 @@ func TestGetAuditLogsByTenant(t *testing.T) {
 			t.Fatal("expected error for 404 response")
 		}
 
-		httpErr, ok := err.(*httpError)
-		if !ok {
-			t.Fatalf("expected httpError, got %T", err)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
 		}
-		if httpErr.statusCode != 404 {
-			t.Errorf("expected status 404, got %d", httpErr.statusCode)
+		if apiErr.StatusCode != 404 {
+			t.Errorf("expected status 404, got %d", apiErr.StatusCode)
 		}
 	})
 
@@ -0,0 +1,288 @@
+// Authentication of requests to AxonFlow
+package axonflow
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to requests sent to AxonFlow. Authenticate is
+// called for every attempt, so it may return a different credential each time (e.g.
+// a refreshed OAuth2 token). An error aborts the attempt.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// Invalidator is implemented by authenticators whose credentials can expire. When
+// AxonFlow answers 401, the client calls Invalidate with the rejected request and
+// sends the request once more with fresh credentials.
+type Invalidator interface {
+	Invalidate(rejected *http.Request)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(ctx context.Context, req *http.Request) error
+
+// Authenticate calls f(ctx, req).
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, req *http.Request) error {
+	return f(ctx, req)
+}
+
+// BasicAuth sends Authorization: Basic base64(clientID:clientSecret). It is the
+// default when ClientID and ClientSecret are set and no other authentication is
+// configured.
+func BasicAuth(clientID, clientSecret string) Authenticator {
+	credentials := "Basic " + base64.StdEncoding.EncodeToString([]byte(clientID+":"+clientSecret))
+	return AuthenticatorFunc(func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", credentials)
+		return nil
+	})
+}
+
+// APIKeyAuth sends Authorization: Bearer apiKey.
+func APIKeyAuth(apiKey string) Authenticator {
+	credentials := "Bearer " + apiKey
+	return AuthenticatorFunc(func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", credentials)
+		return nil
+	})
+}
+
+// authenticator returns the Authenticator selected by config: Authenticator if set,
+// then APIKey, then OAuth2 at TokenURL, then Basic credentials. It returns nil when no
+// credentials are configured (community mode).
+func (config AxonFlowConfig) authenticator(httpClient *http.Client) Authenticator {
+	switch {
+	case config.Authenticator != nil:
+		return config.Authenticator
+	case config.APIKey != "":
+		return APIKeyAuth(config.APIKey)
+	case config.TokenURL != "":
+		return NewOAuth2Authenticator(OAuth2Config{
+			TokenURL:     config.TokenURL,
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Scopes:       config.Scopes,
+			HTTPClient:   httpClient,
+		})
+	case config.ClientID != "" && config.ClientSecret != "":
+		return BasicAuth(config.ClientID, config.ClientSecret)
+	}
+	return nil
+}
+
+// addAuthHeaders sets X-Tenant-ID (the ClientID, required by policy APIs) and the
+// credentials of the client's Authenticator.
+func (c *AxonFlowClient) addAuthHeaders(ctx context.Context, req *http.Request) error {
+	if c.config.ClientID != "" {
+		req.Header.Set("X-Tenant-ID", c.config.ClientID)
+	}
+	if c.auth == nil {
+		return nil
+	}
+	if err := c.auth.Authenticate(ctx, req); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	return nil
+}
+
+// OAuth2Config configures the OAuth2 client credentials flow (RFC 6749 section 4.4).
+type OAuth2Config struct {
+	TokenURL     string   // Token endpoint
+	ClientID     string   // OAuth2 client ID
+	ClientSecret string   // OAuth2 client secret
+	Scopes       []string // Requested scopes (optional)
+	// EndpointParams are added to the token request, e.g. "audience"
+	EndpointParams url.Values
+	// AuthInBody sends the client credentials as form parameters instead of HTTP
+	// Basic authentication, for token endpoints that require it
+	AuthInBody bool
+	// RefreshBefore is how long before expiry a token is refreshed (default: 1m, or
+	// half the token's lifetime if that is shorter). Refreshes happen in the
+	// background while the current token is still valid.
+	RefreshBefore time.Duration
+	// HTTPClient performs token requests (default: a client with a 30s timeout)
+	HTTPClient *http.Client
+}
+
+// OAuth2Authenticator obtains and caches OAuth2 access tokens with the client
+// credentials grant and sends them as bearer tokens. Concurrent callers share a
+// single token request.
+type OAuth2Authenticator struct {
+	config OAuth2Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	token    *oauth2Token
+	inflight *tokenFetch
+}
+
+type oauth2Token struct {
+	accessToken string
+	expiry      time.Time // zero if the server gave no lifetime
+	refreshAt   time.Time
+}
+
+// tokenFetch is a token request shared by concurrent callers.
+type tokenFetch struct {
+	done  chan struct{}
+	token *oauth2Token
+	err   error
+}
+
+// NewOAuth2Authenticator creates an authenticator for the client credentials flow.
+func NewOAuth2Authenticator(config OAuth2Config) *OAuth2Authenticator {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = time.Minute
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &OAuth2Authenticator{config: config, now: time.Now}
+}
+
+// Authenticate sets Authorization: Bearer with a valid access token.
+func (a *OAuth2Authenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate discards the cached token if rejected was sent with it, so that the next
+// call fetches a new one. Stale rejections (a newer token is already cached) are
+// ignored, so concurrent 401s cause a single refresh.
+func (a *OAuth2Authenticator) Invalidate(rejected *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != nil && rejected.Header.Get("Authorization") == "Bearer "+a.token.accessToken {
+		a.token = nil
+	}
+}
+
+// Token returns a valid access token, fetching one if needed. A token that is due for
+// refresh but not yet expired is returned immediately while a refresh runs in the
+// background.
+func (a *OAuth2Authenticator) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	now := a.now()
+	token := a.token
+	if token != nil && (token.refreshAt.IsZero() || now.Before(token.refreshAt)) {
+		a.mu.Unlock()
+		return token.accessToken, nil
+	}
+
+	fetch := a.inflight
+	if fetch == nil {
+		fetch = &tokenFetch{done: make(chan struct{})}
+		a.inflight = fetch
+		// The fetch is shared, so it must not be cancelled with this caller's context
+		go a.fetch(context.WithoutCancel(ctx), fetch)
+	}
+	a.mu.Unlock()
+
+	if token != nil && now.Before(token.expiry) {
+		return token.accessToken, nil
+	}
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if fetch.err != nil {
+		return "", fetch.err
+	}
+	return fetch.token.accessToken, nil
+}
+
+// fetch requests a token and publishes the result to waiting callers.
+func (a *OAuth2Authenticator) fetch(ctx context.Context, f *tokenFetch) {
+	f.token, f.err = a.requestToken(ctx)
+
+	a.mu.Lock()
+	if f.err == nil {
+		a.token = f.token
+	}
+	a.inflight = nil
+	a.mu.Unlock()
+
+	close(f.done)
+}
+
+// requestToken performs the client credentials grant.
+func (a *OAuth2Authenticator) requestToken(ctx context.Context) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+	for key, values := range a.config.EndpointParams {
+		form[key] = values
+	}
+	if a.config.AuthInBody {
+		form.Set("client_id", a.config.ClientID)
+		form.Set("client_secret", a.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("OAuth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !a.config.AuthInBody {
+		req.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(a.config.ClientSecret))
+	}
+
+	start := a.now()
+	resp, err := a.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OAuth2 token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("OAuth2 token request: failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("OAuth2 token request: %w", newAPIError(resp, body))
+	}
+
+	var result struct {
+		AccessToken string      `json:"access_token"`
+		TokenType   string      `json:"token_type"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("OAuth2 token request: failed to parse response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("OAuth2 token request: response has no access_token")
+	}
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		return nil, fmt.Errorf("OAuth2 token request: unsupported token type %q", result.TokenType)
+	}
+
+	token := &oauth2Token{accessToken: result.AccessToken}
+	if seconds, err := result.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		lifetime := time.Duration(seconds) * time.Second
+		token.expiry = start.Add(lifetime)
+		early := a.config.RefreshBefore
+		if early > lifetime/2 {
+			early = lifetime / 2
+		}
+		token.refreshAt = token.expiry.Add(-early)
+	}
+	return token, nil
+}
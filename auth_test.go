@@ -0,0 +1,296 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer issues tokens "token-1", "token-2", ... with the given lifetime.
+type tokenServer struct {
+	*httptest.Server
+	issued int32
+	delay  time.Duration
+	form   chan map[string]string
+}
+
+func newTokenServer(t *testing.T, expiresIn int, delay time.Duration) *tokenServer {
+	t.Helper()
+	ts := &tokenServer{delay: delay, form: make(chan map[string]string, 100)}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		r.ParseForm()
+		ts.form <- map[string]string{"grant_type": r.PostForm.Get("grant_type"), "scope": r.PostForm.Get("scope")}
+		time.Sleep(ts.delay)
+		n := atomic.AddInt32(&ts.issued, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// newBearerServer serves policy lists to requests carrying one of the accepted tokens.
+func newBearerServer(t *testing.T, accepted ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, token := range accepted {
+			if r.Header.Get("Authorization") == "Bearer "+token {
+				json.NewEncoder(w).Encode(staticPoliciesResponse{})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid token"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	server := newBearerServer(t, "my-api-key")
+	client, err := New(server.URL, WithCredentials("tenant", ""), WithAPIKey("my-api-key"), WithoutRetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Errorf("expected the API key to be accepted, got %v", err)
+	}
+}
+
+func TestCustomAuthenticator(t *testing.T) {
+	var gotTenant, gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get("X-Tenant-ID")
+		gotSignature = r.Header.Get("X-Signature")
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "tenant",
+		Authenticator: AuthenticatorFunc(func(_ context.Context, req *http.Request) error {
+			req.Header.Set("X-Signature", req.Method+" "+req.URL.Path)
+			return nil
+		}),
+	})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatal(err)
+	}
+	if gotTenant != "tenant" || gotSignature != "GET /api/v1/static-policies" {
+		t.Errorf("unexpected headers: tenant %q, signature %q", gotTenant, gotSignature)
+	}
+}
+
+func TestAuthenticatorErrorAbortsRequest(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	boom := errors.New("vault sealed")
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "tenant",
+		Retry:    RetryConfig{MaxAttempts: 1},
+		Authenticator: AuthenticatorFunc(func(context.Context, *http.Request) error {
+			return boom
+		}),
+	})
+	if _, err := client.ListStaticPolicies(nil); !errors.Is(err, boom) {
+		t.Errorf("expected the authenticator error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no request to be sent, got %d", calls)
+	}
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	tokens := newTokenServer(t, 3600, 0)
+	server := newBearerServer(t, "token-1")
+
+	client, err := New(server.URL,
+		WithCredentials("client", "s3cret"),
+		WithOAuth2(tokens.URL, "policies:read", "audit:write"),
+		WithoutRetry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ListStaticPolicies(nil); err != nil {
+			t.Fatalf("request %d failed: %v", i+1, err)
+		}
+	}
+	if tokens.issued != 1 {
+		t.Errorf("expected the token to be cached, got %d token requests", tokens.issued)
+	}
+	form := <-tokens.form
+	if form["grant_type"] != "client_credentials" || form["scope"] != "policies:read audit:write" {
+		t.Errorf("unexpected token request: %v", form)
+	}
+}
+
+func TestOAuth2SingleFlight(t *testing.T) {
+	tokens := newTokenServer(t, 3600, 50*time.Millisecond)
+	auth := NewOAuth2Authenticator(OAuth2Config{TokenURL: tokens.URL, ClientID: "client", ClientSecret: "s3cret"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := auth.Token(context.Background()); err != nil || token != "token-1" {
+				t.Errorf("unexpected token %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tokens.issued != 1 {
+		t.Errorf("expected concurrent callers to share one token request, got %d", tokens.issued)
+	}
+}
+
+func TestOAuth2RefreshesBeforeExpiry(t *testing.T) {
+	tokens := newTokenServer(t, 600, 0)
+	auth := NewOAuth2Authenticator(OAuth2Config{
+		TokenURL:      tokens.URL,
+		ClientID:      "client",
+		ClientSecret:  "s3cret",
+		RefreshBefore: time.Minute,
+	})
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	if token, _ := auth.Token(context.Background()); token != "token-1" {
+		t.Fatalf("expected token-1, got %q", token)
+	}
+
+	// Within the refresh window: the current token is still served while a new one
+	// is fetched in the background
+	now = now.Add(9*time.Minute + 30*time.Second)
+	if token, _ := auth.Token(context.Background()); token != "token-1" {
+		t.Errorf("expected the still-valid token during refresh, got %q", token)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&tokens.issued) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if token, _ := auth.Token(context.Background()); token != "token-2" {
+		t.Errorf("expected the refreshed token, got %q", token)
+	}
+
+	// Expired: callers wait for a new token
+	now = now.Add(time.Hour)
+	if token, _ := auth.Token(context.Background()); token != "token-3" {
+		t.Errorf("expected a new token after expiry, got %q", token)
+	}
+}
+
+func TestOAuth2RetriesOnceOn401(t *testing.T) {
+	tokens := newTokenServer(t, 3600, 0)
+	// token-1 has been revoked server-side
+	server := newBearerServer(t, "token-2")
+
+	client, err := New(server.URL,
+		WithCredentials("client", "s3cret"),
+		WithOAuth2(tokens.URL),
+		WithoutRetry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("expected the request to succeed with a refreshed token, got %v", err)
+	}
+	if tokens.issued != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokens.issued)
+	}
+
+	// A persistent 401 is returned after a single refresh
+	rejecting := newBearerServer(t)
+	client, _ = New(rejecting.URL, WithCredentials("client", "s3cret"), WithOAuth2(tokens.URL), WithoutRetry())
+	before := atomic.LoadInt32(&tokens.issued)
+	if _, err := client.ListStaticPolicies(nil); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+	if got := atomic.LoadInt32(&tokens.issued) - before; got != 2 {
+		t.Errorf("expected exactly one refresh, got %d token requests", got)
+	}
+}
+
+func TestOAuth2InvalidClient(t *testing.T) {
+	tokens := newTokenServer(t, 3600, 0)
+	server := newBearerServer(t, "token-1")
+
+	client, _ := New(server.URL, WithCredentials("client", "wrong"), WithOAuth2(tokens.URL), WithoutRetry())
+	resp, err := client.ExecuteQuery("user", "hello", "chat", nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized from the token endpoint, got %v (response %+v)", err, resp)
+	}
+}
+
+func TestOAuth2Validation(t *testing.T) {
+	_, err := New("https://agent.example.com", WithCredentials("client", ""), WithOAuth2("https://idp.example.com/token"))
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "TokenURL" {
+		t.Errorf("expected a TokenURL validation error, got %v", err)
+	}
+
+	_, err = New("https://agent.example.com", WithCredentials("client", "secret"), WithOAuth2("idp/token"))
+	if !errors.As(err, &valErr) || valErr.Field != "TokenURL" {
+		t.Errorf("expected a TokenURL validation error, got %v", err)
+	}
+}
+
+func TestAuthFromEnvironment(t *testing.T) {
+	t.Setenv(EnvEndpoint, "https://agent.example.com")
+	t.Setenv(EnvClientID, "client")
+	t.Setenv(EnvClientSecret, "s3cret")
+	t.Setenv(EnvTokenURL, "https://idp.example.com/token")
+	t.Setenv(EnvScopes, "a b,c")
+	t.Setenv(EnvAPIKey, "")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, ok := client.auth.(*OAuth2Authenticator)
+	if !ok {
+		t.Fatalf("expected an OAuth2 authenticator, got %T", client.auth)
+	}
+	if len(auth.config.Scopes) != 3 || auth.config.TokenURL != "https://idp.example.com/token" {
+		t.Errorf("unexpected OAuth2 config: %+v", auth.config)
+	}
+
+	t.Setenv(EnvAPIKey, "key")
+	client, _ = NewClientFromEnv()
+	if _, ok := client.auth.(*OAuth2Authenticator); ok {
+		t.Error("expected AXONFLOW_API_KEY to take precedence")
+	}
+	var dump map[string]interface{}
+	json.Unmarshal([]byte(client.DumpConfig()), &dump)
+	if dump["api_key"] != maskedSecret {
+		t.Errorf("expected the API key to be masked, got %v", dump["api_key"])
+	}
+}
@@ -4,18 +4,15 @@
 package axonflow
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"math"
+	"log/slog"
 	"net/http"
 	"os"
-	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,28 +27,110 @@ type AxonFlowConfig struct {
 	MapTimeout   time.Duration // Timeout for MAP operations (default: 120s) - MAP involves multiple LLM calls
 	Retry        RetryConfig   // Retry configuration
 	Cache        CacheConfig   // Cache configuration
-}
 
-// RetryConfig configures retry behavior
+	CircuitBreaker CircuitBreakerConfig // Circuit breaker around the Agent (default: disabled)
+	FailurePolicy  FailurePolicy        // Behavior per request type when AxonFlow is unavailable
+	RateLimit      RateLimitConfig      // Client-side throttling on the reported quota (default: disabled)
+
+	// SingleFlight makes concurrent identical ExecuteQuery calls, and concurrent
+	// identical pre-checks, share one HTTP call and its result (default: false).
+	// Calls are identical when they would have the same cache key. Each caller gets
+	// its own copy of the result.
+	SingleFlight bool
+
+	// Logger receives structured logs. If nil, Debug selects a debug-level text logger
+	// on the standard logger's output; otherwise nothing is logged.
+	Logger *slog.Logger
+	// LogContent includes query, prompt and response text in logs. By default only
+	// its length is logged.
+	LogContent bool
+	// Observer receives callbacks for every call, e.g. a *Metrics collector
+	Observer Observer
+	// MaxPendingAudits bounds the number of AuditLLMCallAsync audits in flight
+	// (default: 1000). Further audits are dropped.
+	MaxPendingAudits int
+	// BatchConcurrency bounds the requests PreCheckBatch has in flight (default: 8)
+	BatchConcurrency int
+
+	// HTTPClient, if set, is used as the basis for all API calls. Its Transport is
+	// wrapped by Middleware; if its Timeout is zero, Timeout applies. MAP operations
+	// use a copy with MapTimeout.
+	HTTPClient *http.Client
+	// Transport is the base RoundTripper when HTTPClient is nil or has no Transport
+	// (default: an http.Transport honouring NODE_TLS_REJECT_UNAUTHORIZED=0)
+	Transport http.RoundTripper
+	// Middleware wraps the transport for every request. Middleware[0] is outermost:
+	// it sees each request first and each response last.
+	Middleware []Middleware
+	// Authenticator adds credentials to every request. If nil, APIKey, TokenURL or
+	// ClientID/ClientSecret select a built-in one (see Authenticator)
+	Authenticator Authenticator
+	// APIKey is sent as a bearer token when Authenticator is nil
+	APIKey string
+	// TokenURL enables the OAuth2 client credentials flow when Authenticator and APIKey
+	// are unset: ClientID and ClientSecret are exchanged for bearer tokens at TokenURL
+	TokenURL string
+	// Scopes are requested from TokenURL
+	Scopes []string
+
+	// TLS configures CA roots, client certificates, pinning and the minimum version
+	// for both the regular and the MAP HTTP client (default: system roots, TLS 1.2+)
+	TLS *TLSConfig
+
+	// Endpoints lists further Agent URLs serving the same deployment, e.g. in other
+	// zones. Requests are spread over Endpoint and Endpoints according to Failover, and
+	// unhealthy agents are taken out of rotation. If Endpoint is empty, Endpoints[0]
+	// is used as Endpoint.
+	Endpoints []string
+	// Failover configures endpoint selection, health probing and ejection
+	Failover FailoverConfig
+
+	// NegotiateCapabilities fetches the server's capabilities before the first call to
+	// an enterprise feature, so that unavailable features fail with
+	// ErrFeatureUnavailable without a request (see GetCapabilities)
+	NegotiateCapabilities bool
+}
+
+// RetryConfig configures retry behavior. Retries apply to every API call; see
+// RetryPolicy and IsRetryable for which failures are retried.
 type RetryConfig struct {
 	Enabled      bool          // Enable retry logic (default: true)
 	MaxAttempts  int           // Maximum retry attempts (default: 3)
 	InitialDelay time.Duration // Initial delay between retries (default: 1s)
+	MaxDelay     time.Duration // Maximum delay between retries (default: 30s)
+	Policy       RetryPolicy   // Custom retry policy; overrides the fields above (default: ExponentialBackoff)
 }
 
-// CacheConfig configures caching behavior
+// CacheConfig configures caching of ExecuteQuery responses
 type CacheConfig struct {
-	Enabled bool          // Enable caching (default: true)
-	TTL     time.Duration // Cache TTL (default: 60s)
+	Enabled    bool          // Enable caching (default: true)
+	TTL        time.Duration // Cache TTL (default: 60s)
+	MaxEntries int           // Maximum entries in the built-in cache (default: 10000)
+	MaxBytes   int64         // Maximum total size of the built-in cache (default: unlimited)
+	// Backend replaces the built-in in-memory LRU cache, e.g. with a shared cache
+	Backend Cache
 }
 
 // AxonFlowClient represents the SDK for connecting to AxonFlow platform
 type AxonFlowClient struct {
 	config        AxonFlowConfig
+	logger        *slog.Logger
+	observer      Observer
 	httpClient    *http.Client
-	mapHttpClient *http.Client // Separate client with longer timeout for MAP operations
-	cache         *cache
-	sessionCookie string // Session cookie for Customer Portal authentication
+	mapHttpClient *http.Client    // Separate client with longer timeout for MAP operations
+	cache         Cache           // nil when caching is disabled
+	retryPolicy   RetryPolicy     // nil when retries are disabled
+	breaker       *circuitBreaker // nil when the circuit breaker is disabled
+	balancer      *balancer       // nil with a single endpoint
+	limiter       *rateLimiter    // quotas reported by AxonFlow
+	flights       *flightGroup    // identical calls in flight (nil unless SingleFlight)
+	noBatch       atomic.Bool     // the server has no batch pre-check endpoint
+	decisions     *decisionStore  // last-known decisions for FailCached (nil if unused)
+	auth          Authenticator   // nil when no credentials are configured
+	sessionCookie string          // Session cookie for Customer Portal authentication
+	life          *lifecycle      // background work and Close state
+	caps          capabilityCache // server capabilities, once fetched
+	pooled        bool            // created by a TenantPool, which owns the shared resources
 }
 
 // ============================================================================
@@ -94,6 +173,10 @@ type ClientResponse struct {
 	Blocked     bool                   `json:"blocked"`
 	BlockReason string                 `json:"block_reason,omitempty"`
 	PolicyInfo  *PolicyEvaluationInfo  `json:"policy_info,omitempty"`
+
+	// Fallback is set when AxonFlow was unavailable and this response was produced by
+	// the FailurePolicy (FailOpen or FailCached) rather than by AxonFlow.
+	Fallback FailureMode `json:"-"`
 }
 
 // PolicyEvaluationInfo contains policy evaluation metadata
@@ -163,6 +246,10 @@ type ConnectorResponse struct {
 	Redacted       bool        `json:"redacted,omitempty"`
 	RedactedFields []string    `json:"redacted_fields,omitempty"`
 	PolicyInfo     *PolicyInfo `json:"policy_info,omitempty"`
+
+	// Fallback is set when AxonFlow was unavailable and this response was produced by
+	// the FailurePolicy (FailOpen or FailCached) rather than by AxonFlow.
+	Fallback FailureMode `json:"-"`
 }
 
 // PolicyInfo contains information about policy evaluation results.
@@ -291,6 +378,12 @@ type PolicyApprovalResult struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	// BlockReason contains the reason for blocking (if not approved)
 	BlockReason string `json:"block_reason,omitempty"`
+	// Fallback is set when AxonFlow was unavailable and this result was produced by
+	// the FailurePolicy (FailOpen or FailCached) rather than by AxonFlow.
+	Fallback FailureMode `json:"-"`
+	// Local is set when the result was produced by a LocalEvaluator rather than by
+	// AxonFlow.
+	Local bool `json:"-"`
 }
 
 // AuditResult represents the result from audit logging in Gateway Mode
@@ -299,6 +392,8 @@ type AuditResult struct {
 	Success bool `json:"success"`
 	// AuditID is a unique ID for reference
 	AuditID string `json:"audit_id"`
+	// Fallback is FailOpen when AxonFlow was unavailable and the audit was dropped
+	Fallback FailureMode `json:"-"`
 }
 
 // PlanExecutionResponse represents the result of plan execution
@@ -325,74 +420,31 @@ type StepResult struct {
 	Duration string      `json:"duration,omitempty"`
 }
 
-// Cache entry
-type cacheEntry struct {
-	value      interface{}
-	expiration time.Time
-}
-
-// Simple in-memory cache
-type cache struct {
-	mu      sync.RWMutex
-	entries map[string]*cacheEntry
-	ttl     time.Duration
-}
-
-func newCache(ttl time.Duration) *cache {
-	c := &cache{
-		entries: make(map[string]*cacheEntry),
-		ttl:     ttl,
-	}
-	// Start cleanup goroutine
-	go c.cleanup()
-	return c
+// NewClient creates a new AxonFlow client with the given configuration. It never fails:
+// config is not validated (see AxonFlowConfig.Validate) and a zero Retry.MaxAttempts or
+// Cache.TTL enables retries or caching regardless of Enabled. New has neither quirk.
+func NewClient(config AxonFlowConfig) *AxonFlowClient {
+	config.enableByDefault()
+	return newClient(config)
 }
 
-func (c *cache) get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	entry, exists := c.entries[key]
-	if !exists {
-		return nil, false
+// enableByDefault turns retries and caching on unless Retry.MaxAttempts or Cache.TTL
+// is set, as NewClient always has. Use New to have Enabled respected as given.
+func (config *AxonFlowConfig) enableByDefault() {
+	if config.Retry.MaxAttempts == 0 {
+		config.Retry.Enabled = true
 	}
-
-	if time.Now().After(entry.expiration) {
-		return nil, false
+	if config.Cache.TTL == 0 {
+		config.Cache.Enabled = true
 	}
-
-	return entry.value, true
 }
 
-func (c *cache) set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.entries[key] = &cacheEntry{
-		value:      value,
-		expiration: time.Now().Add(c.ttl),
+// setDefaults fills in unset values. It never changes Retry.Enabled or Cache.Enabled.
+func (config *AxonFlowConfig) setDefaults() {
+	if config.Endpoint == "" && len(config.Endpoints) > 0 {
+		config.Endpoint = config.Endpoints[0]
+		config.Endpoints = config.Endpoints[1:]
 	}
-}
-
-func (c *cache) cleanup() {
-	ticker := time.NewTicker(c.ttl)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		c.mu.Lock()
-		now := time.Now()
-		for key, entry := range c.entries {
-			if now.After(entry.expiration) {
-				delete(c.entries, key)
-			}
-		}
-		c.mu.Unlock()
-	}
-}
-
-// NewClient creates a new AxonFlow client with the given configuration
-func NewClient(config AxonFlowConfig) *AxonFlowClient {
-	// Set defaults
 	if config.Mode == "" {
 		config.Mode = "production"
 	}
@@ -407,46 +459,93 @@ func NewClient(config AxonFlowConfig) *AxonFlowClient {
 	}
 	if config.Retry.MaxAttempts == 0 {
 		config.Retry.MaxAttempts = 3
-		config.Retry.Enabled = true
+	}
+	if config.Retry.MaxDelay == 0 {
+		config.Retry.MaxDelay = 30 * time.Second
 	}
 	if config.Cache.TTL == 0 {
 		config.Cache.TTL = 60 * time.Second
-		config.Cache.Enabled = true
 	}
+}
 
-	// Configure TLS
-	tlsConfig := &tls.Config{}
-	if os.Getenv("NODE_TLS_REJECT_UNAUTHORIZED") == "0" {
-		tlsConfig.InsecureSkipVerify = true
-	}
+// newClient builds a client, applying defaults to config first.
+func newClient(config AxonFlowConfig) *AxonFlowClient {
+	config.setDefaults()
 
-	transport := &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
+	httpClient, mapHttpClient := newHTTPClients(config)
 
 	client := &AxonFlowClient{
-		config: config,
-		httpClient: &http.Client{
-			Timeout:   config.Timeout,
-			Transport: transport,
-		},
-		mapHttpClient: &http.Client{
-			Timeout:   config.MapTimeout,
-			Transport: transport,
-		},
+		config:        config,
+		logger:        newLogger(config),
+		observer:      config.Observer,
+		life:          newLifecycle(config.MaxPendingAudits),
+		httpClient:    httpClient,
+		mapHttpClient: mapHttpClient,
 	}
 
+	if client.observer == nil {
+		client.observer = NopObserver{}
+	}
+
+	client.auth = config.authenticator(httpClient)
+
 	if config.Cache.Enabled {
-		client.cache = newCache(config.Cache.TTL)
+		client.cache = config.Cache.Backend
+		if client.cache == nil {
+			client.cache = NewLRUCache(config.Cache.MaxEntries, config.Cache.MaxBytes)
+		}
+	}
+
+	if config.CircuitBreaker.Enabled {
+		client.breaker = newCircuitBreaker(config.CircuitBreaker)
+	}
+
+	client.limiter = newRateLimiter(config.RateLimit)
+
+	if config.SingleFlight {
+		client.flights = &flightGroup{}
+	}
+
+	if client.balancer = newBalancer(config, client.logger); client.balancer != nil {
+		go client.balancer.run(client.life.ctx, httpClient)
 	}
 
-	if config.Debug {
-		log.Printf("[AxonFlow] Client initialized - Mode: %s, Endpoint: %s, MapTimeout: %v", config.Mode, config.Endpoint, config.MapTimeout)
+	if config.FailurePolicy.usesCache() {
+		client.decisions = newDecisionStore(config.FailurePolicy.CachedDecisions, config.FailurePolicy.CachedDecisionTTL)
 	}
 
+	if config.Retry.Enabled {
+		client.retryPolicy = config.Retry.Policy
+		if client.retryPolicy == nil {
+			client.retryPolicy = ExponentialBackoff{
+				MaxAttempts:  config.Retry.MaxAttempts,
+				InitialDelay: config.Retry.InitialDelay,
+				MaxDelay:     config.Retry.MaxDelay,
+			}
+		}
+	}
+
+	client.logger.Debug("AxonFlow client initialized",
+		"mode", config.Mode,
+		"endpoint", config.Endpoint,
+		"endpoints", len(config.endpoints()),
+		"map_timeout", config.MapTimeout)
+
 	return client
 }
 
+// defaultTransport returns the transport used when none is configured.
+func defaultTransport() http.RoundTripper {
+	tlsConfig := &tls.Config{}
+	if os.Getenv("NODE_TLS_REJECT_UNAUTHORIZED") == "0" {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+}
+
 // NewClientSimple creates a client with simple parameters (backward compatible)
 func NewClientSimple(endpoint, clientID, clientSecret string) *AxonFlowClient {
 	return NewClient(AxonFlowConfig{
@@ -473,23 +572,38 @@ func Sandbox(apiKey string) *AxonFlowClient {
 
 // ExecuteQuery sends a query through AxonFlow platform with policy enforcement.
 // If userToken is empty, it defaults to "anonymous" for audit purposes.
-func (c *AxonFlowClient) ExecuteQuery(userToken, query, requestType string, context map[string]interface{}) (*ClientResponse, error) {
+//
+// ExecuteQuery uses context.Background(); use ExecuteQueryContext to bound the call
+// with a deadline or cancel it.
+func (c *AxonFlowClient) ExecuteQuery(userToken, query, requestType string, queryContext map[string]interface{}) (*ClientResponse, error) {
+	return c.ExecuteQueryContext(context.Background(), userToken, query, requestType, queryContext)
+}
+
+// ExecuteQueryContext is like ExecuteQuery but carries a context. Cancelling ctx aborts
+// the in-flight HTTP request and any pending retry backoff.
+func (c *AxonFlowClient) ExecuteQueryContext(ctx context.Context, userToken, query, requestType string, queryContext map[string]interface{}) (*ClientResponse, error) {
+	if err := c.checkOpen(ctx); err != nil {
+		return nil, err
+	}
+
 	// Default to "anonymous" if userToken is empty (community mode)
 	if userToken == "" {
 		userToken = "anonymous"
 	}
 
 	// Generate cache key
-	cacheKey := fmt.Sprintf("%s:%s:%s", requestType, query, userToken)
+	cacheKey := c.queryCacheKey(requestType, query, userToken, queryContext)
 
 	// Check cache if enabled
-	if c.cache != nil {
-		if cached, found := c.cache.get(cacheKey); found {
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Cache hit for query: %s", query[:min(50, len(query))])
-			}
-			return cached.(*ClientResponse), nil
+	if c.cache != nil && !cacheBypassed(ctx) {
+		if cached, found := c.cachedResponse(ctx, cacheKey); found {
+			c.logger.DebugContext(ctx, "AxonFlow cache hit",
+				"request_type", requestType,
+				c.content("query", query))
+			c.observer.OnCacheHit(ctx, ObserverEvent{RequestType: requestType})
+			return cached, nil
 		}
+		c.observer.OnCacheMiss(ctx, ObserverEvent{RequestType: requestType})
 	}
 
 	req := ClientRequest{
@@ -497,108 +611,104 @@ func (c *AxonFlowClient) ExecuteQuery(userToken, query, requestType string, cont
 		UserToken:   userToken,
 		ClientID:    c.config.ClientID,
 		RequestType: requestType,
-		Context:     context,
+		Context:     queryContext,
 	}
 
+	value, err, _ := c.collapse(ctx, cacheKey, requestType, func(ctx context.Context) (interface{}, error) {
+		return c.executeRequest(ctx, req)
+	})
 	var resp *ClientResponse
-	var err error
-
-	// Execute with retry if enabled
-	if c.config.Retry.Enabled {
-		resp, err = c.executeWithRetry(req)
-	} else {
-		resp, err = c.executeRequest(req)
+	if err == nil {
+		resp = value.(*ClientResponse)
+		if c.flights != nil {
+			// The response may be shared: each caller gets its own copy
+			resp = resp.clone()
+		}
 	}
-
-	// Handle fail-open in production mode
-	if err != nil && c.config.Mode == "production" && c.isAxonFlowError(err) {
-		if c.config.Debug {
-			log.Printf("[AxonFlow] AxonFlow unavailable, failing open: %v", err)
+	decision := cacheKey
+
+	// Apply the failure policy (fail-open by default in production mode). A cancelled
+	// or expired caller context is never treated as an AxonFlow outage.
+	if err != nil && ctx.Err() == nil && isUnavailableError(err) {
+		switch c.failureMode(requestType, true) {
+		case FailOpen:
+			c.logger.WarnContext(ctx, "AxonFlow unavailable, failing open",
+				"request_type", requestType,
+				"error", err)
+			c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: requestType, Err: err, Fallback: FailOpen})
+			// Return a success response indicating the request was allowed through
+			return &ClientResponse{
+				Success:  true,
+				Data:     nil,
+				Error:    unavailableMessage(err),
+				Fallback: FailOpen,
+			}, nil
+		case FailCached:
+			if cached, ok := c.recall(decision); ok {
+				c.logger.WarnContext(ctx, "AxonFlow unavailable, using cached decision",
+					"request_type", requestType,
+					"error", err)
+				c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: requestType, Err: err, Fallback: FailCached})
+				fallback := *cached.(*ClientResponse)
+				fallback.Fallback = FailCached
+				return &fallback, nil
+			}
 		}
-		// Return a success response indicating the request was allowed through
-		return &ClientResponse{
-			Success: true,
-			Data:    nil,
-			Error:   fmt.Sprintf("AxonFlow unavailable (fail-open): %v", err),
-		}, nil
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	c.remember(decision, resp)
+
+	if resp.Blocked {
+		c.observer.OnPolicyBlock(ctx, ObserverEvent{
+			RequestType: requestType,
+			BlockReason: resp.BlockReason,
+			PolicyInfo:  resp.PolicyInfo,
+		})
+	}
+
 	// Cache successful responses
 	if c.cache != nil && resp.Success {
-		c.cache.set(cacheKey, resp)
+		c.cacheResponse(ctx, cacheKey, resp)
 	}
 
 	return resp, nil
 }
 
-// executeWithRetry executes a request with exponential backoff retry
-func (c *AxonFlowClient) executeWithRetry(req ClientRequest) (*ClientResponse, error) {
-	var lastErr error
-
-	for attempt := 0; attempt < c.config.Retry.MaxAttempts; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: delay * 2^(attempt-1)
-			delay := time.Duration(float64(c.config.Retry.InitialDelay) * math.Pow(2, float64(attempt-1)))
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Retry attempt %d/%d after %v", attempt+1, c.config.Retry.MaxAttempts, delay)
-			}
-			time.Sleep(delay)
-		}
-
-		resp, err := c.executeRequest(req)
-		if err == nil {
-			return resp, nil
-		}
-
-		lastErr = err
+// sleepContext pauses for d or until ctx is done, whichever comes first.
+// It returns ctx.Err() when the wait was cut short.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-		// Don't retry on client errors (4xx)
-		if httpErr, ok := err.(*httpError); ok && httpErr.statusCode >= 400 && httpErr.statusCode < 500 {
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Client error (4xx), not retrying: %v", err)
-			}
-			break
-		}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-
-	return nil, fmt.Errorf("request failed after %d attempts: %w", c.config.Retry.MaxAttempts, lastErr)
-}
-
-// httpError represents an HTTP error with status code
-type httpError struct {
-	statusCode int
-	message    string
 }
 
-func (e *httpError) Error() string {
-	return fmt.Sprintf("HTTP %d: %s", e.statusCode, e.message)
-}
-
-// executeRequest executes a single request without retry
-func (c *AxonFlowClient) executeRequest(req ClientRequest) (*ClientResponse, error) {
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequest("POST", c.config.Endpoint+"/api/request", bytes.NewReader(reqBody))
+// executeRequest sends a query to the Agent. Queries are policy-evaluated before
+// anything else happens, so they are retried like idempotent requests.
+func (c *AxonFlowClient) executeRequest(ctx context.Context, req ClientRequest) (*ClientResponse, error) {
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/request", req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	httpReq.retrySafe = true
+	httpReq.requestType = req.RequestType
+	httpReq.user = req.UserToken
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	c.addAuthHeaders(httpReq)
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Sending request - Type: %s, Query: %s", req.RequestType, req.Query[:min(50, len(req.Query))])
-	}
+	c.logger.DebugContext(ctx, "Sending AxonFlow query",
+		"request_type", req.RequestType,
+		c.content("query", req.Query))
 
 	startTime := time.Now()
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -611,29 +721,24 @@ func (c *AxonFlowClient) executeRequest(req ClientRequest) (*ClientResponse, err
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// [DEBUG] Log raw response body before unmarshaling
-	log.Printf("[SDK-DEBUG] Raw response body size: %d bytes", len(body))
-	if len(body) > 0 && len(body) <= 500 {
-		log.Printf("[SDK-DEBUG] Raw response body (full): %s", string(body))
-	} else if len(body) > 500 {
-		log.Printf("[SDK-DEBUG] Raw response body (first 500 chars): %s...", string(body[:500]))
-	}
-
 	// For 403 (Forbidden), the request was blocked by policy - parse the response body
 	if resp.StatusCode == http.StatusForbidden {
 		var clientResp ClientResponse
 		if err := json.Unmarshal(body, &clientResp); err != nil {
 			return nil, fmt.Errorf("failed to parse blocked response: %w", err)
 		}
+		c.logger.InfoContext(ctx, "AxonFlow query blocked by policy",
+			"request_type", req.RequestType,
+			"request_id", resp.Header.Get("X-Request-ID"),
+			"status", resp.StatusCode,
+			"duration", duration,
+			"block_reason", clientResp.BlockReason)
 		// The response contains blocked=true and block_reason from the agent
 		return &clientResp, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var clientResp ClientResponse
@@ -649,7 +754,6 @@ func (c *AxonFlowClient) executeRequest(req ClientRequest) (*ClientResponse, err
 			if dataSuccess, hasSuccess := dataMap["success"].(bool); hasSuccess && !dataSuccess {
 				// Orchestrator execution failed - extract error message
 				if errorMsg, hasError := dataMap["error"].(string); hasError {
-					log.Printf("[SDK-DEBUG] Detected orchestrator failure in data.error: %s", errorMsg)
 					// Surface the error by setting the Error field and marking success as false
 					clientResp.Error = errorMsg
 					clientResp.Success = false
@@ -658,86 +762,74 @@ func (c *AxonFlowClient) executeRequest(req ClientRequest) (*ClientResponse, err
 			// Also check if data.result or data.data exists and use it if Result is empty
 			if clientResp.Result == "" {
 				if dataResult, hasResult := dataMap["result"].(string); hasResult && dataResult != "" {
-					log.Printf("[SDK-DEBUG] Using data.result field (length: %d)", len(dataResult))
 					clientResp.Result = dataResult
 				} else if dataData, hasData := dataMap["data"].(string); hasData && dataData != "" {
-					log.Printf("[SDK-DEBUG] Using data.data field (length: %d)", len(dataData))
 					clientResp.Result = dataData
 				}
 			}
 			// Check if data.plan_id exists and use it if PlanID is empty
 			if clientResp.PlanID == "" {
 				if dataPlanID, hasPlanID := dataMap["plan_id"].(string); hasPlanID && dataPlanID != "" {
-					log.Printf("[SDK-DEBUG] Using data.plan_id field: %s", dataPlanID)
 					clientResp.PlanID = dataPlanID
 				}
 			}
 			// Check if data.metadata exists and use it if Metadata is empty
 			if clientResp.Metadata == nil {
 				if dataMetadata, hasMetadata := dataMap["metadata"].(map[string]interface{}); hasMetadata {
-					log.Printf("[SDK-DEBUG] Using data.metadata field")
 					clientResp.Metadata = dataMetadata
 				}
 			}
 		}
 	}
 
-	// [DEBUG] Log unmarshaled response details
-	log.Printf("[SDK-DEBUG] Unmarshaled - Success: %v, Blocked: %v, BlockReason: %s, Result length: %d, PlanID: %s",
-		clientResp.Success, clientResp.Blocked, clientResp.BlockReason, len(clientResp.Result), clientResp.PlanID)
-	if len(clientResp.Result) > 0 {
-		if len(clientResp.Result) <= 100 {
-			log.Printf("[SDK-DEBUG] Result (full): %s", clientResp.Result)
-		} else {
-			log.Printf("[SDK-DEBUG] Result (first 100 chars): %s...", clientResp.Result[:100])
-		}
-	} else {
-		log.Printf("[SDK-DEBUG] Result is empty!")
-	}
-	log.Printf("[SDK-DEBUG] Metadata keys: %v", getMetadataKeys(clientResp.Metadata))
+	c.logger.DebugContext(ctx, "AxonFlow query response received",
+		"request_type", req.RequestType,
+		"request_id", resp.Header.Get("X-Request-ID"),
+		"status", resp.StatusCode,
+		"duration", duration,
+		"success", clientResp.Success,
+		"blocked", clientResp.Blocked,
+		"plan_id", clientResp.PlanID,
+		"metadata_keys", getMetadataKeys(clientResp.Metadata),
+		c.content("result", clientResp.Result))
 
-	// If we detected an error in the data field, log it prominently
+	// Surface orchestrator failures reported inside a successful response
 	if clientResp.Error != "" {
-		log.Printf("[SDK-DEBUG] Error field set: %s", clientResp.Error)
-	}
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Response received - Success: %v, Duration: %v", clientResp.Success, duration)
+		c.logger.WarnContext(ctx, "AxonFlow query returned an error",
+			"request_type", req.RequestType,
+			"request_id", resp.Header.Get("X-Request-ID"),
+			c.content("error", clientResp.Error))
 	}
 
 	return &clientResp, nil
 }
 
-// isAxonFlowError checks if an error is from AxonFlow (vs the AI provider)
-func (c *AxonFlowClient) isAxonFlowError(err error) bool {
-	errMsg := err.Error()
-	return strings.Contains(errMsg, "AxonFlow") ||
-		strings.Contains(errMsg, "governance") ||
-		strings.Contains(errMsg, "request failed") ||
-		strings.Contains(errMsg, "connection refused")
-}
-
 // HealthCheck checks if AxonFlow Agent is healthy
 func (c *AxonFlowClient) HealthCheck() error {
-	resp, err := c.httpClient.Get(c.config.Endpoint + "/health")
+	return c.HealthCheckContext(context.Background())
+}
+
+// HealthCheckContext is like HealthCheck but carries a context.
+func (c *AxonFlowClient) HealthCheckContext(ctx context.Context) error {
+	resp, err := c.send(ctx, &request{method: "GET", url: c.config.Endpoint + "/health", auth: authNone})
 	if err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("agent not healthy: status %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("agent not healthy: %w", newAPIError(resp, body))
 	}
 
-	if c.config.Debug {
-		log.Println("[AxonFlow] Health check passed")
-	}
+	c.debugf("Health check passed")
 
 	return nil
 }
 
 // OrchestratorHealthCheck checks orchestrator health via Agent proxy.
-// Deprecated: Use HealthCheck() instead - Agent proxies all routes since ADR-026.
+// Deprecated: Use HealthCheck() instead - Agent proxies all routes since ADR-026. Diagnose
+// reports the orchestrator's reachability as seen by the Agent.
 func (c *AxonFlowClient) OrchestratorHealthCheck() error {
 	// Since ADR-026, Agent proxies to Orchestrator, so we just call Agent health
 	return c.HealthCheck()
@@ -755,9 +847,19 @@ func getMetadataKeys(metadata map[string]interface{}) []string {
 	return keys
 }
 
+// getWithContext issues an unauthenticated GET request bound to ctx.
+func (c *AxonFlowClient) getWithContext(ctx context.Context, url string) (*http.Response, error) {
+	return c.send(ctx, &request{method: "GET", url: url, auth: authNone})
+}
+
 // ListConnectors returns all available MCP connectors from the marketplace
 func (c *AxonFlowClient) ListConnectors() ([]ConnectorMetadata, error) {
-	resp, err := c.httpClient.Get(c.config.Endpoint + "/api/v1/connectors")
+	return c.ListConnectorsContext(context.Background())
+}
+
+// ListConnectorsContext is like ListConnectors but carries a context.
+func (c *AxonFlowClient) ListConnectorsContext(ctx context.Context) ([]ConnectorMetadata, error) {
+	resp, err := c.getWithContext(ctx, c.config.Endpoint+"/api/v1/connectors")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list connectors: %w", err)
 	}
@@ -765,7 +867,7 @@ func (c *AxonFlowClient) ListConnectors() ([]ConnectorMetadata, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list connectors failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("list connectors failed: %w", newAPIError(resp, body))
 	}
 
 	// Response is wrapped: {"connectors": [...], "total": N}
@@ -777,29 +879,33 @@ func (c *AxonFlowClient) ListConnectors() ([]ConnectorMetadata, error) {
 		return nil, fmt.Errorf("failed to decode connectors: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listed %d connectors", len(response.Connectors))
-	}
+	c.debugf("Listed %d connectors", len(response.Connectors))
 
 	return response.Connectors, nil
 }
 
 // GetConnector returns details for a specific connector by ID
 func (c *AxonFlowClient) GetConnector(connectorID string) (*ConnectorMetadata, error) {
+	return c.GetConnectorContext(context.Background(), connectorID)
+}
+
+// GetConnectorContext is like GetConnector but carries a context.
+func (c *AxonFlowClient) GetConnectorContext(ctx context.Context, connectorID string) (*ConnectorMetadata, error) {
 	url := fmt.Sprintf("%s/api/v1/connectors/%s", c.config.Endpoint, connectorID)
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.getWithContext(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connector: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("connector not found: %s", connectorID)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newNotFoundError(resp, body, "connector", connectorID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get connector failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("get connector failed: %w", newAPIError(resp, body))
 	}
 
 	var connector ConnectorMetadata
@@ -807,29 +913,33 @@ func (c *AxonFlowClient) GetConnector(connectorID string) (*ConnectorMetadata, e
 		return nil, fmt.Errorf("failed to decode connector: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Got connector: %s (installed: %v)", connector.ID, connector.Installed)
-	}
+	c.debugf("Got connector: %s (installed: %v)", connector.ID, connector.Installed)
 
 	return &connector, nil
 }
 
 // GetConnectorHealth returns the health status of an installed connector
 func (c *AxonFlowClient) GetConnectorHealth(connectorID string) (*ConnectorHealthStatus, error) {
+	return c.GetConnectorHealthContext(context.Background(), connectorID)
+}
+
+// GetConnectorHealthContext is like GetConnectorHealth but carries a context.
+func (c *AxonFlowClient) GetConnectorHealthContext(ctx context.Context, connectorID string) (*ConnectorHealthStatus, error) {
 	url := fmt.Sprintf("%s/api/v1/connectors/%s/health", c.config.Endpoint, connectorID)
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.getWithContext(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connector health: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("connector not found: %s", connectorID)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newNotFoundError(resp, body, "connector", connectorID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("connector health check failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("connector health check failed: %w", newAPIError(resp, body))
 	}
 
 	var status ConnectorHealthStatus
@@ -837,31 +947,26 @@ func (c *AxonFlowClient) GetConnectorHealth(connectorID string) (*ConnectorHealt
 		return nil, fmt.Errorf("failed to decode health status: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Connector %s health: %v", connectorID, status.Healthy)
-	}
+	c.debugf("Connector %s health: %v", connectorID, status.Healthy)
 
 	return &status, nil
 }
 
 // InstallConnector installs an MCP connector from the marketplace
 func (c *AxonFlowClient) InstallConnector(req ConnectorInstallRequest) error {
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal install request: %w", err)
-	}
+	return c.InstallConnectorContext(context.Background(), req)
+}
 
+// InstallConnectorContext is like InstallConnector but carries a context.
+func (c *AxonFlowClient) InstallConnectorContext(ctx context.Context, req ConnectorInstallRequest) error {
 	// Connector install via Agent proxy: POST /api/v1/connectors/{id}/install
 	url := fmt.Sprintf("%s/api/v1/connectors/%s/install", c.config.Endpoint, req.ConnectorID)
-	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(reqBody))
+	httpReq, err := newJSONRequest("POST", url, req)
 	if err != nil {
-		return fmt.Errorf("failed to create install request: %w", err)
+		return fmt.Errorf("failed to marshal install request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	c.addAuthHeaders(httpReq)
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return fmt.Errorf("install request failed: %w", err)
 	}
@@ -869,27 +974,23 @@ func (c *AxonFlowClient) InstallConnector(req ConnectorInstallRequest) error {
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("install failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("install failed: %w", newAPIError(resp, body))
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Connector installed: %s", req.Name)
-	}
+	c.debugf("Connector installed: %s", req.Name)
 
 	return nil
 }
 
 // UninstallConnector removes an installed MCP connector
 func (c *AxonFlowClient) UninstallConnector(connectorName string) error {
-	url := fmt.Sprintf("%s/api/v1/connectors/%s", c.config.Endpoint, connectorName)
-	httpReq, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create uninstall request: %w", err)
-	}
-
-	c.addAuthHeaders(httpReq)
+	return c.UninstallConnectorContext(context.Background(), connectorName)
+}
 
-	resp, err := c.httpClient.Do(httpReq)
+// UninstallConnectorContext is like UninstallConnector but carries a context.
+func (c *AxonFlowClient) UninstallConnectorContext(ctx context.Context, connectorName string) error {
+	url := fmt.Sprintf("%s/api/v1/connectors/%s", c.config.Endpoint, connectorName)
+	resp, err := c.send(ctx, newRequest("DELETE", url))
 	if err != nil {
 		return fmt.Errorf("uninstall request failed: %w", err)
 	}
@@ -897,33 +998,37 @@ func (c *AxonFlowClient) UninstallConnector(connectorName string) error {
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("uninstall failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("uninstall failed: %w", newAPIError(resp, body))
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Connector uninstalled: %s", connectorName)
-	}
+	c.debugf("Connector uninstalled: %s", connectorName)
 
 	return nil
 }
 
 // QueryConnector executes a query against an installed MCP connector
 func (c *AxonFlowClient) QueryConnector(userToken, connectorName, query string, params map[string]interface{}) (*ConnectorResponse, error) {
-	context := map[string]interface{}{
+	return c.QueryConnectorContext(context.Background(), userToken, connectorName, query, params)
+}
+
+// QueryConnectorContext is like QueryConnector but carries a context.
+func (c *AxonFlowClient) QueryConnectorContext(ctx context.Context, userToken, connectorName, query string, params map[string]interface{}) (*ConnectorResponse, error) {
+	queryContext := map[string]interface{}{
 		"connector": connectorName,
 		"params":    params,
 	}
 
-	resp, err := c.ExecuteQuery(userToken, query, "mcp-query", context)
+	resp, err := c.ExecuteQueryContext(ctx, userToken, query, "mcp-query", queryContext)
 	if err != nil {
 		return nil, err
 	}
 
 	connResp := &ConnectorResponse{
-		Success: resp.Success,
-		Data:    resp.Data,
-		Error:   resp.Error,
-		Meta:    resp.Metadata,
+		Success:  resp.Success,
+		Data:     resp.Data,
+		Error:    resp.Error,
+		Meta:     resp.Metadata,
+		Fallback: resp.Fallback,
 	}
 
 	return connResp, nil
@@ -943,18 +1048,66 @@ type MCPQueryRequest struct {
 // - PolicyInfo metadata in responses
 //
 // Returns ConnectorResponse with PolicyInfo, Redacted, and RedactedFields populated.
+//
+// If AxonFlow is unavailable, FailurePolicy.MCPQuery applies (default: fail closed).
+// Fail-open returns a successful response with no data and Fallback set.
 func (c *AxonFlowClient) MCPQuery(ctx context.Context, req MCPQueryRequest) (*ConnectorResponse, error) {
+	result, err := c.mcpQuery(ctx, req)
+	decision := c.decisionKey(RequestTypeMCPQuery, req.Connector, req.Statement, canonicalJSON(req.Options))
+	if err == nil {
+		c.remember(decision, result)
+		if result.PolicyInfo != nil && result.PolicyInfo.Blocked {
+			c.observer.OnPolicyBlock(ctx, ObserverEvent{
+				RequestType:         RequestTypeMCPQuery,
+				BlockReason:         result.PolicyInfo.BlockReason,
+				ConnectorPolicyInfo: result.PolicyInfo,
+			})
+		}
+		return result, nil
+	}
+	if ctx.Err() != nil || !isUnavailableError(err) {
+		return nil, err
+	}
+
+	switch c.failureMode(RequestTypeMCPQuery, false) {
+	case FailOpen:
+		c.logger.WarnContext(ctx, "AxonFlow unavailable, failing open",
+			"request_type", RequestTypeMCPQuery,
+			"error", err)
+		c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: RequestTypeMCPQuery, Err: err, Fallback: FailOpen})
+		return &ConnectorResponse{
+			Success:  true,
+			Error:    unavailableMessage(err),
+			Fallback: FailOpen,
+		}, nil
+	case FailCached:
+		if cached, ok := c.recall(decision); ok {
+			c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: RequestTypeMCPQuery, Err: err, Fallback: FailCached})
+			fallback := *cached.(*ConnectorResponse)
+			fallback.Fallback = FailCached
+			return &fallback, nil
+		}
+	}
+	return nil, err
+}
+
+func (c *AxonFlowClient) mcpQuery(ctx context.Context, req MCPQueryRequest) (*ConnectorResponse, error) {
 	if req.Connector == "" {
-		return nil, fmt.Errorf("connector name is required")
+		return nil, newValidationError("connector", "connector name is required")
 	}
 	if req.Statement == "" {
-		return nil, fmt.Errorf("statement is required")
+		return nil, newValidationError("statement", "statement is required")
 	}
 
-	url := c.config.Endpoint + "/mcp/resources/query"
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/mcp/resources/query", req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.retrySafe = true // queries are read-only; writes go through MCPExecute
+	httpReq.requestType = RequestTypeMCPQuery
 
 	var result ConnectorResponse
-	if err := c.makeJSONRequest(ctx, "POST", url, req, &result); err != nil {
+	if err := c.sendJSON(ctx, httpReq, &result); err != nil {
 		return nil, err
 	}
 
@@ -983,10 +1136,10 @@ type MCPExecuteResponse struct {
 // This method calls the agent's /mcp/tools/execute endpoint.
 func (c *AxonFlowClient) MCPExecute(ctx context.Context, req MCPExecuteRequest) (*MCPExecuteResponse, error) {
 	if req.Connector == "" {
-		return nil, fmt.Errorf("connector name is required")
+		return nil, newValidationError("connector", "connector name is required")
 	}
 	if req.Action == "" {
-		return nil, fmt.Errorf("action is required")
+		return nil, newValidationError("action", "action is required")
 	}
 
 	url := c.config.Endpoint + "/mcp/tools/execute"
@@ -1004,9 +1157,15 @@ func (c *AxonFlowClient) MCPExecute(ctx context.Context, req MCPExecuteRequest)
 // Usage: GeneratePlan(query, domain) or GeneratePlan(query, domain, userToken)
 // Note: This uses MapTimeout (default 120s) as MAP operations involve multiple LLM calls.
 func (c *AxonFlowClient) GeneratePlan(query string, domain string, userToken ...string) (*PlanResponse, error) {
-	context := map[string]interface{}{}
+	return c.GeneratePlanContext(context.Background(), query, domain, userToken...)
+}
+
+// GeneratePlanContext is like GeneratePlan but carries a context. The call is bounded
+// by whichever is shorter: ctx's deadline or MapTimeout.
+func (c *AxonFlowClient) GeneratePlanContext(ctx context.Context, query string, domain string, userToken ...string) (*PlanResponse, error) {
+	planContext := map[string]interface{}{}
 	if domain != "" {
-		context["domain"] = domain
+		planContext["domain"] = domain
 	}
 
 	// Use client ID as fallback if no user token provided
@@ -1021,13 +1180,16 @@ func (c *AxonFlowClient) GeneratePlan(query string, domain string, userToken ...
 		UserToken:   token,
 		ClientID:    c.config.ClientID,
 		RequestType: "multi-agent-plan",
-		Context:     context,
+		Context:     planContext,
 	}
 
-	resp, err := c.executeMapRequest(req)
+	// With several endpoints, later calls for the plan go to the agent that generated it
+	ctx, rt := c.withRoute(ctx, "")
+	resp, err := c.executeMapRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	c.stickPlan(resp.PlanID, rt)
 
 	if !resp.Success {
 		return nil, fmt.Errorf("plan generation failed: %s", resp.Error)
@@ -1048,35 +1210,28 @@ func (c *AxonFlowClient) GeneratePlan(query string, domain string, userToken ...
 
 	plan.PlanID = resp.PlanID
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Plan generated: %s (%d steps)", plan.PlanID, len(plan.Steps))
-	}
+	c.debugf("Plan generated: %s (%d steps)", plan.PlanID, len(plan.Steps))
 
 	return &plan, nil
 }
 
 // executeMapRequest executes a MAP request using the mapHttpClient with longer timeout
-func (c *AxonFlowClient) executeMapRequest(req ClientRequest) (*ClientResponse, error) {
-	reqBody, err := json.Marshal(req)
+func (c *AxonFlowClient) executeMapRequest(ctx context.Context, req ClientRequest) (*ClientResponse, error) {
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/request", req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequest("POST", c.config.Endpoint+"/api/request", bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	httpReq.client = c.mapHttpClient // Use mapHttpClient with longer timeout
+	httpReq.requestType = req.RequestType
+	httpReq.user = req.UserToken
 
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	c.addAuthHeaders(httpReq)
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] MAP request - Query: %s (timeout: %v)", req.Query[:min(50, len(req.Query))], c.config.MapTimeout)
-	}
+	c.logger.DebugContext(ctx, "Sending AxonFlow MAP request",
+		"request_type", req.RequestType,
+		"timeout", c.config.MapTimeout,
+		c.content("query", req.Query))
 
 	startTime := time.Now()
-	resp, err := c.mapHttpClient.Do(httpReq) // Use mapHttpClient with longer timeout
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("MAP request failed: %w", err)
 	}
@@ -1089,15 +1244,14 @@ func (c *AxonFlowClient) executeMapRequest(req ClientRequest) (*ClientResponse,
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] MAP response received in %v", duration)
-	}
+	c.logger.DebugContext(ctx, "AxonFlow MAP response received",
+		"request_type", req.RequestType,
+		"request_id", resp.Header.Get("X-Request-ID"),
+		"status", resp.StatusCode,
+		"duration", duration)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var clientResp ClientResponse
@@ -1139,7 +1293,12 @@ func (c *AxonFlowClient) executeMapRequest(req ClientRequest) (*ClientResponse,
 // The userToken parameter is optional; if not provided, it defaults to the client ID.
 // Usage: ExecutePlan(planID) or ExecutePlan(planID, userToken)
 func (c *AxonFlowClient) ExecutePlan(planID string, userToken ...string) (*PlanExecutionResponse, error) {
-	context := map[string]interface{}{
+	return c.ExecutePlanContext(context.Background(), planID, userToken...)
+}
+
+// ExecutePlanContext is like ExecutePlan but carries a context.
+func (c *AxonFlowClient) ExecutePlanContext(ctx context.Context, planID string, userToken ...string) (*PlanExecutionResponse, error) {
+	planContext := map[string]interface{}{
 		"plan_id": planID,
 	}
 
@@ -1149,10 +1308,12 @@ func (c *AxonFlowClient) ExecutePlan(planID string, userToken ...string) (*PlanE
 		token = userToken[0]
 	}
 
-	resp, err := c.ExecuteQuery(token, "", "execute-plan", context)
+	ctx, rt := c.withRoute(ctx, planID)
+	resp, err := c.ExecuteQueryContext(ctx, token, "", "execute-plan", planContext)
 	if err != nil {
 		return nil, err
 	}
+	c.stickPlan(planID, rt)
 
 	execResp := &PlanExecutionResponse{
 		PlanID: planID,
@@ -1204,16 +1365,20 @@ func (c *AxonFlowClient) ExecutePlan(planID string, userToken ...string) (*PlanE
 		execResp.Status = "failed"
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Plan executed: %s - Status: %s", planID, execResp.Status)
-	}
+	c.debugf("Plan executed: %s - Status: %s", planID, execResp.Status)
 
 	return execResp, nil
 }
 
 // GetPlanStatus retrieves the status of a running or completed plan
 func (c *AxonFlowClient) GetPlanStatus(planID string) (*PlanExecutionResponse, error) {
-	resp, err := c.httpClient.Get(c.config.Endpoint + "/api/v1/plan/" + planID)
+	return c.GetPlanStatusContext(context.Background(), planID)
+}
+
+// GetPlanStatusContext is like GetPlanStatus but carries a context.
+func (c *AxonFlowClient) GetPlanStatusContext(ctx context.Context, planID string) (*PlanExecutionResponse, error) {
+	ctx, _ = c.withRoute(ctx, planID)
+	resp, err := c.getWithContext(ctx, c.config.Endpoint+"/api/v1/plan/"+planID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plan status: %w", err)
 	}
@@ -1221,7 +1386,7 @@ func (c *AxonFlowClient) GetPlanStatus(planID string) (*PlanExecutionResponse, e
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get plan status failed: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("get plan status failed: %w", newAPIError(resp, body))
 	}
 
 	var status PlanExecutionResponse
@@ -1241,9 +1406,20 @@ func (c *AxonFlowClient) PreCheck(
 	userToken string,
 	query string,
 	dataSources []string,
-	context map[string]interface{},
+	queryContext map[string]interface{},
 ) (*PolicyApprovalResult, error) {
-	return c.GetPolicyApprovedContext(userToken, query, dataSources, context)
+	return c.GetPolicyApprovedContext(userToken, query, dataSources, queryContext)
+}
+
+// PreCheckContext is an alias for GetPolicyApprovedContextWithContext.
+func (c *AxonFlowClient) PreCheckContext(
+	ctx context.Context,
+	userToken string,
+	query string,
+	dataSources []string,
+	queryContext map[string]interface{},
+) (*PolicyApprovalResult, error) {
+	return c.GetPolicyApprovedContextWithContext(ctx, userToken, query, dataSources, queryContext)
 }
 
 // GetPolicyApprovedContext performs a policy pre-check before making a direct LLM call.
@@ -1283,7 +1459,81 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 	userToken string,
 	query string,
 	dataSources []string,
-	context map[string]interface{},
+	queryContext map[string]interface{},
+) (*PolicyApprovalResult, error) {
+	return c.GetPolicyApprovedContextWithContext(context.Background(), userToken, query, dataSources, queryContext)
+}
+
+// GetPolicyApprovedContextWithContext is like GetPolicyApprovedContext but carries a
+// context. (The "Context" suffix alone would collide with the method's own name.)
+//
+// If AxonFlow is unavailable, FailurePolicy.PreCheck applies (default: fail closed).
+// Fail-open returns an approval with no ContextID and Fallback set; fail-cached
+// returns the last result for the same user, query and data sources.
+func (c *AxonFlowClient) GetPolicyApprovedContextWithContext(
+	ctx context.Context,
+	userToken string,
+	query string,
+	dataSources []string,
+	queryContext map[string]interface{},
+) (*PolicyApprovalResult, error) {
+	value, err, _ := c.collapse(ctx, c.preCheckKey(userToken, query, dataSources, queryContext), RequestTypePreCheck,
+		func(ctx context.Context) (interface{}, error) {
+			return c.preCheck(ctx, userToken, query, dataSources, queryContext)
+		})
+	var result *PolicyApprovalResult
+	if err == nil {
+		result = value.(*PolicyApprovalResult)
+		if c.flights != nil {
+			// The result may be shared: each caller gets its own copy
+			result = result.clone()
+		}
+	}
+	return c.preCheckOutcome(ctx, c.preCheckKey(userToken, query, dataSources, queryContext), result, err)
+}
+
+// preCheckOutcome records a pre-check result, or applies the failure policy if
+// AxonFlow was unavailable.
+func (c *AxonFlowClient) preCheckOutcome(ctx context.Context, decision string, result *PolicyApprovalResult, err error) (*PolicyApprovalResult, error) {
+	if err == nil {
+		c.remember(decision, result)
+		if !result.Approved {
+			c.observer.OnPolicyBlock(ctx, ObserverEvent{RequestType: RequestTypePreCheck, BlockReason: result.BlockReason})
+		}
+		return result, nil
+	}
+	if ctx.Err() != nil || !isUnavailableError(err) {
+		return nil, err
+	}
+
+	switch c.failureMode(RequestTypePreCheck, false) {
+	case FailOpen:
+		c.logger.WarnContext(ctx, "AxonFlow unavailable, failing open",
+			"request_type", RequestTypePreCheck,
+			"error", err)
+		c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: RequestTypePreCheck, Err: err, Fallback: FailOpen})
+		return &PolicyApprovalResult{
+			Approved:  true,
+			ExpiresAt: time.Now().Add(5 * time.Minute),
+			Fallback:  FailOpen,
+		}, nil
+	case FailCached:
+		if cached, ok := c.recall(decision); ok {
+			c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: RequestTypePreCheck, Err: err, Fallback: FailCached})
+			fallback := *cached.(*PolicyApprovalResult)
+			fallback.Fallback = FailCached
+			return &fallback, nil
+		}
+	}
+	return nil, err
+}
+
+func (c *AxonFlowClient) preCheck(
+	ctx context.Context,
+	userToken string,
+	query string,
+	dataSources []string,
+	queryContext map[string]interface{},
 ) (*PolicyApprovalResult, error) {
 	// Gateway Mode requires credentials (enterprise feature)
 	if err := c.requireCredentials("Gateway Mode (GetPolicyApprovedContext)"); err != nil {
@@ -1293,8 +1543,8 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 	if dataSources == nil {
 		dataSources = []string{}
 	}
-	if context == nil {
-		context = map[string]interface{}{}
+	if queryContext == nil {
+		queryContext = map[string]interface{}{}
 	}
 
 	reqBody := map[string]interface{}{
@@ -1302,28 +1552,22 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 		"client_id":    c.config.ClientID,
 		"query":        query,
 		"data_sources": dataSources,
-		"context":      context,
+		"context":      queryContext,
 	}
 
-	reqBytes, err := json.Marshal(reqBody)
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/policy/pre-check", reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal pre-check request: %w", err)
 	}
+	httpReq.retrySafe = true // a pre-check only evaluates policies
+	httpReq.requestType = RequestTypePreCheck
+	httpReq.user = userToken
 
-	httpReq, err := http.NewRequest("POST", c.config.Endpoint+"/api/policy/pre-check", bytes.NewReader(reqBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create pre-check request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
+	c.logger.DebugContext(ctx, "AxonFlow pre-check",
+		"request_type", RequestTypePreCheck,
+		c.content("query", query))
 
-	c.addAuthHeaders(httpReq)
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Gateway Mode: Pre-check for query: %s", query[:min(50, len(query))])
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("pre-check request failed: %w", err)
 	}
@@ -1335,40 +1579,54 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
-	// Parse response
-	var rawResp struct {
-		ContextID         string                 `json:"context_id"`
-		Approved          bool                   `json:"approved"`
-		RequiresRedaction bool                   `json:"requires_redaction"`
-		ApprovedData      map[string]interface{} `json:"approved_data"`
-		Policies          []string               `json:"policies"`
-		RateLimit         *struct {
-			Limit     int    `json:"limit"`
-			Remaining int    `json:"remaining"`
-			ResetAt   string `json:"reset_at"`
-		} `json:"rate_limit,omitempty"`
-		ExpiresAt   string `json:"expires_at"`
-		BlockReason string `json:"block_reason,omitempty"`
+	result, err := c.decodePreCheck(ctx, body)
+	if err != nil {
+		return nil, err
 	}
 
+	c.logger.DebugContext(ctx, "AxonFlow pre-check result",
+		"request_type", RequestTypePreCheck,
+		"request_id", resp.Header.Get("X-Request-ID"),
+		"approved", result.Approved,
+		"context_id", result.ContextID,
+		"policies", len(result.Policies))
+
+	return result, nil
+}
+
+// rawPreCheckResponse is the pre-check response body.
+type rawPreCheckResponse struct {
+	ContextID         string                 `json:"context_id"`
+	Approved          bool                   `json:"approved"`
+	RequiresRedaction bool                   `json:"requires_redaction"`
+	ApprovedData      map[string]interface{} `json:"approved_data"`
+	Policies          []string               `json:"policies"`
+	RateLimit         *rawRateLimit          `json:"rate_limit,omitempty"`
+	ExpiresAt         string                 `json:"expires_at"`
+	BlockReason       string                 `json:"block_reason,omitempty"`
+}
+
+// decodePreCheck decodes a pre-check response body.
+func (c *AxonFlowClient) decodePreCheck(ctx context.Context, body []byte) (*PolicyApprovalResult, error) {
+	var rawResp rawPreCheckResponse
 	if err := json.Unmarshal(body, &rawResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal pre-check response: %w", err)
 	}
+	return c.preCheckResult(ctx, rawResp), nil
+}
 
+// preCheckResult converts a decoded pre-check response.
+func (c *AxonFlowClient) preCheckResult(ctx context.Context, rawResp rawPreCheckResponse) *PolicyApprovalResult {
 	// Parse expiration time (supports both RFC3339 and RFC3339Nano formats)
 	expiresAt, err := parseTimeWithFallback(rawResp.ExpiresAt)
 	if err != nil {
 		// Use a default expiration if parsing fails
 		expiresAt = time.Now().Add(5 * time.Minute)
-		if c.config.Debug {
-			log.Printf("[AxonFlow] Warning: Failed to parse expires_at '%s', using default 5 minute expiration", rawResp.ExpiresAt)
-		}
+		c.logger.WarnContext(ctx, "Failed to parse pre-check expires_at, using default 5 minute expiration",
+			"expires_at", rawResp.ExpiresAt)
 	}
 
 	result := &PolicyApprovalResult{
@@ -1384,8 +1642,9 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 	// Parse rate limit info if present
 	if rawResp.RateLimit != nil {
 		resetAt, err := parseTimeWithFallback(rawResp.RateLimit.ResetAt)
-		if err != nil && c.config.Debug {
-			log.Printf("[AxonFlow] Warning: Failed to parse rate_limit.reset_at '%s'", rawResp.RateLimit.ResetAt)
+		if err != nil {
+			c.logger.WarnContext(ctx, "Failed to parse pre-check rate_limit.reset_at",
+				"reset_at", rawResp.RateLimit.ResetAt)
 		}
 		result.RateLimitInfo = &RateLimitInfo{
 			Limit:     rawResp.RateLimit.Limit,
@@ -1394,12 +1653,7 @@ func (c *AxonFlowClient) GetPolicyApprovedContext(
 		}
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Gateway Mode: Pre-check result - Approved: %v, ContextID: %s, Policies: %d",
-			result.Approved, result.ContextID, len(result.Policies))
-	}
-
-	return result, nil
+	return result
 }
 
 // AuditLLMCall logs an audit trail after making a direct LLM call.
@@ -1426,6 +1680,55 @@ func (c *AxonFlowClient) AuditLLMCall(
 	tokenUsage TokenUsage,
 	latencyMs int64,
 	metadata map[string]interface{},
+) (*AuditResult, error) {
+	return c.AuditLLMCallContext(context.Background(), contextID, responseSummary, provider, model, tokenUsage, latencyMs, metadata)
+}
+
+// AuditLLMCallContext is like AuditLLMCall but carries a context.
+//
+// If AxonFlow is unavailable, FailurePolicy.Audit applies (default: fail closed).
+// Fail-open drops the audit record and returns an unsuccessful AuditResult with
+// Fallback set and a nil error. There is no decision to reuse, so FailCached behaves
+// like FailClosed.
+func (c *AxonFlowClient) AuditLLMCallContext(
+	ctx context.Context,
+	contextID string,
+	responseSummary string,
+	provider string,
+	model string,
+	tokenUsage TokenUsage,
+	latencyMs int64,
+	metadata map[string]interface{},
+) (*AuditResult, error) {
+	result, err := c.auditLLMCall(ctx, contextID, responseSummary, provider, model, tokenUsage, latencyMs, metadata)
+	if err == nil {
+		if !result.Success {
+			c.observer.OnAuditFailure(ctx, ObserverEvent{RequestType: RequestTypeAudit})
+		}
+		return result, nil
+	}
+
+	c.observer.OnAuditFailure(ctx, ObserverEvent{RequestType: RequestTypeAudit, Err: err})
+	if ctx.Err() == nil && isUnavailableError(err) && c.failureMode(RequestTypeAudit, false) == FailOpen {
+		c.logger.WarnContext(ctx, "AxonFlow unavailable, dropping audit",
+			"request_type", RequestTypeAudit,
+			"context_id", contextID,
+			"error", err)
+		c.observer.OnFailOpen(ctx, ObserverEvent{RequestType: RequestTypeAudit, Err: err, Fallback: FailOpen})
+		return &AuditResult{Success: false, Fallback: FailOpen}, nil
+	}
+	return nil, err
+}
+
+func (c *AxonFlowClient) auditLLMCall(
+	ctx context.Context,
+	contextID string,
+	responseSummary string,
+	provider string,
+	model string,
+	tokenUsage TokenUsage,
+	latencyMs int64,
+	metadata map[string]interface{},
 ) (*AuditResult, error) {
 	// Gateway Mode requires credentials (enterprise feature)
 	if err := c.requireCredentials("Gateway Mode (AuditLLMCall)"); err != nil {
@@ -1451,26 +1754,15 @@ func (c *AxonFlowClient) AuditLLMCall(
 		"metadata":   metadata,
 	}
 
-	reqBytes, err := json.Marshal(reqBody)
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/audit/llm-call", reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal audit request: %w", err)
 	}
+	httpReq.requestType = RequestTypeAudit
 
-	httpReq, err := http.NewRequest("POST", c.config.Endpoint+"/api/audit/llm-call", bytes.NewReader(reqBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create audit request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	c.addAuthHeaders(httpReq)
+	c.debugf("Gateway Mode: Audit - ContextID: %s, Provider: %s, Model: %s", contextID, provider, model)
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Gateway Mode: Audit - ContextID: %s, Provider: %s, Model: %s",
-			contextID, provider, model)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("audit request failed: %w", err)
 	}
@@ -1482,10 +1774,7 @@ func (c *AxonFlowClient) AuditLLMCall(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var rawResp struct {
@@ -1502,9 +1791,7 @@ func (c *AxonFlowClient) AuditLLMCall(
 		AuditID: rawResp.AuditID,
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Gateway Mode: Audit logged - AuditID: %s", result.AuditID)
-	}
+	c.debugf("Gateway Mode: Audit logged - AuditID: %s", result.AuditID)
 
 	return result, nil
 }
@@ -1548,30 +1835,31 @@ func min(a, b int) int {
 //	// Now you can use Code Governance methods
 //	providers, err := client.ListGitProviders()
 func (c *AxonFlowClient) LoginToPortal(orgID, password string) (*PortalLoginResponse, error) {
+	return c.LoginToPortalContext(context.Background(), orgID, password)
+}
+
+// LoginToPortalContext is like LoginToPortal but carries a context.
+func (c *AxonFlowClient) LoginToPortalContext(ctx context.Context, orgID, password string) (*PortalLoginResponse, error) {
+	if err := c.requireFeature(ctx, FeaturePortal); err != nil {
+		return nil, err
+	}
+
 	reqBody := PortalLoginRequest{
 		OrgID:    orgID,
 		Password: password,
 	}
 
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal login request: %w", err)
-	}
-
 	fullURL := c.config.Endpoint + "/api/v1/auth/login"
 
-	req, err := http.NewRequest("POST", fullURL, bytes.NewReader(bodyBytes))
+	req, err := newJSONRequest("POST", fullURL, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create login request: %w", err)
+		return nil, fmt.Errorf("failed to marshal login request: %w", err)
 	}
+	req.auth = authNone
 
-	req.Header.Set("Content-Type", "application/json")
+	c.debugf("Portal login for org: %s", orgID)
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Portal login for org: %s", orgID)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("login request failed: %w", err)
 	}
@@ -1583,10 +1871,7 @@ func (c *AxonFlowClient) LoginToPortal(orgID, password string) (*PortalLoginResp
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var loginResp PortalLoginResponse
@@ -1598,9 +1883,7 @@ func (c *AxonFlowClient) LoginToPortal(orgID, password string) (*PortalLoginResp
 	for _, cookie := range resp.Cookies() {
 		if cookie.Name == "axonflow_session" {
 			c.sessionCookie = cookie.Value
-			if c.config.Debug {
-				log.Printf("[AxonFlow] Portal session established for %s", orgID)
-			}
+			c.debugf("Portal session established for %s", orgID)
 			break
 		}
 	}
@@ -1608,9 +1891,7 @@ func (c *AxonFlowClient) LoginToPortal(orgID, password string) (*PortalLoginResp
 	// If no cookie in response, use session_id from JSON response
 	if c.sessionCookie == "" && loginResp.SessionID != "" {
 		c.sessionCookie = loginResp.SessionID
-		if c.config.Debug {
-			log.Printf("[AxonFlow] Portal session established from response body for %s", orgID)
-		}
+		c.debugf("Portal session established from response body for %s", orgID)
 	}
 
 	return &loginResp, nil
@@ -1618,23 +1899,18 @@ func (c *AxonFlowClient) LoginToPortal(orgID, password string) (*PortalLoginResp
 
 // LogoutFromPortal logs out from the Customer Portal and clears the session cookie.
 func (c *AxonFlowClient) LogoutFromPortal() error {
+	return c.LogoutFromPortalContext(context.Background())
+}
+
+// LogoutFromPortalContext is like LogoutFromPortal but carries a context.
+func (c *AxonFlowClient) LogoutFromPortalContext(ctx context.Context) error {
 	if c.sessionCookie == "" {
 		return nil // Already logged out
 	}
 
 	fullURL := c.config.Endpoint + "/api/v1/auth/logout"
 
-	req, err := http.NewRequest("POST", fullURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create logout request: %w", err)
-	}
-
-	req.AddCookie(&http.Cookie{
-		Name:  "axonflow_session",
-		Value: c.sessionCookie,
-	})
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, &request{method: "POST", url: fullURL, auth: authSession})
 	if err != nil {
 		return fmt.Errorf("logout request failed: %w", err)
 	}
@@ -1642,9 +1918,7 @@ func (c *AxonFlowClient) LogoutFromPortal() error {
 
 	c.sessionCookie = ""
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Portal session ended")
-	}
+	c.debugf("Portal session ended")
 
 	return nil
 }
@@ -1656,55 +1930,12 @@ func (c *AxonFlowClient) IsLoggedIn() bool {
 
 // makeJSONRequest is a generic helper for making JSON HTTP requests
 func (c *AxonFlowClient) makeJSONRequest(ctx context.Context, method, fullURL string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(bodyBytes)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	c.addAuthHeaders(req)
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] JSON request: %s %s", method, fullURL)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	req, err := newJSONRequest(method, fullURL, body)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
+	c.debugf("JSON request: %s %s", method, fullURL)
 
-	if resp.StatusCode >= 400 {
-		return &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
-	}
-
-	// Handle no-content responses
-	if resp.StatusCode == 204 || len(respBody) == 0 {
-		return nil
-	}
-
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-	}
-
-	return nil
+	return c.sendJSON(ctx, req, result)
 }
@@ -4,13 +4,19 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 )
 
+// noRetry makes a single attempt, for tests of error paths.
+var noRetry = RetryConfig{MaxAttempts: 1}
+
 func TestExecuteQuery(t *testing.T) {
 	// Create a mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -289,6 +295,7 @@ func TestHealthCheckUnhealthy(t *testing.T) {
 	client := NewClient(AxonFlowConfig{
 		Endpoint: server.URL,
 		ClientID: "test",
+		Retry:    noRetry,
 	})
 
 	err := client.HealthCheck()
@@ -357,6 +364,7 @@ func TestListConnectorsError(t *testing.T) {
 	client := NewClient(AxonFlowConfig{
 		Endpoint: server.URL,
 		ClientID: "test",
+		Retry:    noRetry,
 	})
 
 	_, err := client.ListConnectors()
@@ -1073,30 +1081,29 @@ func TestGetPlanStatusError(t *testing.T) {
 	}
 }
 
-func TestIsAxonFlowError(t *testing.T) {
-	client := NewClient(AxonFlowConfig{
-		Endpoint: "http://test.example.com",
-		ClientID: "test",
-	})
-
+func TestIsUnavailableError(t *testing.T) {
 	tests := []struct {
-		errMsg   string
+		name     string
+		err      error
 		expected bool
 	}{
-		{"AxonFlow service unavailable", true},
-		{"governance policy error", true},
-		{"request failed: connection refused", true},
-		{"connection refused", true},
-		{"OpenAI API error: rate limit exceeded", false},
-		{"Anthropic error: invalid API key", false},
+		{"nil", nil, false},
+		{"server error", &APIError{StatusCode: 503, Message: "unavailable"}, true},
+		{"wrapped server error", fmt.Errorf("request failed after 3 attempts: %w", &APIError{StatusCode: 500}), true},
+		{"bad request", &ValidationError{APIError: &APIError{StatusCode: 400}}, false},
+		{"policy block", &PolicyBlockedError{APIError: &APIError{StatusCode: 403}, BlockReason: "PII"}, false},
+		{"rate limited", &RateLimitedError{APIError: &APIError{StatusCode: 429}}, false},
+		{"network error", &url.Error{Op: "Post", URL: "http://x", Err: errors.New("connection refused")}, true},
+		{"caller cancelled", &url.Error{Op: "Post", URL: "http://x", Err: context.Canceled}, false},
+		{"plain error mentioning governance", errors.New("governance policy error"), false},
 	}
 
 	for _, tt := range tests {
-		err := &httpError{statusCode: 500, message: tt.errMsg}
-		result := client.isAxonFlowError(err)
-		if result != tt.expected {
-			t.Errorf("isAxonFlowError(%q) = %v, want %v", tt.errMsg, result, tt.expected)
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnavailableError(tt.err); got != tt.expected {
+				t.Errorf("isUnavailableError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
 	}
 }
 
@@ -1249,6 +1256,7 @@ func TestOrchestratorHealthCheckUnhealthy(t *testing.T) {
 		Endpoint: server.URL,
 		ClientID: "test",
 		Cache:    CacheConfig{Enabled: false},
+		Retry:    noRetry,
 	})
 
 	err := client.OrchestratorHealthCheck()
@@ -1461,3 +1469,118 @@ func TestMCPExecute(t *testing.T) {
 		t.Errorf("Expected 1 affected row, got %d", resp.RowsAffected)
 	}
 }
+
+func TestExecuteQueryContextCancelsRetryBackoff(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal Server Error"))
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Mode:     "production", // Cancellation must not be mistaken for an outage
+		Retry: RetryConfig{
+			Enabled: true,
+			// No jitter, so the backoff is reliably longer than the context deadline
+			Policy: ExponentialBackoff{MaxAttempts: 5, InitialDelay: 10 * time.Second, NoJitter: true},
+		},
+		Cache: CacheConfig{Enabled: false},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.ExecuteQueryContext(ctx, "user", "query", "chat", nil)
+	if err == nil {
+		t.Fatal("Expected error when context expires during backoff")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Backoff was not interrupted by context (took %v)", elapsed)
+	}
+	if callCount != 1 {
+		t.Errorf("Expected 1 call before cancellation, got %d", callCount)
+	}
+}
+
+func TestExecuteQueryContextAlreadyCancelled(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Cache:    CacheConfig{Enabled: false},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.ExecuteQueryContext(ctx, "user", "query", "chat", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if called {
+		t.Error("Expected no request to reach the server")
+	}
+}
+
+func TestGetPolicyApprovedContextWithContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"approved": true})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.PreCheckContext(ctx, "user", "query", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestGeneratePlanContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GeneratePlanContext(ctx, "plan a trip", "travel"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
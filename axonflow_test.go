@@ -1,6 +1,7 @@
 package axonflow
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -93,34 +94,36 @@ func TestSandboxDefaultKey(t *testing.T) {
 }
 
 func TestCacheBasicOperations(t *testing.T) {
-	cache := newCache(1 * time.Second)
+	cache := NewLRUCache(0, 0)
+	ctx := context.Background()
 
 	// Test set and get
-	cache.set("key1", "value1")
-	value, found := cache.get("key1")
+	cache.Set(ctx, "key1", []byte("value1"), 1*time.Second)
+	value, found, _ := cache.Get(ctx, "key1")
 
 	if !found {
 		t.Error("Expected to find cached value")
 	}
 
-	if value != "value1" {
-		t.Errorf("Expected value 'value1', got '%v'", value)
+	if string(value) != "value1" {
+		t.Errorf("Expected value 'value1', got '%s'", value)
 	}
 
 	// Test non-existent key
-	_, found = cache.get("nonexistent")
+	_, found, _ = cache.Get(ctx, "nonexistent")
 	if found {
 		t.Error("Expected key not to be found")
 	}
 }
 
 func TestCacheExpiration(t *testing.T) {
-	cache := newCache(50 * time.Millisecond)
+	cache := NewLRUCache(0, 0)
+	ctx := context.Background()
 
-	cache.set("key1", "value1")
+	cache.Set(ctx, "key1", []byte("value1"), 50*time.Millisecond)
 
 	// Value should exist immediately
-	_, found := cache.get("key1")
+	_, found, _ := cache.Get(ctx, "key1")
 	if !found {
 		t.Error("Expected cached value to exist")
 	}
@@ -129,7 +132,7 @@ func TestCacheExpiration(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Value should be expired
-	_, found = cache.get("key1")
+	_, found, _ = cache.Get(ctx, "key1")
 	if found {
 		t.Error("Expected cached value to be expired")
 	}
@@ -153,10 +156,10 @@ func TestMinHelper(t *testing.T) {
 	}
 }
 
-func TestHttpError(t *testing.T) {
-	err := &httpError{
-		statusCode: 404,
-		message:    "Not Found",
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{
+		StatusCode: 404,
+		Message:    "Not Found",
 	}
 
 	expected := "HTTP 404: Not Found"
@@ -0,0 +1,252 @@
+// Batch policy pre-checks
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	// preCheckBatchSize is the most items sent in one batch request
+	preCheckBatchSize = 100
+	// defaultBatchConcurrency is the default of AxonFlowConfig.BatchConcurrency
+	defaultBatchConcurrency = 8
+)
+
+// PreCheckItem is one query to pre-check with PreCheckBatch. The fields match the
+// arguments of GetPolicyApprovedContext.
+type PreCheckItem struct {
+	UserToken   string
+	Query       string
+	DataSources []string
+	Context     map[string]interface{}
+}
+
+// PreCheckResult is the outcome of one PreCheckItem: a result or an error.
+type PreCheckResult struct {
+	Result *PolicyApprovalResult
+	Err    error
+}
+
+// BatchError is returned by PreCheckBatch when some items failed. The results of the
+// other items are still returned.
+type BatchError struct {
+	Total  int           // Number of items in the batch
+	Failed map[int]error // Errors by item index
+}
+
+func (e *BatchError) Error() string {
+	indexes := e.indexes()
+	return fmt.Sprintf("axonflow: %d of %d pre-checks failed (item %d: %v)",
+		len(indexes), e.Total, indexes[0], e.Failed[indexes[0]])
+}
+
+// Unwrap returns the item errors in item order, for use with errors.Is and errors.As.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, i := range e.indexes() {
+		errs = append(errs, e.Failed[i])
+	}
+	return errs
+}
+
+func (e *BatchError) indexes() []int {
+	indexes := make([]int, 0, len(e.Failed))
+	for i := range e.Failed {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// PreCheckBatch pre-checks several queries at once, e.g. the chunks retrieved for a
+// RAG prompt. It returns one PreCheckResult per item, in order.
+//
+// Items are sent to the batch pre-check endpoint, up to 100 per request. Servers
+// without that endpoint get one pre-check per item instead, with at most
+// AxonFlowConfig.BatchConcurrency (default 8) in flight. Either way each item is
+// subject to the FailurePolicy like a single pre-check.
+//
+// If some items failed, the error is a *BatchError and the results of the other items
+// are still valid. Any other error means no item was checked.
+func (c *AxonFlowClient) PreCheckBatch(ctx context.Context, items []PreCheckItem) ([]PreCheckResult, error) {
+	if err := c.checkOpen(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.requireCredentials("Gateway Mode (PreCheckBatch)"); err != nil {
+		return nil, err
+	}
+
+	results := make([]PreCheckResult, len(items))
+	concurrency := c.config.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	var unchecked []int // Items to pre-check one by one
+	if c.preCheckBatchSupported() {
+		chunks := (len(items) + preCheckBatchSize - 1) / preCheckBatchSize
+		sent := make([]bool, chunks)
+		forEachLimit(chunks, concurrency, func(n int) {
+			lo := n * preCheckBatchSize
+			hi := min(lo+preCheckBatchSize, len(items))
+			sent[n] = c.preCheckChunk(ctx, items[lo:hi], results[lo:hi])
+		})
+		for n, ok := range sent {
+			if ok {
+				continue
+			}
+			for i := n * preCheckBatchSize; i < min((n+1)*preCheckBatchSize, len(items)); i++ {
+				unchecked = append(unchecked, i)
+			}
+		}
+	} else {
+		for i := range items {
+			unchecked = append(unchecked, i)
+		}
+	}
+	// After the chunks, so that at most concurrency pre-checks are in flight
+	c.preCheckEach(ctx, items, results, unchecked, concurrency)
+
+	batchErr := &BatchError{Total: len(items), Failed: map[int]error{}}
+	for i, r := range results {
+		if r.Err != nil {
+			batchErr.Failed[i] = r.Err
+		}
+	}
+	if len(batchErr.Failed) > 0 {
+		return results, batchErr
+	}
+	return results, nil
+}
+
+// preCheckBatchSupported reports whether the batch endpoint may exist.
+func (c *AxonFlowClient) preCheckBatchSupported() bool {
+	if c.noBatch.Load() {
+		return false
+	}
+	caps := c.caps.last()
+	return caps == nil || caps.Supports(FeaturePreCheckBatch)
+}
+
+// preCheckEach pre-checks the items at indexes one by one, concurrently.
+func (c *AxonFlowClient) preCheckEach(ctx context.Context, items []PreCheckItem, results []PreCheckResult, indexes []int, concurrency int) {
+	forEachLimit(len(indexes), concurrency, func(n int) {
+		i := indexes[n]
+		item := items[i]
+		result, err := c.GetPolicyApprovedContextWithContext(ctx, item.UserToken, item.Query, item.DataSources, item.Context)
+		results[i] = PreCheckResult{Result: result, Err: err}
+	})
+}
+
+// preCheckChunk sends items as one batch request and fills in their results. It
+// returns false, leaving the results unset, if the server has no batch endpoint.
+func (c *AxonFlowClient) preCheckChunk(ctx context.Context, items []PreCheckItem, results []PreCheckResult) bool {
+	raw, err := c.sendPreCheckBatch(ctx, items)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+		if !c.noBatch.Swap(true) {
+			c.logger.InfoContext(ctx, "AxonFlow has no batch pre-check endpoint, pre-checking items one by one")
+		}
+		return false
+	}
+
+	for i, item := range items {
+		var result *PolicyApprovalResult
+		itemErr := err
+		if err == nil {
+			result, itemErr = c.preCheckBatchItem(ctx, raw[i])
+		}
+		decision := c.preCheckKey(item.UserToken, item.Query, item.DataSources, item.Context)
+		result, itemErr = c.preCheckOutcome(ctx, decision, result, itemErr)
+		results[i] = PreCheckResult{Result: result, Err: itemErr}
+	}
+	return true
+}
+
+// rawPreCheckBatchItem is one result of a batch pre-check response.
+type rawPreCheckBatchItem struct {
+	rawPreCheckResponse
+	Error  string `json:"error,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Status int    `json:"status,omitempty"`
+}
+
+// sendPreCheckBatch sends one batch request and returns a result per item.
+func (c *AxonFlowClient) sendPreCheckBatch(ctx context.Context, items []PreCheckItem) ([]rawPreCheckBatchItem, error) {
+	type batchItem struct {
+		UserToken   string                 `json:"user_token"`
+		Query       string                 `json:"query"`
+		DataSources []string               `json:"data_sources"`
+		Context     map[string]interface{} `json:"context"`
+	}
+	body := struct {
+		ClientID string      `json:"client_id"`
+		Items    []batchItem `json:"items"`
+	}{ClientID: c.config.ClientID}
+	for _, item := range items {
+		b := batchItem{UserToken: item.UserToken, Query: item.Query, DataSources: item.DataSources, Context: item.Context}
+		if b.DataSources == nil {
+			b.DataSources = []string{}
+		}
+		if b.Context == nil {
+			b.Context = map[string]interface{}{}
+		}
+		body.Items = append(body.Items, b)
+	}
+
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/policy/pre-check/batch", body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.retrySafe = true // pre-checks only evaluate policies
+	httpReq.requestType = RequestTypePreCheck
+
+	c.logger.DebugContext(ctx, "AxonFlow batch pre-check",
+		"request_type", RequestTypePreCheck,
+		"items", len(items))
+
+	var resp struct {
+		Results []rawPreCheckBatchItem `json:"results"`
+	}
+	if err := c.sendJSON(ctx, httpReq, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(items) {
+		return nil, fmt.Errorf("batch pre-check returned %d results for %d items", len(resp.Results), len(items))
+	}
+	return resp.Results, nil
+}
+
+// preCheckBatchItem converts one result of a batch response.
+func (c *AxonFlowClient) preCheckBatchItem(ctx context.Context, raw rawPreCheckBatchItem) (*PolicyApprovalResult, error) {
+	if raw.Error != "" {
+		if raw.Status != 0 {
+			return nil, &APIError{StatusCode: raw.Status, Code: raw.Code, Message: raw.Error}
+		}
+		return nil, fmt.Errorf("pre-check failed: %s", raw.Error)
+	}
+	return c.preCheckResult(ctx, raw.rawPreCheckResponse), nil
+}
+
+// forEachLimit calls fn(0) ... fn(n-1) with at most limit calls running at once, and
+// returns when all have returned.
+func forEachLimit(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
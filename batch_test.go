@@ -0,0 +1,192 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPreCheckBatch(t *testing.T) {
+	var batches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/policy/pre-check/batch" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			return
+		}
+		atomic.AddInt32(&batches, 1)
+		var body struct {
+			Items []struct {
+				Query string `json:"query"`
+			} `json:"items"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		var results []map[string]interface{}
+		for i, item := range body.Items {
+			switch item.Query {
+			case "ssn 123-45-6789":
+				results = append(results, map[string]interface{}{"approved": false, "block_reason": "PII detected"})
+			case "":
+				results = append(results, map[string]interface{}{"error": "query is required", "status": 400, "code": "INVALID_REQUEST"})
+			default:
+				results = append(results, map[string]interface{}{"context_id": fmt.Sprintf("ctx-%d", i), "approved": true})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer server.Close()
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+
+	items := []PreCheckItem{{Query: "chunk one"}, {Query: "ssn 123-45-6789"}, {Query: ""}, {Query: "chunk four"}}
+	results, err := client.PreCheckBatch(context.Background(), items)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Total != 4 || len(batchErr.Failed) != 1 || batchErr.Failed[2] == nil {
+		t.Fatalf("expected one failed item, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || apiErr.Code != "INVALID_REQUEST" {
+		t.Errorf("expected the item error to unwrap to an APIError, got %v", err)
+	}
+	if len(results) != 4 || !results[0].Result.Approved || results[0].Result.ContextID != "ctx-0" || results[3].Result.ContextID != "ctx-3" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if results[1].Result.Approved || results[1].Result.BlockReason != "PII detected" {
+		t.Errorf("expected item 1 to be blocked, got %+v", results[1].Result)
+	}
+	if batches != 1 {
+		t.Errorf("expected one batch request, got %d", batches)
+	}
+
+	// Large batches are split
+	items = make([]PreCheckItem, 250)
+	for i := range items {
+		items[i].Query = "chunk"
+	}
+	if results, err := client.PreCheckBatch(context.Background(), items); err != nil || len(results) != 250 {
+		t.Fatalf("unexpected result: %d results, %v", len(results), err)
+	}
+	if batches != 4 {
+		t.Errorf("expected 3 more batch requests, got %d", batches-1)
+	}
+}
+
+func TestPreCheckBatchFallback(t *testing.T) {
+	var batches, singles, inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/policy/pre-check/batch" {
+			atomic.AddInt32(&batches, 1)
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&singles, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"context_id": "ctx", "approved": true})
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.Endpoint = server.URL
+	config.ClientID = "tenant"
+	config.BatchConcurrency = 2
+	client := NewClient(config)
+
+	items := make([]PreCheckItem, 10)
+	for i := range items {
+		items[i].Query = fmt.Sprintf("chunk %d", i)
+	}
+	for round := 0; round < 2; round++ {
+		results, err := client.PreCheckBatch(context.Background(), items)
+		if err != nil || len(results) != 10 || !results[9].Result.Approved {
+			t.Fatalf("unexpected result: %+v, %v", results, err)
+		}
+	}
+	if batches != 1 || singles != 20 {
+		t.Errorf("expected one batch attempt and 20 single pre-checks, got %d and %d", batches, singles)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 pre-checks in flight, got %d", maxInFlight)
+	}
+}
+
+func TestPreCheckBatchFallbackChunks(t *testing.T) {
+	var singles, inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/policy/pre-check/batch" {
+			time.Sleep(5 * time.Millisecond) // let the chunks' batch requests overlap
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&singles, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"context_id": "ctx", "approved": true})
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.Endpoint = server.URL
+	config.ClientID = "tenant"
+	config.BatchConcurrency = 2
+	client := NewClient(config)
+
+	// Three chunks, each finding the batch endpoint missing
+	items := make([]PreCheckItem, 2*preCheckBatchSize+50)
+	for i := range items {
+		items[i].Query = fmt.Sprintf("chunk %d", i)
+	}
+	results, err := client.PreCheckBatch(context.Background(), items)
+	if err != nil || !results[0].Result.Approved || !results[len(items)-1].Result.Approved {
+		t.Fatalf("unexpected result: %v", err)
+	}
+	if singles != int32(len(items)) {
+		t.Errorf("expected %d single pre-checks, got %d", len(items), singles)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 pre-checks in flight across chunks, got %d", maxInFlight)
+	}
+}
+
+func TestPreCheckBatchFailurePolicy(t *testing.T) {
+	client, _ := New(downURL(), WithCredentials("tenant", "secret"), WithoutRetry(),
+		WithFailurePolicy(FailurePolicy{PreCheck: FailOpen}))
+
+	results, err := client.PreCheckBatch(context.Background(), []PreCheckItem{{Query: "a"}, {Query: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !r.Result.Approved || r.Result.Fallback != FailOpen {
+			t.Errorf("expected each item to fail open, got %+v", r.Result)
+		}
+	}
+
+	client, _ = New(downURL(), WithCredentials("tenant", "secret"), WithoutRetry(),
+		WithFailurePolicy(FailurePolicy{PreCheck: FailClosed}))
+	_, err = client.PreCheckBatch(context.Background(), []PreCheckItem{{Query: "a"}, {Query: "b"}})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 2 {
+		t.Errorf("expected both items to fail, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Response cache for ExecuteQuery
+package axonflow
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Cache stores ExecuteQuery responses. Implement it to share a cache between clients
+// or processes (e.g. on Redis). Values are opaque, JSON-encoded responses; keys are
+// hashes that do not reveal the query. Implementations must be safe for concurrent use.
+//
+// Errors are logged and treated as cache misses; they never fail a call.
+type Cache interface {
+	// Get returns the value stored under key, and false if there is none or it expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key
+	Delete(ctx context.Context, key string) error
+}
+
+// LRUCache is the built-in in-memory Cache. It is bounded by number of entries and,
+// optionally, by total size, evicting the least recently used entries first. Expired
+// entries are dropped lazily; it starts no background goroutines.
+type LRUCache struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu      sync.Mutex
+	bytes   int64
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache creates an in-memory cache holding at most maxEntries entries (default:
+// 10000 if <= 0) and at most maxBytes of keys and values (unlimited if <= 0).
+func NewLRUCache(maxEntries int, maxBytes int64) *LRUCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &LRUCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (c *LRUCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+// Set implements Cache.
+func (c *LRUCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+
+	size := entrySize(key, value)
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return nil // would never fit
+	}
+
+	e := &lruEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	c.entries[key] = c.order.PushFront(e)
+	c.bytes += size
+
+	for c.order.Len() > c.maxEntries || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (c *LRUCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet dropped.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove deletes el. It must be called with c.mu held.
+func (c *LRUCache) remove(el *list.Element) {
+	e := el.Value.(*lruEntry)
+	c.order.Remove(el)
+	delete(c.entries, e.key)
+	c.bytes -= entrySize(e.key, e.value)
+}
+
+func entrySize(key string, value []byte) int64 {
+	return int64(len(key) + len(value))
+}
+
+// cacheBypassKey is the context key set by ContextWithCacheBypass.
+type cacheBypassKey struct{}
+
+// ContextWithCacheBypass returns a copy of ctx that makes ExecuteQuery skip the cache
+// lookup and always ask AxonFlow. The fresh response still replaces any cached one.
+func ContextWithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// queryCacheKey returns the cache key for an ExecuteQuery call. It covers the client
+// ID (so a shared backend can serve several tenants), the request type, user, query
+// and a canonical encoding of the context map (encoding/json sorts map keys).
+func (c *AxonFlowClient) queryCacheKey(requestType, query, userToken string, queryContext map[string]interface{}) string {
+	h := sha256.New()
+	for _, part := range []string{c.config.ClientID, requestType, userToken, query} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if len(queryContext) > 0 {
+		canonical, err := json.Marshal(queryContext)
+		if err != nil {
+			// Not JSON-encodable; the request itself will fail to marshal too
+			canonical = []byte(err.Error())
+		}
+		h.Write(canonical)
+	}
+	return "axonflow:query:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResponse looks up a cached ExecuteQuery response.
+func (c *AxonFlowClient) cachedResponse(ctx context.Context, key string) (*ClientResponse, bool) {
+	value, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.logger.WarnContext(ctx, "AxonFlow cache get failed", "error", err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var resp ClientResponse
+	if err := json.Unmarshal(value, &resp); err != nil {
+		c.logger.WarnContext(ctx, "AxonFlow cache entry is invalid, ignoring it", "error", err)
+		c.cache.Delete(ctx, key)
+		return nil, false
+	}
+	return &resp, true
+}
+
+// cacheResponse stores an ExecuteQuery response.
+func (c *AxonFlowClient) cacheResponse(ctx context.Context, key string, resp *ClientResponse) {
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if err := c.cache.Set(ctx, key, value, c.config.Cache.TTL); err != nil {
+		c.logger.WarnContext(ctx, "AxonFlow cache set failed", "error", err)
+	}
+}
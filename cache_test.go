@@ -0,0 +1,185 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2, 0)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), time.Hour)
+	c.Set(ctx, "b", []byte("2"), time.Hour)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), time.Hour)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUCacheMaxBytes(t *testing.T) {
+	c := NewLRUCache(100, 10)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1234"), time.Hour) // 5 bytes
+	c.Set(ctx, "b", []byte("1234"), time.Hour) // 10 bytes total
+	c.Set(ctx, "c", []byte("1234"), time.Hour) // evicts a
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("expected a to be evicted by size")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+
+	c.Set(ctx, "big", make([]byte, 20), time.Hour)
+	if _, ok, _ := c.Get(ctx, "big"); ok {
+		t.Error("expected an entry larger than MaxBytes not to be stored")
+	}
+
+	// Replacing an entry must not leak its size
+	c.Set(ctx, "b", []byte("1"), time.Hour)
+	c.Delete(ctx, "c")
+	if c.bytes != 2 {
+		t.Errorf("expected 2 bytes accounted, got %d", c.bytes)
+	}
+}
+
+func TestQueryCacheKeyIncludesContext(t *testing.T) {
+	client := NewClient(AxonFlowConfig{Endpoint: "http://localhost:8080", ClientID: "tenant-a"})
+
+	k1 := client.queryCacheKey("mcp-query", "SELECT 1", "user", map[string]interface{}{"connector": "pg", "params": map[string]interface{}{"a": 1, "b": 2}})
+	k2 := client.queryCacheKey("mcp-query", "SELECT 1", "user", map[string]interface{}{"params": map[string]interface{}{"b": 2, "a": 1}, "connector": "pg"})
+	k3 := client.queryCacheKey("mcp-query", "SELECT 1", "user", map[string]interface{}{"connector": "mysql"})
+
+	if k1 != k2 {
+		t.Error("expected key to be independent of map ordering")
+	}
+	if k1 == k3 {
+		t.Error("expected different contexts to produce different keys")
+	}
+
+	other := NewClient(AxonFlowConfig{Endpoint: "http://localhost:8080", ClientID: "tenant-b"})
+	if other.queryCacheKey("mcp-query", "SELECT 1", "user", nil) == client.queryCacheKey("mcp-query", "SELECT 1", "user", nil) {
+		t.Error("expected different clients to produce different keys")
+	}
+}
+
+// countingServer answers ExecuteQuery calls and counts them.
+func countingServer(calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		json.NewEncoder(w).Encode(ClientResponse{Success: true, Result: string(rune('0' + n))})
+	}))
+}
+
+func TestExecuteQueryCacheRespectsContext(t *testing.T) {
+	var calls int32
+	server := countingServer(&calls)
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+
+	client.ExecuteQuery("user", "SELECT 1", "mcp-query", map[string]interface{}{"connector": "pg"})
+	client.ExecuteQuery("user", "SELECT 1", "mcp-query", map[string]interface{}{"connector": "pg"})
+	client.ExecuteQuery("user", "SELECT 1", "mcp-query", map[string]interface{}{"connector": "mysql"})
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls (one cached), got %d", calls)
+	}
+}
+
+func TestExecuteQueryCacheBypass(t *testing.T) {
+	var calls int32
+	server := countingServer(&calls)
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+	ctx := context.Background()
+
+	client.ExecuteQueryContext(ctx, "user", "q", "chat", nil)
+	resp, _ := client.ExecuteQueryContext(ContextWithCacheBypass(ctx), "user", "q", "chat", nil)
+	if calls != 2 || resp.Result != "2" {
+		t.Fatalf("expected bypass to call AxonFlow, got %d calls and result %q", calls, resp.Result)
+	}
+
+	// The fresh response replaced the cached one
+	resp, _ = client.ExecuteQueryContext(ctx, "user", "q", "chat", nil)
+	if calls != 2 || resp.Result != "2" {
+		t.Errorf("expected refreshed cache entry, got %d calls and result %q", calls, resp.Result)
+	}
+}
+
+// failingCache is a Cache backend that always fails.
+type failingCache struct{ sets int32 }
+
+func (f *failingCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("backend down")
+}
+
+func (f *failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	atomic.AddInt32(&f.sets, 1)
+	return errors.New("backend down")
+}
+
+func (f *failingCache) Delete(context.Context, string) error { return nil }
+
+func TestExecuteQueryCustomCacheBackend(t *testing.T) {
+	var calls int32
+	server := countingServer(&calls)
+	defer server.Close()
+
+	backend := &failingCache{}
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Cache:    CacheConfig{Enabled: true, TTL: time.Minute, Backend: backend},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ExecuteQuery("user", "q", "chat", nil); err != nil {
+			t.Fatalf("expected cache errors to be ignored, got %v", err)
+		}
+	}
+	if calls != 2 || backend.sets != 2 {
+		t.Errorf("expected 2 calls and 2 sets, got %d and %d", calls, backend.sets)
+	}
+}
+
+func TestExecuteQuerySharedCacheBackend(t *testing.T) {
+	var calls int32
+	server := countingServer(&calls)
+	defer server.Close()
+
+	shared := NewLRUCache(100, 0)
+	config := AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Cache:    CacheConfig{Enabled: true, TTL: time.Minute, Backend: shared},
+	}
+
+	NewClient(config).ExecuteQuery("user", "q", "chat", nil)
+	resp, err := NewClient(config).ExecuteQuery("user", "q", "chat", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || resp.Result != "1" {
+		t.Errorf("expected the second client to hit the shared cache, got %d calls", calls)
+	}
+}
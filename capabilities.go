@@ -0,0 +1,261 @@
+// Server capability and version negotiation
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrFeatureUnavailable matches *FeatureUnavailableError.
+var ErrFeatureUnavailable = errors.New("axonflow: feature not available on this server")
+
+// capabilitiesTTL is how long GetCapabilities reuses a fetched result.
+const capabilitiesTTL = 15 * time.Minute
+
+// capabilitiesRetryDelay is how long feature checks wait before fetching capabilities
+// again after a failed fetch.
+const capabilitiesRetryDelay = time.Minute
+
+// Edition is the AxonFlow platform edition.
+type Edition string
+
+const (
+	EditionCommunity  Edition = "community"
+	EditionEnterprise Edition = "enterprise"
+)
+
+// Feature names a server feature that not every deployment offers.
+type Feature string
+
+const (
+	// FeaturePortal is the Customer Portal login (LoginToPortal, LogoutFromPortal)
+	FeaturePortal Feature = "portal"
+	// FeatureCodeGovernance is Git provider configuration and PR management
+	FeatureCodeGovernance Feature = "code_governance"
+	// FeaturePolicyOverrides is static policy overrides
+	FeaturePolicyOverrides Feature = "policy_overrides"
+	// FeaturePreCheckBatch is the batch pre-check endpoint (PreCheckBatch)
+	FeaturePreCheckBatch Feature = "pre_check_batch"
+)
+
+// enterpriseFeatures are only offered by the enterprise edition.
+var enterpriseFeatures = map[Feature]bool{
+	FeaturePortal:          true,
+	FeatureCodeGovernance:  true,
+	FeaturePolicyOverrides: true,
+}
+
+// Capabilities describes what an AxonFlow server supports.
+type Capabilities struct {
+	ServerVersion string           // Platform version (empty if not reported)
+	Edition       Edition          // Platform edition (empty if not reported)
+	Features      map[Feature]bool // Feature flags reported by the server
+	FetchedAt     time.Time
+}
+
+// Supports reports whether the server offers feature. A feature the server reports
+// is used as reported. Otherwise enterprise features are unavailable on the community
+// edition, and anything else is assumed to be available.
+func (c *Capabilities) Supports(feature Feature) bool {
+	if enabled, ok := c.Features[feature]; ok {
+		return enabled
+	}
+	return !(c.Edition == EditionCommunity && enterpriseFeatures[feature])
+}
+
+// FeatureUnavailableError is returned without contacting the server when the server's
+// capabilities show that a feature is not available.
+type FeatureUnavailableError struct {
+	Feature       Feature
+	Edition       Edition
+	ServerVersion string
+}
+
+func (e *FeatureUnavailableError) Error() string {
+	var server []string
+	if e.Edition != "" {
+		server = append(server, string(e.Edition)+" edition")
+	}
+	if e.ServerVersion != "" {
+		server = append(server, "version "+e.ServerVersion)
+	}
+	if len(server) == 0 {
+		return fmt.Sprintf("axonflow: feature %q is not available on this server", e.Feature)
+	}
+	return fmt.Sprintf("axonflow: feature %q is not available on this server (%s)", e.Feature, strings.Join(server, ", "))
+}
+
+// Is reports whether target is ErrFeatureUnavailable.
+func (e *FeatureUnavailableError) Is(target error) bool { return target == ErrFeatureUnavailable }
+
+// capabilityCache holds the capabilities fetched by a client.
+type capabilityCache struct {
+	mu       sync.Mutex
+	caps     *Capabilities
+	failedAt time.Time // Last failed fetch, if after caps were fetched
+}
+
+// get returns the capabilities if they were fetched less than capabilitiesTTL ago.
+func (cc *capabilityCache) get(now time.Time) *Capabilities {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.caps == nil || now.Sub(cc.caps.FetchedAt) >= capabilitiesTTL {
+		return nil
+	}
+	return cc.caps
+}
+
+// last returns the last fetched capabilities, however old.
+func (cc *capabilityCache) last() *Capabilities {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.caps
+}
+
+func (cc *capabilityCache) set(caps *Capabilities) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.caps = caps
+	cc.failedAt = time.Time{}
+}
+
+// fail records a failed fetch.
+func (cc *capabilityCache) fail(now time.Time) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.failedAt = now
+}
+
+// failedRecently reports whether a fetch failed less than capabilitiesRetryDelay ago.
+func (cc *capabilityCache) failedRecently(now time.Time) bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return !cc.failedAt.IsZero() && now.Sub(cc.failedAt) < capabilitiesRetryDelay
+}
+
+// GetCapabilities returns the server's version, edition and feature flags. The
+// result is cached by the client for 15 minutes.
+//
+// Capabilities are read from /api/v1/capabilities. Servers without that route are
+// described from their /health body (version, edition and features, if present).
+//
+// Once capabilities have been fetched, enterprise methods (Customer Portal, code
+// governance and policy overrides) return a *FeatureUnavailableError, matching
+// ErrFeatureUnavailable, instead of sending a request the server would reject. Set
+// AxonFlowConfig.NegotiateCapabilities to fetch them automatically before the first
+// such call and again once they are 15 minutes old. The last known capabilities apply
+// until a new fetch succeeds.
+func (c *AxonFlowClient) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	if caps := c.caps.get(time.Now()); caps != nil {
+		return caps, nil
+	}
+
+	caps, err := c.fetchCapabilities(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.caps.fail(time.Now())
+		}
+		return nil, err
+	}
+	c.caps.set(caps)
+
+	c.logger.DebugContext(ctx, "AxonFlow capabilities fetched",
+		"server_version", caps.ServerVersion,
+		"edition", caps.Edition,
+		"features", len(caps.Features))
+
+	return caps, nil
+}
+
+func (c *AxonFlowClient) fetchCapabilities(ctx context.Context) (*Capabilities, error) {
+	body, err := c.sendRaw(ctx, &request{method: http.MethodGet, url: c.config.Endpoint + "/api/v1/capabilities"})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+		// Older servers: fall back to what /health reports
+		body, err = c.sendRaw(ctx, &request{method: http.MethodGet, url: c.config.Endpoint + "/health", auth: authNone})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+
+	caps := &Capabilities{FetchedAt: time.Now()}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		// A health route answering plain text reports nothing
+		return caps, nil
+	}
+
+	for _, key := range []string{"version", "server_version", "axonflow_version"} {
+		if v, ok := raw[key].(string); ok && v != "" {
+			caps.ServerVersion = v
+			break
+		}
+	}
+	if edition, ok := raw["edition"].(string); ok {
+		caps.Edition = Edition(strings.ToLower(edition))
+	}
+	caps.Features = parseFeatures(raw["features"])
+	return caps, nil
+}
+
+// parseFeatures decodes a list of enabled feature names or a map of name to boolean.
+func parseFeatures(v interface{}) map[Feature]bool {
+	features := map[Feature]bool{}
+	switch v := v.(type) {
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				features[Feature(s)] = true
+			}
+		}
+	case map[string]interface{}:
+		for name, enabled := range v {
+			if b, ok := enabled.(bool); ok {
+				features[Feature(name)] = b
+			}
+		}
+	}
+	if len(features) == 0 {
+		return nil
+	}
+	return features
+}
+
+// FeatureNames returns the names of the enabled features, sorted.
+func (c *Capabilities) FeatureNames() []string {
+	var names []string
+	for feature, enabled := range c.Features {
+		if enabled {
+			names = append(names, string(feature))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requireFeature returns a *FeatureUnavailableError if the server's capabilities
+// show that feature is not available. Capabilities are only fetched here when
+// NegotiateCapabilities is set, at most once per capabilitiesRetryDelay while fetches
+// fail. A failure to fetch them does not block the call; the last known capabilities,
+// if any, still apply.
+func (c *AxonFlowClient) requireFeature(ctx context.Context, feature Feature) error {
+	now := time.Now()
+	caps := c.caps.get(now)
+	if caps == nil && c.config.NegotiateCapabilities && !c.caps.failedRecently(now) {
+		caps, _ = c.GetCapabilities(ctx)
+	}
+	if caps == nil {
+		caps = c.caps.last()
+	}
+	if caps == nil || caps.Supports(feature) {
+		return nil
+	}
+	return &FeatureUnavailableError{Feature: feature, Edition: caps.Edition, ServerVersion: caps.ServerVersion}
+}
@@ -0,0 +1,159 @@
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCapabilitiesServer serves capabilities (or 404 when empty) and counts requests
+// to the policy override API.
+func newCapabilitiesServer(t *testing.T, capabilities, health string, overrides *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/capabilities":
+			if capabilities == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(capabilities))
+		case "/health":
+			w.Write([]byte(health))
+		default:
+			atomic.AddInt32(overrides, 1)
+			w.Write([]byte(`{"overrides": [], "count": 0}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetCapabilities(t *testing.T) {
+	var overrides int32
+	server := newCapabilitiesServer(t,
+		`{"version": "3.4.0", "edition": "Enterprise", "features": {"portal": true, "policy_overrides": false}}`, "", &overrides)
+	client, _ := New(server.URL)
+
+	caps, err := client.GetCapabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.ServerVersion != "3.4.0" || caps.Edition != EditionEnterprise {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+	if !caps.Supports(FeaturePortal) || caps.Supports(FeaturePolicyOverrides) || !caps.Supports(FeatureCodeGovernance) {
+		t.Errorf("unexpected feature support: %v", caps.Features)
+	}
+	if again, _ := client.GetCapabilities(context.Background()); again != caps {
+		t.Error("expected the cached capabilities to be reused")
+	}
+
+	_, err = client.ListPolicyOverrides()
+	var featErr *FeatureUnavailableError
+	if !errors.Is(err, ErrFeatureUnavailable) || !errors.As(err, &featErr) || featErr.Feature != FeaturePolicyOverrides {
+		t.Errorf("expected a FeatureUnavailableError, got %v", err)
+	}
+	if overrides != 0 {
+		t.Errorf("expected no request to the override API, got %d", overrides)
+	}
+}
+
+func TestGetCapabilitiesFromHealth(t *testing.T) {
+	var overrides int32
+	server := newCapabilitiesServer(t, "", `{"status": "healthy", "version": "3.2.0", "edition": "community"}`, &overrides)
+	client, _ := New(server.URL, WithCapabilityNegotiation())
+
+	// Negotiated on first use: community servers do not offer portal features
+	_, err := client.LoginToPortal("org", "password")
+	if !errors.Is(err, ErrFeatureUnavailable) {
+		t.Fatalf("expected ErrFeatureUnavailable, got %v", err)
+	}
+	if err.Error() != `axonflow: feature "portal" is not available on this server (community edition, version 3.2.0)` {
+		t.Errorf("unexpected message: %v", err)
+	}
+	if _, err := client.ListGitProviders(); !errors.Is(err, ErrFeatureUnavailable) {
+		t.Errorf("expected code governance to be unavailable, got %v", err)
+	}
+}
+
+func TestCapabilitiesNotNegotiated(t *testing.T) {
+	var overrides int32
+	server := newCapabilitiesServer(t, `{"edition": "community"}`, "", &overrides)
+	client, _ := New(server.URL)
+
+	// Without known capabilities, calls go through as before
+	if _, err := client.ListPolicyOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	if overrides != 1 {
+		t.Errorf("expected one request to the override API, got %d", overrides)
+	}
+}
+
+func TestCapabilitiesFetchFailures(t *testing.T) {
+	var down, fetches, overrides int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/api/v1/capabilities":
+			atomic.AddInt32(&overrides, 1)
+			w.Write([]byte(`{"overrides": [], "count": 0}`))
+		case atomic.LoadInt32(&down) == 1:
+			atomic.AddInt32(&fetches, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			atomic.AddInt32(&fetches, 1)
+			w.Write([]byte(`{"edition": "enterprise", "features": {"policy_overrides": false}}`))
+		}
+	}))
+	defer server.Close()
+
+	// Failed fetches are not repeated for every call
+	atomic.StoreInt32(&down, 1)
+	client, _ := New(server.URL, WithCapabilityNegotiation(), WithoutRetry())
+	for i := 0; i < 3; i++ {
+		if _, err := client.ListPolicyOverrides(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 || overrides != 3 {
+		t.Errorf("expected one capabilities fetch and 3 override requests, got %d and %d", fetches, overrides)
+	}
+
+	// Expired capabilities keep applying while they cannot be refetched
+	atomic.StoreInt32(&down, 0)
+	atomic.StoreInt32(&fetches, 0)
+	atomic.StoreInt32(&overrides, 0)
+	client, _ = New(server.URL, WithCapabilityNegotiation(), WithoutRetry())
+	if _, err := client.GetCapabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	client.caps.last().FetchedAt = time.Now().Add(-capabilitiesTTL)
+	atomic.StoreInt32(&down, 1)
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListPolicyOverrides(); !errors.Is(err, ErrFeatureUnavailable) {
+			t.Errorf("expected the last known capabilities to apply, got %v", err)
+		}
+	}
+	if fetches != 2 || overrides != 0 {
+		t.Errorf("expected one refetch and no override requests, got %d fetches and %d requests", fetches, overrides)
+	}
+}
+
+func TestParseFeatures(t *testing.T) {
+	list := parseFeatures([]interface{}{"portal", "code_governance", 3})
+	if len(list) != 2 || !list[FeaturePortal] {
+		t.Errorf("unexpected features from a list: %v", list)
+	}
+	if parseFeatures(nil) != nil || parseFeatures("portal") != nil {
+		t.Error("expected no features from an unknown shape")
+	}
+	caps := &Capabilities{Features: parseFeatures(map[string]interface{}{"b": true, "a": true, "c": false})}
+	if names := caps.FeatureNames(); len(names) != 2 || names[0] != "a" {
+		t.Errorf("unexpected feature names: %v", names)
+	}
+}
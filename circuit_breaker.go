@@ -0,0 +1,186 @@
+// Circuit breaker guarding calls to the AxonFlow Agent
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting AxonFlow while the circuit breaker is
+// open. It counts as AxonFlow being unavailable, so the configured FailurePolicy applies.
+var ErrCircuitOpen = errors.New("axonflow: circuit breaker open")
+
+// CircuitState is the state of the client's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through (normal operation)
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen until the cool-down ends
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through to test recovery
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures the circuit breaker shared by all calls made by a
+// client. Only failures that mean AxonFlow is unavailable (network errors, timeouts
+// and 5xx responses) count towards opening the circuit; 4xx responses and policy
+// blocks show the Agent is up and count as successes.
+type CircuitBreakerConfig struct {
+	Enabled             bool          // Enable the circuit breaker (default: false)
+	FailureThreshold    int           // Consecutive failures that open the circuit (default: 5)
+	CoolDown            time.Duration // How long the circuit stays open before probing (default: 30s)
+	HalfOpenMaxRequests int           // Concurrent probe requests allowed while half-open (default: 1)
+	SuccessThreshold    int           // Successful probes needed to close the circuit (default: 1)
+
+	// OnStateChange is called after every state transition. It runs synchronously on
+	// the goroutine that caused the transition and must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// circuitBreaker implements the closed -> open -> half-open -> closed state machine.
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int // consecutive failures while closed
+	successes int // successful probes while half-open
+	probes    int // probes in flight while half-open
+	openedAt  time.Time
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+	return &circuitBreaker{config: config, now: time.Now}
+}
+
+// State returns the current state, moving from open to half-open once the cool-down
+// has elapsed.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	from, to := b.state, b.advance()
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+// allow reports whether a request may be sent. Every call that returns nil must be
+// followed by exactly one call to done.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	state := b.advance()
+
+	var err error
+	switch state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.config.HalfOpenMaxRequests {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(from, state)
+	return err
+}
+
+// done records the outcome of a request admitted by allow.
+func (b *circuitBreaker) done(err error) {
+	b.mu.Lock()
+	from := b.state
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	switch {
+	case err != nil && isUnavailableError(err):
+		b.successes = 0
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+			b.trip()
+		}
+	case errors.Is(err, context.Canceled):
+		// The caller gave up; this says nothing about the Agent
+	default:
+		b.failures = 0
+		if b.state == CircuitHalfOpen {
+			b.successes++
+			if b.successes >= b.config.SuccessThreshold {
+				b.state = CircuitClosed
+				b.successes = 0
+			}
+		}
+	}
+
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// advance moves an open circuit to half-open once the cool-down has elapsed.
+// It must be called with b.mu held.
+func (b *circuitBreaker) advance() CircuitState {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.CoolDown {
+		b.state = CircuitHalfOpen
+		b.successes = 0
+		b.probes = 0
+	}
+	return b.state
+}
+
+// trip opens the circuit. It must be called with b.mu held.
+func (b *circuitBreaker) trip() {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
+
+// CircuitState returns the state of the client's circuit breaker. It is always
+// CircuitClosed when the breaker is disabled.
+func (c *AxonFlowClient) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
@@ -0,0 +1,199 @@
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testBreaker returns a breaker with a controllable clock.
+func testBreaker(config CircuitBreakerConfig) (*circuitBreaker, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	b := newCircuitBreaker(config)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	type transition struct{ from, to CircuitState }
+	var transitions []transition
+
+	b, now := testBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		CoolDown:         10 * time.Second,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, transition{from, to})
+		},
+	})
+	unavailable := &APIError{StatusCode: 503}
+
+	// Two consecutive failures open the circuit
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("attempt %d rejected while closed: %v", i+1, err)
+		}
+		b.done(unavailable)
+	}
+	if b.State() != CircuitOpen {
+		t.Fatalf("expected open, got %v", b.State())
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// After the cool-down a single probe is admitted
+	*now = now.Add(10 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be admitted, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second concurrent probe to be rejected, got %v", err)
+	}
+
+	// A failed probe re-opens the circuit
+	b.done(unavailable)
+	if b.State() != CircuitOpen {
+		t.Fatalf("expected open after failed probe, got %v", b.State())
+	}
+
+	// A successful probe closes it
+	*now = now.Add(10 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be admitted, got %v", err)
+	}
+	b.done(nil)
+	if b.State() != CircuitClosed {
+		t.Fatalf("expected closed after successful probe, got %v", b.State())
+	}
+
+	expected := []transition{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("transition %d: expected %v, got %v", i, expected[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresDefinitiveAnswers(t *testing.T) {
+	b, _ := testBreaker(CircuitBreakerConfig{FailureThreshold: 2})
+
+	for _, err := range []error{
+		&APIError{StatusCode: 503},
+		&ValidationError{APIError: &APIError{StatusCode: 400}}, // resets the count
+		&APIError{StatusCode: 503},
+		context.Canceled, // neutral
+		&PolicyBlockedError{APIError: &APIError{StatusCode: 403}},
+		&APIError{StatusCode: 502},
+	} {
+		if allowErr := b.allow(); allowErr != nil {
+			t.Fatalf("unexpected rejection: %v", allowErr)
+		}
+		b.done(err)
+	}
+
+	if b.State() != CircuitClosed {
+		t.Errorf("expected 4xx responses to keep the circuit closed, got %v", b.State())
+	}
+}
+
+func TestCircuitBreakerSuccessThreshold(t *testing.T) {
+	b, now := testBreaker(CircuitBreakerConfig{
+		FailureThreshold:    1,
+		CoolDown:            time.Second,
+		HalfOpenMaxRequests: 2,
+		SuccessThreshold:    2,
+	})
+
+	b.allow()
+	b.done(&APIError{StatusCode: 500})
+	*now = now.Add(time.Second)
+
+	b.allow()
+	b.done(nil)
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open after 1 of 2 successful probes, got %v", b.State())
+	}
+	b.allow()
+	b.done(nil)
+	if b.State() != CircuitClosed {
+		t.Fatalf("expected closed after 2 successful probes, got %v", b.State())
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	for state, want := range map[CircuitState]string{
+		CircuitClosed:    "closed",
+		CircuitOpen:      "open",
+		CircuitHalfOpen:  "half-open",
+		CircuitState(42): "unknown",
+	} {
+		if got := state.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", state, got, want)
+		}
+	}
+}
+
+func TestClientCircuitBreakerShortCircuits(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var opened int32
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: CircuitBreakerConfig{
+			Enabled:          true,
+			FailureThreshold: 3,
+			CoolDown:         time.Hour,
+			OnStateChange: func(from, to CircuitState) {
+				if to == CircuitOpen {
+					atomic.AddInt32(&opened, 1)
+				}
+			},
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		client.ListStaticPolicies(nil)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected the breaker to stop calls after 3 failures, got %d calls", calls)
+	}
+	if opened != 1 {
+		t.Errorf("expected 1 transition to open, got %d", opened)
+	}
+	if client.CircuitState() != CircuitOpen {
+		t.Errorf("expected open circuit, got %v", client.CircuitState())
+	}
+
+	_, err := client.ListStaticPolicies(nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestClientCircuitStateDisabled(t *testing.T) {
+	client := NewClient(AxonFlowConfig{Endpoint: "http://localhost:8080"})
+	if client.CircuitState() != CircuitClosed {
+		t.Errorf("expected closed circuit when disabled, got %v", client.CircuitState())
+	}
+}
@@ -3,12 +3,8 @@
 package axonflow
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"net/url"
 	"time"
 )
@@ -222,100 +218,41 @@ type ListPRsResponse struct {
 
 // portalRequest makes an HTTP request to the Customer Portal API (for enterprise features).
 // Requires prior authentication via LoginToPortal().
-func (c *AxonFlowClient) portalRequest(method, path string, body interface{}, result interface{}) error {
+func (c *AxonFlowClient) portalRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	if err := c.requireFeature(ctx, FeatureCodeGovernance); err != nil {
+		return err
+	}
 	// Check if logged in
 	if c.sessionCookie == "" {
 		return fmt.Errorf("not logged in to Customer Portal. Call LoginToPortal() first")
 	}
 
-	var reqBody io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(bodyBytes)
-	}
-
-	fullURL := c.config.Endpoint + path
-
-	req, err := http.NewRequest(method, fullURL, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Add session cookie for authentication
-	req.AddCookie(&http.Cookie{
-		Name:  "axonflow_session",
-		Value: c.sessionCookie,
-	})
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Portal request: %s %s", method, path)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+	req, err := newJSONRequest(method, c.config.Endpoint+path, body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
+		return err
 	}
+	req.auth = authSession
 
-	// Handle no-content responses
-	if resp.StatusCode == 204 || len(respBody) == 0 {
-		return nil
-	}
-
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-	}
+	c.debugf("Portal request: %s %s", method, path)
 
-	return nil
+	return c.sendJSON(ctx, req, result)
 }
 
 // portalRequestRaw makes an HTTP request to portal and returns raw bytes (for CSV export).
 // Requires prior authentication via LoginToPortal().
-func (c *AxonFlowClient) portalRequestRaw(method, path string) ([]byte, error) {
+func (c *AxonFlowClient) portalRequestRaw(ctx context.Context, method, path string) ([]byte, error) {
+	if err := c.requireFeature(ctx, FeatureCodeGovernance); err != nil {
+		return nil, err
+	}
 	// Check if logged in
 	if c.sessionCookie == "" {
 		return nil, fmt.Errorf("not logged in to Customer Portal. Call LoginToPortal() first")
 	}
 
-	fullURL := c.config.Endpoint + path
-
-	req, err := http.NewRequest(method, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add session cookie for authentication
-	req.AddCookie(&http.Cookie{
-		Name:  "axonflow_session",
-		Value: c.sessionCookie,
-	})
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+	req := newRequest(method, c.config.Endpoint+path)
+	req.auth = authSession
 
-	return io.ReadAll(resp.Body)
+	return c.sendRaw(ctx, req)
 }
 
 // ============================================================================
@@ -325,12 +262,15 @@ func (c *AxonFlowClient) portalRequestRaw(method, path string) ([]byte, error) {
 // ValidateGitProvider validates Git provider credentials before configuration.
 // Use this to verify tokens and connectivity before saving.
 func (c *AxonFlowClient) ValidateGitProvider(req *ValidateGitProviderRequest) (*ValidateGitProviderResponse, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Validating Git provider: %s", req.Type)
-	}
+	return c.ValidateGitProviderContext(context.Background(), req)
+}
+
+// ValidateGitProviderContext is like ValidateGitProvider but carries a context.
+func (c *AxonFlowClient) ValidateGitProviderContext(ctx context.Context, req *ValidateGitProviderRequest) (*ValidateGitProviderResponse, error) {
+	c.debugf("Validating Git provider: %s", req.Type)
 
 	var resp ValidateGitProviderResponse
-	if err := c.portalRequest("POST", "/api/v1/code-governance/git-providers/validate", req, &resp); err != nil {
+	if err := c.portalRequest(ctx, "POST", "/api/v1/code-governance/git-providers/validate", req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -340,12 +280,15 @@ func (c *AxonFlowClient) ValidateGitProvider(req *ValidateGitProviderRequest) (*
 // ConfigureGitProvider configures a Git provider for code governance.
 // Supports GitHub, GitLab, and Bitbucket (cloud and self-hosted).
 func (c *AxonFlowClient) ConfigureGitProvider(req *ConfigureGitProviderRequest) (*ConfigureGitProviderResponse, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Configuring Git provider: %s", req.Type)
-	}
+	return c.ConfigureGitProviderContext(context.Background(), req)
+}
+
+// ConfigureGitProviderContext is like ConfigureGitProvider but carries a context.
+func (c *AxonFlowClient) ConfigureGitProviderContext(ctx context.Context, req *ConfigureGitProviderRequest) (*ConfigureGitProviderResponse, error) {
+	c.debugf("Configuring Git provider: %s", req.Type)
 
 	var resp ConfigureGitProviderResponse
-	if err := c.portalRequest("POST", "/api/v1/code-governance/git-providers", req, &resp); err != nil {
+	if err := c.portalRequest(ctx, "POST", "/api/v1/code-governance/git-providers", req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -354,12 +297,15 @@ func (c *AxonFlowClient) ConfigureGitProvider(req *ConfigureGitProviderRequest)
 
 // ListGitProviders lists all configured Git providers for the tenant.
 func (c *AxonFlowClient) ListGitProviders() (*ListGitProvidersResponse, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing Git providers")
-	}
+	return c.ListGitProvidersContext(context.Background())
+}
+
+// ListGitProvidersContext is like ListGitProviders but carries a context.
+func (c *AxonFlowClient) ListGitProvidersContext(ctx context.Context) (*ListGitProvidersResponse, error) {
+	c.debugf("Listing Git providers")
 
 	var resp ListGitProvidersResponse
-	if err := c.portalRequest("GET", "/api/v1/code-governance/git-providers", nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "GET", "/api/v1/code-governance/git-providers", nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -368,22 +314,28 @@ func (c *AxonFlowClient) ListGitProviders() (*ListGitProvidersResponse, error) {
 
 // DeleteGitProvider deletes a configured Git provider.
 func (c *AxonFlowClient) DeleteGitProvider(providerType GitProviderType) error {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleting Git provider: %s", providerType)
-	}
+	return c.DeleteGitProviderContext(context.Background(), providerType)
+}
+
+// DeleteGitProviderContext is like DeleteGitProvider but carries a context.
+func (c *AxonFlowClient) DeleteGitProviderContext(ctx context.Context, providerType GitProviderType) error {
+	c.debugf("Deleting Git provider: %s", providerType)
 
-	return c.portalRequest("DELETE", "/api/v1/code-governance/git-providers/"+string(providerType), nil, nil)
+	return c.portalRequest(ctx, "DELETE", "/api/v1/code-governance/git-providers/"+string(providerType), nil, nil)
 }
 
 // CreatePR creates a Pull Request from LLM-generated code.
 // This creates a PR with full audit trail linking back to the AI request.
 func (c *AxonFlowClient) CreatePR(req *CreatePRRequest) (*CreatePRResponse, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Creating PR: %s/%s - %s", req.Owner, req.Repo, req.Title)
-	}
+	return c.CreatePRContext(context.Background(), req)
+}
+
+// CreatePRContext is like CreatePR but carries a context.
+func (c *AxonFlowClient) CreatePRContext(ctx context.Context, req *CreatePRRequest) (*CreatePRResponse, error) {
+	c.debugf("Creating PR: %s/%s - %s", req.Owner, req.Repo, req.Title)
 
 	var resp CreatePRResponse
-	if err := c.portalRequest("POST", "/api/v1/code-governance/prs", req, &resp); err != nil {
+	if err := c.portalRequest(ctx, "POST", "/api/v1/code-governance/prs", req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -410,17 +362,20 @@ func (o *ListPRsOptions) buildQueryParams() string {
 
 // ListPRs lists Pull Requests created through code governance.
 func (c *AxonFlowClient) ListPRs(options *ListPRsOptions) (*ListPRsResponse, error) {
+	return c.ListPRsContext(context.Background(), options)
+}
+
+// ListPRsContext is like ListPRs but carries a context.
+func (c *AxonFlowClient) ListPRsContext(ctx context.Context, options *ListPRsOptions) (*ListPRsResponse, error) {
 	path := "/api/v1/code-governance/prs"
 	if options != nil {
 		path += options.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing PRs: %s", path)
-	}
+	c.debugf("Listing PRs: %s", path)
 
 	var resp ListPRsResponse
-	if err := c.portalRequest("GET", path, nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -429,12 +384,15 @@ func (c *AxonFlowClient) ListPRs(options *ListPRsOptions) (*ListPRsResponse, err
 
 // GetPR gets a specific PR record by ID.
 func (c *AxonFlowClient) GetPR(prID string) (*PRRecord, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting PR: %s", prID)
-	}
+	return c.GetPRContext(context.Background(), prID)
+}
+
+// GetPRContext is like GetPR but carries a context.
+func (c *AxonFlowClient) GetPRContext(ctx context.Context, prID string) (*PRRecord, error) {
+	c.debugf("Getting PR: %s", prID)
 
 	var resp PRRecord
-	if err := c.portalRequest("GET", "/api/v1/code-governance/prs/"+prID, nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "GET", "/api/v1/code-governance/prs/"+prID, nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -444,12 +402,15 @@ func (c *AxonFlowClient) GetPR(prID string) (*PRRecord, error) {
 // SyncPRStatus syncs PR status with the Git provider.
 // This updates the local record with the current state from GitHub/GitLab/Bitbucket.
 func (c *AxonFlowClient) SyncPRStatus(prID string) (*PRRecord, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Syncing PR status: %s", prID)
-	}
+	return c.SyncPRStatusContext(context.Background(), prID)
+}
+
+// SyncPRStatusContext is like SyncPRStatus but carries a context.
+func (c *AxonFlowClient) SyncPRStatusContext(ctx context.Context, prID string) (*PRRecord, error) {
+	c.debugf("Syncing PR status: %s", prID)
 
 	var resp PRRecord
-	if err := c.portalRequest("POST", "/api/v1/code-governance/prs/"+prID+"/sync", nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "POST", "/api/v1/code-governance/prs/"+prID+"/sync", nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -460,9 +421,12 @@ func (c *AxonFlowClient) SyncPRStatus(prID string) (*PRRecord, error) {
 // This is an enterprise feature for cleaning up test/demo PRs.
 // Supports all Git providers: GitHub, GitLab, Bitbucket.
 func (c *AxonFlowClient) ClosePR(prID string, deleteBranch bool) (*PRRecord, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Closing PR: %s (deleteBranch=%v)", prID, deleteBranch)
-	}
+	return c.ClosePRContext(context.Background(), prID, deleteBranch)
+}
+
+// ClosePRContext is like ClosePR but carries a context.
+func (c *AxonFlowClient) ClosePRContext(ctx context.Context, prID string, deleteBranch bool) (*PRRecord, error) {
+	c.debugf("Closing PR: %s (deleteBranch=%v)", prID, deleteBranch)
 
 	path := "/api/v1/code-governance/prs/" + prID
 	if deleteBranch {
@@ -470,7 +434,7 @@ func (c *AxonFlowClient) ClosePR(prID string, deleteBranch bool) (*PRRecord, err
 	}
 
 	var resp PRRecord
-	if err := c.portalRequest("DELETE", path, nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "DELETE", path, nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -556,12 +520,15 @@ func (o *ExportOptions) buildQueryParams() string {
 // This provides compliance dashboard data including PR counts, file totals,
 // and security findings (secrets detected, unsafe patterns).
 func (c *AxonFlowClient) GetCodeGovernanceMetrics() (*CodeGovernanceMetrics, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting code governance metrics")
-	}
+	return c.GetCodeGovernanceMetricsContext(context.Background())
+}
+
+// GetCodeGovernanceMetricsContext is like GetCodeGovernanceMetrics but carries a context.
+func (c *AxonFlowClient) GetCodeGovernanceMetricsContext(ctx context.Context) (*CodeGovernanceMetrics, error) {
+	c.debugf("Getting code governance metrics")
 
 	var resp CodeGovernanceMetrics
-	if err := c.portalRequest("GET", "/api/v1/code-governance/metrics", nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "GET", "/api/v1/code-governance/metrics", nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -572,6 +539,11 @@ func (c *AxonFlowClient) GetCodeGovernanceMetrics() (*CodeGovernanceMetrics, err
 // Supports JSON and CSV formats with optional date filtering.
 // For CSV format, use ExportCodeGovernanceDataCSV which returns []byte.
 func (c *AxonFlowClient) ExportCodeGovernanceData(options *ExportOptions) (*ExportResponse, error) {
+	return c.ExportCodeGovernanceDataContext(context.Background(), options)
+}
+
+// ExportCodeGovernanceDataContext is like ExportCodeGovernanceData but carries a context.
+func (c *AxonFlowClient) ExportCodeGovernanceDataContext(ctx context.Context, options *ExportOptions) (*ExportResponse, error) {
 	path := "/api/v1/code-governance/export"
 	if options != nil {
 		// Force JSON format for structured response
@@ -580,12 +552,10 @@ func (c *AxonFlowClient) ExportCodeGovernanceData(options *ExportOptions) (*Expo
 		path += opts.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Exporting code governance data: %s", path)
-	}
+	c.debugf("Exporting code governance data: %s", path)
 
 	var resp ExportResponse
-	if err := c.portalRequest("GET", path, nil, &resp); err != nil {
+	if err := c.portalRequest(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -595,6 +565,11 @@ func (c *AxonFlowClient) ExportCodeGovernanceData(options *ExportOptions) (*Expo
 // ExportCodeGovernanceDataCSV exports code governance data as CSV.
 // Returns the raw CSV bytes suitable for saving to file or streaming.
 func (c *AxonFlowClient) ExportCodeGovernanceDataCSV(options *ExportOptions) ([]byte, error) {
+	return c.ExportCodeGovernanceDataCSVContext(context.Background(), options)
+}
+
+// ExportCodeGovernanceDataCSVContext is like ExportCodeGovernanceDataCSV but carries a context.
+func (c *AxonFlowClient) ExportCodeGovernanceDataCSVContext(ctx context.Context, options *ExportOptions) ([]byte, error) {
 	path := "/api/v1/code-governance/export?format=csv"
 	if options != nil {
 		opts := *options
@@ -602,9 +577,7 @@ func (c *AxonFlowClient) ExportCodeGovernanceDataCSV(options *ExportOptions) ([]
 		path = "/api/v1/code-governance/export" + opts.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Exporting code governance data as CSV: %s", path)
-	}
+	c.debugf("Exporting code governance data as CSV: %s", path)
 
-	return c.portalRequestRaw("GET", path)
+	return c.portalRequestRaw(ctx, "GET", path)
 }
@@ -2,6 +2,7 @@ package axonflow
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -682,6 +683,36 @@ func TestExportCodeGovernanceDataCSV(t *testing.T) {
 	}
 }
 
+func TestExportCodeGovernanceDataCSVError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/auth/login" && r.Method == "POST" {
+			w.Header().Set("Set-Cookie", "axonflow_session=abc123; Path=/; HttpOnly")
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(PortalLoginResponse{SessionID: "sess-123", OrgID: "test-org"})
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "session expired"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:     server.URL,
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+	})
+
+	if _, err := client.LoginToPortal("test-org", "password"); err != nil {
+		t.Fatalf("LoginToPortal failed: %v", err)
+	}
+
+	csv, err := client.ExportCodeGovernanceDataCSV(nil)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Message != "session expired" {
+		t.Errorf("Expected an AuthError, got %q, %v", csv, err)
+	}
+}
+
 func TestPortalRequestWithoutLogin(t *testing.T) {
 	client := NewClient(AxonFlowConfig{
 		Endpoint:     "http://localhost",
@@ -0,0 +1,704 @@
+// Configuration from the environment and JSON config files
+package axonflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv, LoadConfig and LoadConfigProfile.
+// Empty variables are ignored. Durations are Go durations ("30s", "1m") or whole
+// seconds ("30").
+const (
+	EnvConfigFile            = "AXONFLOW_CONFIG_FILE"             // Config file read by NewClientFromEnv
+	EnvProfile               = "AXONFLOW_PROFILE"                 // Profile selected in the config file
+	EnvEndpoint              = "AXONFLOW_ENDPOINT"                // AxonFlowConfig.Endpoint
+	EnvEndpoints             = "AXONFLOW_ENDPOINTS"               // AxonFlowConfig.Endpoints, comma-separated
+	EnvClientID              = "AXONFLOW_CLIENT_ID"               // AxonFlowConfig.ClientID
+	EnvClientSecret          = "AXONFLOW_CLIENT_SECRET"           // AxonFlowConfig.ClientSecret
+	EnvAPIKey                = "AXONFLOW_API_KEY"                 // AxonFlowConfig.APIKey
+	EnvTokenURL              = "AXONFLOW_TOKEN_URL"               // AxonFlowConfig.TokenURL
+	EnvScopes                = "AXONFLOW_SCOPES"                  // AxonFlowConfig.Scopes, space- or comma-separated
+	EnvMode                  = "AXONFLOW_MODE"                    // AxonFlowConfig.Mode
+	EnvDebug                 = "AXONFLOW_DEBUG"                   // AxonFlowConfig.Debug
+	EnvLogContent            = "AXONFLOW_LOG_CONTENT"             // AxonFlowConfig.LogContent
+	EnvTimeout               = "AXONFLOW_TIMEOUT"                 // AxonFlowConfig.Timeout
+	EnvMapTimeout            = "AXONFLOW_MAP_TIMEOUT"             // AxonFlowConfig.MapTimeout
+	EnvMaxPendingAudits      = "AXONFLOW_MAX_PENDING_AUDITS"      // AxonFlowConfig.MaxPendingAudits
+	EnvBatchConcurrency      = "AXONFLOW_BATCH_CONCURRENCY"       // AxonFlowConfig.BatchConcurrency
+	EnvNegotiateCapabilities = "AXONFLOW_NEGOTIATE_CAPABILITIES"  // AxonFlowConfig.NegotiateCapabilities
+	EnvSingleFlight          = "AXONFLOW_SINGLE_FLIGHT"           // AxonFlowConfig.SingleFlight
+	EnvRetryEnabled          = "AXONFLOW_RETRY_ENABLED"           // RetryConfig.Enabled
+	EnvRetryMaxAttempts      = "AXONFLOW_RETRY_MAX_ATTEMPTS"      // RetryConfig.MaxAttempts
+	EnvRetryInitialDelay     = "AXONFLOW_RETRY_INITIAL_DELAY"     // RetryConfig.InitialDelay
+	EnvRetryMaxDelay         = "AXONFLOW_RETRY_MAX_DELAY"         // RetryConfig.MaxDelay
+	EnvCacheEnabled          = "AXONFLOW_CACHE_ENABLED"           // CacheConfig.Enabled
+	EnvCacheTTL              = "AXONFLOW_CACHE_TTL"               // CacheConfig.TTL
+	EnvCacheMaxEntries       = "AXONFLOW_CACHE_MAX_ENTRIES"       // CacheConfig.MaxEntries
+	EnvCircuitBreakerEnabled = "AXONFLOW_CIRCUIT_BREAKER_ENABLED" // CircuitBreakerConfig.Enabled
+	EnvFailureMode           = "AXONFLOW_FAILURE_MODE"            // FailurePolicy.Default
+	EnvFailoverStrategy      = "AXONFLOW_FAILOVER_STRATEGY"       // FailoverConfig.Strategy
+	EnvRateLimitEnabled      = "AXONFLOW_RATE_LIMIT_ENABLED"      // RateLimitConfig.Enabled
+	EnvRateLimitQueue        = "AXONFLOW_RATE_LIMIT_QUEUE"        // RateLimitConfig.Queue
+	EnvTLSCAFile             = "AXONFLOW_TLS_CA_FILE"             // TLSConfig.RootCAFile
+	EnvTLSCertFile           = "AXONFLOW_TLS_CERT_FILE"           // TLSConfig.CertFile
+	EnvTLSKeyFile            = "AXONFLOW_TLS_KEY_FILE"            // TLSConfig.KeyFile
+	EnvTLSServerName         = "AXONFLOW_TLS_SERVER_NAME"         // TLSConfig.ServerName
+	EnvTLSMinVersion         = "AXONFLOW_TLS_MIN_VERSION"         // TLSConfig.MinVersion ("1.2" or "1.3")
+	EnvTLSPinnedSPKI         = "AXONFLOW_TLS_PINNED_SPKI"         // TLSConfig.PinnedSPKI, comma-separated
+)
+
+// maskedSecret replaces secrets in DumpConfig output.
+const maskedSecret = "********"
+
+// NewClientFromEnv creates a client configured from the environment.
+//
+// Settings are applied in order of increasing precedence:
+//
+//  1. built-in defaults
+//  2. the JSON config file named by AXONFLOW_CONFIG_FILE, if set: top-level settings,
+//     then the profile named by AXONFLOW_PROFILE (or the file's "default_profile");
+//     AXONFLOW_PROFILE without AXONFLOW_CONFIG_FILE is an error
+//  3. AXONFLOW_* environment variables
+//  4. opts
+//
+// The result is validated like New.
+func NewClientFromEnv(opts ...Option) (*AxonFlowClient, error) {
+	config, err := loadConfig(os.Getenv(EnvConfigFile), os.Getenv(EnvProfile))
+	if err != nil {
+		return nil, err
+	}
+	return newValidatedClient(config, opts)
+}
+
+// LoadConfig reads the JSON config file at path, selects the profile named by
+// AXONFLOW_PROFILE (or the file's "default_profile"), and applies AXONFLOW_*
+// environment variables on top. Defaults are filled in, so the result can be passed
+// to NewClient or inspected with DumpConfig. It is not validated; see Validate.
+//
+// A config file looks like:
+//
+//	{
+//	  "client_id": "my-service",
+//	  "default_profile": "dev",
+//	  "profiles": {
+//	    "dev":     {"endpoint": "http://localhost:8080", "mode": "sandbox"},
+//	    "staging": {"endpoint": "https://staging.example.com", "timeout": "30s"},
+//	    "prod":    {"endpoint": "https://agent.example.com", "retry": {"max_attempts": 5}}
+//	  }
+//	}
+//
+// Unknown keys are rejected so that typos do not go unnoticed. path is required; use
+// NewClientFromEnv for a config taken from the environment alone.
+func LoadConfig(path string) (AxonFlowConfig, error) {
+	return LoadConfigProfile(path, os.Getenv(EnvProfile))
+}
+
+// LoadConfigProfile is like LoadConfig but selects profile explicitly.
+func LoadConfigProfile(path, profile string) (AxonFlowConfig, error) {
+	if path == "" {
+		return AxonFlowConfig{}, fmt.Errorf("axonflow: config file path is required")
+	}
+	return loadConfig(path, profile)
+}
+
+// loadConfig layers defaults, the config file (if path is set) and the environment.
+func loadConfig(path, profile string) (AxonFlowConfig, error) {
+	config := defaultConfig()
+
+	if path == "" && profile != "" {
+		return AxonFlowConfig{}, fmt.Errorf("axonflow: %s is set but no config file is given", EnvProfile)
+	}
+	if path != "" {
+		if err := applyConfigFile(&config, path, profile); err != nil {
+			return AxonFlowConfig{}, err
+		}
+	}
+
+	env, err := envOverlay()
+	if err != nil {
+		return AxonFlowConfig{}, err
+	}
+	env.apply(&config)
+
+	config.setDefaults()
+	return config, nil
+}
+
+// applyConfigFile applies the top-level settings and the selected profile of a file.
+func applyConfigFile(config *AxonFlowConfig, path, profile string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("axonflow: failed to read config file: %w", err)
+	}
+
+	var file configFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("axonflow: failed to parse config file %s: %w", path, err)
+	}
+
+	file.configOverlay.apply(config)
+
+	if profile == "" {
+		profile = file.DefaultProfile
+	}
+	if profile == "" {
+		return nil
+	}
+	p, ok := file.Profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(file.Profiles))
+		for name := range file.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("axonflow: profile %q not found in config file %s (available: %s)",
+			profile, path, strings.Join(names, ", "))
+	}
+	p.apply(config)
+	return nil
+}
+
+// DumpConfig returns the client's effective configuration as indented JSON in config
+// file format, with the client secret and API key masked. Settings that cannot be expressed in a
+// config file (HTTP client, transport, middleware, logger, observer, cache backend,
+// retry policy) are omitted.
+func (c *AxonFlowClient) DumpConfig() string {
+	return c.config.Dump()
+}
+
+// Dump is like AxonFlowClient.DumpConfig for a configuration that has not been used to
+// create a client yet. Unset values are shown as zero, not as their defaults.
+func (config AxonFlowConfig) Dump() string {
+	endpoint := redactURL(config.Endpoint)
+	var endpoints []string
+	for _, e := range config.Endpoints {
+		endpoints = append(endpoints, redactURL(e))
+	}
+	secret := config.ClientSecret
+	if secret != "" {
+		secret = maskedSecret
+	}
+	apiKey := config.APIKey
+	if apiKey != "" {
+		apiKey = maskedSecret
+	}
+
+	o := configOverlay{
+		Endpoint:              &endpoint,
+		Endpoints:             endpoints,
+		ClientID:              &config.ClientID,
+		ClientSecret:          &secret,
+		APIKey:                &apiKey,
+		TokenURL:              &config.TokenURL,
+		Scopes:                config.Scopes,
+		Mode:                  &config.Mode,
+		Debug:                 &config.Debug,
+		LogContent:            &config.LogContent,
+		Timeout:               durationPtr(config.Timeout),
+		MapTimeout:            durationPtr(config.MapTimeout),
+		MaxPendingAudits:      &config.MaxPendingAudits,
+		BatchConcurrency:      &config.BatchConcurrency,
+		NegotiateCapabilities: &config.NegotiateCapabilities,
+		SingleFlight:          &config.SingleFlight,
+		Retry: &retryOverlay{
+			Enabled:      &config.Retry.Enabled,
+			MaxAttempts:  &config.Retry.MaxAttempts,
+			InitialDelay: durationPtr(config.Retry.InitialDelay),
+			MaxDelay:     durationPtr(config.Retry.MaxDelay),
+		},
+		Cache: &cacheOverlay{
+			Enabled:    &config.Cache.Enabled,
+			TTL:        durationPtr(config.Cache.TTL),
+			MaxEntries: &config.Cache.MaxEntries,
+			MaxBytes:   &config.Cache.MaxBytes,
+		},
+		CircuitBreaker: &breakerOverlay{
+			Enabled:             &config.CircuitBreaker.Enabled,
+			FailureThreshold:    &config.CircuitBreaker.FailureThreshold,
+			CoolDown:            durationPtr(config.CircuitBreaker.CoolDown),
+			HalfOpenMaxRequests: &config.CircuitBreaker.HalfOpenMaxRequests,
+			SuccessThreshold:    &config.CircuitBreaker.SuccessThreshold,
+		},
+		FailurePolicy: &failurePolicyOverlay{
+			Default:           modePtr(config.FailurePolicy.Default),
+			LLMChat:           modePtr(config.FailurePolicy.LLMChat),
+			SQL:               modePtr(config.FailurePolicy.SQL),
+			MCPQuery:          modePtr(config.FailurePolicy.MCPQuery),
+			PreCheck:          modePtr(config.FailurePolicy.PreCheck),
+			Audit:             modePtr(config.FailurePolicy.Audit),
+			CachedDecisions:   &config.FailurePolicy.CachedDecisions,
+			CachedDecisionTTL: durationPtr(config.FailurePolicy.CachedDecisionTTL),
+		},
+		RateLimit: &rateLimitOverlay{
+			Enabled: &config.RateLimit.Enabled,
+			Queue:   &config.RateLimit.Queue,
+			MaxWait: durationPtr(config.RateLimit.MaxWait),
+		},
+	}
+	if len(config.endpoints()) > 1 {
+		o.Failover = &failoverOverlay{
+			Strategy:          (*string)(&config.Failover.Strategy),
+			ProbeInterval:     durationPtr(config.Failover.ProbeInterval),
+			ProbeTimeout:      durationPtr(config.Failover.ProbeTimeout),
+			FailureThreshold:  &config.Failover.FailureThreshold,
+			RecoveryThreshold: &config.Failover.RecoveryThreshold,
+			ReadmitAfter:      durationPtr(config.Failover.ReadmitAfter),
+		}
+	}
+
+	if t := config.TLS; t != nil {
+		o.TLS = &tlsOverlay{
+			CAFile:             &t.RootCAFile,
+			CertFile:           &t.CertFile,
+			KeyFile:            &t.KeyFile,
+			ServerName:         &t.ServerName,
+			PinnedSPKI:         t.PinnedSPKI,
+			InsecureSkipVerify: &t.InsecureSkipVerify,
+		}
+		if t.MinVersion != 0 {
+			v := tlsVersion(t.MinVersion)
+			o.TLS.MinVersion = &v
+		}
+	}
+
+	out, _ := json.MarshalIndent(o, "", "  ")
+	return string(out)
+}
+
+// redactURL hides the password of a URL with user info.
+func redactURL(s string) string {
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return s
+}
+
+// configFile is the JSON config file format: top-level settings shared by all
+// profiles, plus named profiles that override them.
+type configFile struct {
+	configOverlay
+	DefaultProfile string                   `json:"default_profile,omitempty"`
+	Profiles       map[string]configOverlay `json:"profiles,omitempty"`
+}
+
+// configOverlay is a partial configuration; nil fields leave the setting unchanged.
+type configOverlay struct {
+	Endpoint              *string               `json:"endpoint,omitempty"`
+	Endpoints             []string              `json:"endpoints,omitempty"`
+	ClientID              *string               `json:"client_id,omitempty"`
+	ClientSecret          *string               `json:"client_secret,omitempty"`
+	APIKey                *string               `json:"api_key,omitempty"`
+	TokenURL              *string               `json:"token_url,omitempty"`
+	Scopes                []string              `json:"scopes,omitempty"`
+	Mode                  *string               `json:"mode,omitempty"`
+	Debug                 *bool                 `json:"debug,omitempty"`
+	LogContent            *bool                 `json:"log_content,omitempty"`
+	Timeout               *configDuration       `json:"timeout,omitempty"`
+	MapTimeout            *configDuration       `json:"map_timeout,omitempty"`
+	MaxPendingAudits      *int                  `json:"max_pending_audits,omitempty"`
+	BatchConcurrency      *int                  `json:"batch_concurrency,omitempty"`
+	NegotiateCapabilities *bool                 `json:"negotiate_capabilities,omitempty"`
+	SingleFlight          *bool                 `json:"single_flight,omitempty"`
+	Retry                 *retryOverlay         `json:"retry,omitempty"`
+	Cache                 *cacheOverlay         `json:"cache,omitempty"`
+	CircuitBreaker        *breakerOverlay       `json:"circuit_breaker,omitempty"`
+	FailurePolicy         *failurePolicyOverlay `json:"failure_policy,omitempty"`
+	TLS                   *tlsOverlay           `json:"tls,omitempty"`
+	Failover              *failoverOverlay      `json:"failover,omitempty"`
+	RateLimit             *rateLimitOverlay     `json:"rate_limit,omitempty"`
+}
+
+type retryOverlay struct {
+	Enabled      *bool           `json:"enabled,omitempty"`
+	MaxAttempts  *int            `json:"max_attempts,omitempty"`
+	InitialDelay *configDuration `json:"initial_delay,omitempty"`
+	MaxDelay     *configDuration `json:"max_delay,omitempty"`
+}
+
+type cacheOverlay struct {
+	Enabled    *bool           `json:"enabled,omitempty"`
+	TTL        *configDuration `json:"ttl,omitempty"`
+	MaxEntries *int            `json:"max_entries,omitempty"`
+	MaxBytes   *int64          `json:"max_bytes,omitempty"`
+}
+
+type breakerOverlay struct {
+	Enabled             *bool           `json:"enabled,omitempty"`
+	FailureThreshold    *int            `json:"failure_threshold,omitempty"`
+	CoolDown            *configDuration `json:"cool_down,omitempty"`
+	HalfOpenMaxRequests *int            `json:"half_open_max_requests,omitempty"`
+	SuccessThreshold    *int            `json:"success_threshold,omitempty"`
+}
+
+type failurePolicyOverlay struct {
+	Default           *FailureMode    `json:"default,omitempty"`
+	LLMChat           *FailureMode    `json:"llm_chat,omitempty"`
+	SQL               *FailureMode    `json:"sql,omitempty"`
+	MCPQuery          *FailureMode    `json:"mcp_query,omitempty"`
+	PreCheck          *FailureMode    `json:"pre_check,omitempty"`
+	Audit             *FailureMode    `json:"audit,omitempty"`
+	CachedDecisions   *int            `json:"cached_decisions,omitempty"`
+	CachedDecisionTTL *configDuration `json:"cached_decision_ttl,omitempty"`
+}
+
+type failoverOverlay struct {
+	Strategy          *string         `json:"strategy,omitempty"`
+	ProbeInterval     *configDuration `json:"probe_interval,omitempty"`
+	ProbeTimeout      *configDuration `json:"probe_timeout,omitempty"`
+	FailureThreshold  *int            `json:"failure_threshold,omitempty"`
+	RecoveryThreshold *int            `json:"recovery_threshold,omitempty"`
+	ReadmitAfter      *configDuration `json:"readmit_after,omitempty"`
+}
+
+type rateLimitOverlay struct {
+	Enabled *bool           `json:"enabled,omitempty"`
+	Queue   *bool           `json:"queue,omitempty"`
+	MaxWait *configDuration `json:"max_wait,omitempty"`
+}
+
+type tlsOverlay struct {
+	CAFile             *string     `json:"ca_file,omitempty"`
+	CertFile           *string     `json:"cert_file,omitempty"`
+	KeyFile            *string     `json:"key_file,omitempty"`
+	ServerName         *string     `json:"server_name,omitempty"`
+	MinVersion         *tlsVersion `json:"min_version,omitempty"`
+	PinnedSPKI         []string    `json:"pinned_spki,omitempty"`
+	InsecureSkipVerify *bool       `json:"insecure_skip_verify,omitempty"`
+}
+
+// isSet reports whether any TLS setting is present.
+func (t *tlsOverlay) isSet() bool {
+	return t != nil && (t.CAFile != nil || t.CertFile != nil || t.KeyFile != nil || t.ServerName != nil ||
+		t.MinVersion != nil || t.PinnedSPKI != nil || t.InsecureSkipVerify != nil)
+}
+
+// apply copies the set fields of o into config.
+func (o *configOverlay) apply(config *AxonFlowConfig) {
+	setString(&config.Endpoint, o.Endpoint)
+	if o.Endpoints != nil {
+		config.Endpoints = o.Endpoints
+	}
+	setString(&config.ClientID, o.ClientID)
+	setString(&config.ClientSecret, o.ClientSecret)
+	setString(&config.APIKey, o.APIKey)
+	setString(&config.TokenURL, o.TokenURL)
+	if o.Scopes != nil {
+		config.Scopes = o.Scopes
+	}
+	setString(&config.Mode, o.Mode)
+	setBool(&config.Debug, o.Debug)
+	setBool(&config.LogContent, o.LogContent)
+	setDuration(&config.Timeout, o.Timeout)
+	setDuration(&config.MapTimeout, o.MapTimeout)
+	setInt(&config.MaxPendingAudits, o.MaxPendingAudits)
+	setInt(&config.BatchConcurrency, o.BatchConcurrency)
+	setBool(&config.NegotiateCapabilities, o.NegotiateCapabilities)
+	setBool(&config.SingleFlight, o.SingleFlight)
+
+	if r := o.Retry; r != nil {
+		setBool(&config.Retry.Enabled, r.Enabled)
+		setInt(&config.Retry.MaxAttempts, r.MaxAttempts)
+		setDuration(&config.Retry.InitialDelay, r.InitialDelay)
+		setDuration(&config.Retry.MaxDelay, r.MaxDelay)
+	}
+	if c := o.Cache; c != nil {
+		setBool(&config.Cache.Enabled, c.Enabled)
+		setDuration(&config.Cache.TTL, c.TTL)
+		setInt(&config.Cache.MaxEntries, c.MaxEntries)
+		if c.MaxBytes != nil {
+			config.Cache.MaxBytes = *c.MaxBytes
+		}
+	}
+	if b := o.CircuitBreaker; b != nil {
+		setBool(&config.CircuitBreaker.Enabled, b.Enabled)
+		setInt(&config.CircuitBreaker.FailureThreshold, b.FailureThreshold)
+		setDuration(&config.CircuitBreaker.CoolDown, b.CoolDown)
+		setInt(&config.CircuitBreaker.HalfOpenMaxRequests, b.HalfOpenMaxRequests)
+		setInt(&config.CircuitBreaker.SuccessThreshold, b.SuccessThreshold)
+	}
+	if f := o.FailurePolicy; f != nil {
+		setMode(&config.FailurePolicy.Default, f.Default)
+		setMode(&config.FailurePolicy.LLMChat, f.LLMChat)
+		setMode(&config.FailurePolicy.SQL, f.SQL)
+		setMode(&config.FailurePolicy.MCPQuery, f.MCPQuery)
+		setMode(&config.FailurePolicy.PreCheck, f.PreCheck)
+		setMode(&config.FailurePolicy.Audit, f.Audit)
+		setInt(&config.FailurePolicy.CachedDecisions, f.CachedDecisions)
+		setDuration(&config.FailurePolicy.CachedDecisionTTL, f.CachedDecisionTTL)
+	}
+	if f := o.Failover; f != nil {
+		if f.Strategy != nil {
+			config.Failover.Strategy = EndpointStrategy(*f.Strategy)
+		}
+		setDuration(&config.Failover.ProbeInterval, f.ProbeInterval)
+		setDuration(&config.Failover.ProbeTimeout, f.ProbeTimeout)
+		setInt(&config.Failover.FailureThreshold, f.FailureThreshold)
+		setInt(&config.Failover.RecoveryThreshold, f.RecoveryThreshold)
+		setDuration(&config.Failover.ReadmitAfter, f.ReadmitAfter)
+	}
+	if r := o.RateLimit; r != nil {
+		setBool(&config.RateLimit.Enabled, r.Enabled)
+		setBool(&config.RateLimit.Queue, r.Queue)
+		setDuration(&config.RateLimit.MaxWait, r.MaxWait)
+	}
+	if t := o.TLS; t.isSet() {
+		// Copy so that a TLSConfig shared with the caller is left untouched
+		tlsConfig := TLSConfig{}
+		if config.TLS != nil {
+			tlsConfig = *config.TLS
+		}
+		setString(&tlsConfig.RootCAFile, t.CAFile)
+		setString(&tlsConfig.CertFile, t.CertFile)
+		setString(&tlsConfig.KeyFile, t.KeyFile)
+		setString(&tlsConfig.ServerName, t.ServerName)
+		if t.MinVersion != nil {
+			tlsConfig.MinVersion = uint16(*t.MinVersion)
+		}
+		if t.PinnedSPKI != nil {
+			tlsConfig.PinnedSPKI = t.PinnedSPKI
+		}
+		setBool(&tlsConfig.InsecureSkipVerify, t.InsecureSkipVerify)
+		config.TLS = &tlsConfig
+	}
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setBool(dst *bool, src *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setInt(dst *int, src *int) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setDuration(dst *time.Duration, src *configDuration) {
+	if src != nil {
+		*dst = time.Duration(*src)
+	}
+}
+
+func setMode(dst *FailureMode, src *FailureMode) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func durationPtr(d time.Duration) *configDuration {
+	cd := configDuration(d)
+	return &cd
+}
+
+func modePtr(m FailureMode) *FailureMode {
+	if m == "" {
+		return nil
+	}
+	return &m
+}
+
+// envOverlay reads the AXONFLOW_* environment variables. Malformed values are
+// reported as *ValidationError naming the variable.
+func envOverlay() (*configOverlay, error) {
+	var errs []error
+	lookup := func(name string) (string, bool) {
+		v := os.Getenv(name)
+		return v, v != ""
+	}
+	invalid := func(name, value, want string) {
+		errs = append(errs, newValidationError(name, fmt.Sprintf("invalid config: %s must be %s, got %q", name, want, value)))
+	}
+
+	str := func(name string) *string {
+		if v, ok := lookup(name); ok {
+			return &v
+		}
+		return nil
+	}
+	boolean := func(name string) *bool {
+		v, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid(name, v, "a boolean")
+			return nil
+		}
+		return &b
+	}
+	integer := func(name string) *int {
+		v, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			invalid(name, v, "an integer")
+			return nil
+		}
+		return &n
+	}
+	duration := func(name string) *configDuration {
+		v, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		d, err := parseConfigDuration(v)
+		if err != nil {
+			invalid(name, v, "a duration")
+			return nil
+		}
+		return durationPtr(d)
+	}
+
+	o := &configOverlay{
+		Endpoint:              str(EnvEndpoint),
+		ClientID:              str(EnvClientID),
+		ClientSecret:          str(EnvClientSecret),
+		APIKey:                str(EnvAPIKey),
+		TokenURL:              str(EnvTokenURL),
+		Mode:                  str(EnvMode),
+		Debug:                 boolean(EnvDebug),
+		LogContent:            boolean(EnvLogContent),
+		Timeout:               duration(EnvTimeout),
+		MapTimeout:            duration(EnvMapTimeout),
+		MaxPendingAudits:      integer(EnvMaxPendingAudits),
+		BatchConcurrency:      integer(EnvBatchConcurrency),
+		NegotiateCapabilities: boolean(EnvNegotiateCapabilities),
+		SingleFlight:          boolean(EnvSingleFlight),
+		Retry: &retryOverlay{
+			Enabled:      boolean(EnvRetryEnabled),
+			MaxAttempts:  integer(EnvRetryMaxAttempts),
+			InitialDelay: duration(EnvRetryInitialDelay),
+			MaxDelay:     duration(EnvRetryMaxDelay),
+		},
+		Cache: &cacheOverlay{
+			Enabled:    boolean(EnvCacheEnabled),
+			TTL:        duration(EnvCacheTTL),
+			MaxEntries: integer(EnvCacheMaxEntries),
+		},
+		CircuitBreaker: &breakerOverlay{
+			Enabled: boolean(EnvCircuitBreakerEnabled),
+		},
+		RateLimit: &rateLimitOverlay{
+			Enabled: boolean(EnvRateLimitEnabled),
+			Queue:   boolean(EnvRateLimitQueue),
+		},
+	}
+	o.TLS = &tlsOverlay{
+		CAFile:     str(EnvTLSCAFile),
+		CertFile:   str(EnvTLSCertFile),
+		KeyFile:    str(EnvTLSKeyFile),
+		ServerName: str(EnvTLSServerName),
+	}
+	if v, ok := lookup(EnvTLSMinVersion); ok {
+		if version, err := parseTLSVersion(v); err != nil {
+			invalid(EnvTLSMinVersion, v, `"1.2" or "1.3"`)
+		} else {
+			o.TLS.MinVersion = (*tlsVersion)(&version)
+		}
+	}
+	if v, ok := lookup(EnvScopes); ok {
+		o.Scopes = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if v, ok := lookup(EnvTLSPinnedSPKI); ok {
+		for _, pin := range strings.Split(v, ",") {
+			if pin = strings.TrimSpace(pin); pin != "" {
+				o.TLS.PinnedSPKI = append(o.TLS.PinnedSPKI, pin)
+			}
+		}
+	}
+	if v, ok := lookup(EnvEndpoints); ok {
+		for _, endpoint := range strings.Split(v, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				o.Endpoints = append(o.Endpoints, endpoint)
+			}
+		}
+	}
+	if strategy := str(EnvFailoverStrategy); strategy != nil {
+		o.Failover = &failoverOverlay{Strategy: strategy}
+	}
+	if mode := str(EnvFailureMode); mode != nil {
+		m := FailureMode(*mode)
+		o.FailurePolicy = &failurePolicyOverlay{Default: &m}
+	}
+
+	if err := joinErrors(errs); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// configDuration is a duration in a config file: a Go duration string ("30s") or a
+// number of seconds.
+type configDuration time.Duration
+
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		parsed, err := parseConfigDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*d = configDuration(parsed)
+		return nil
+	case float64:
+		*d = configDuration(v * float64(time.Second))
+		return nil
+	}
+	return fmt.Errorf("invalid duration %s", data)
+}
+
+// parseConfigDuration parses a Go duration string or a whole number of seconds.
+func parseConfigDuration(s string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(s); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// tlsVersion is a TLS version in a config file: "1.2" or "1.3".
+type tlsVersion uint16
+
+func (v tlsVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tlsVersionName(uint16(v)))
+}
+
+func (v *tlsVersion) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid TLS version %s", data)
+	}
+	version, err := parseTLSVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = tlsVersion(version)
+	return nil
+}
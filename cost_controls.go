@@ -4,7 +4,6 @@ package axonflow
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/url"
 )
 
@@ -212,9 +211,7 @@ type PricingListResponse struct {
 
 // CreateBudget creates a new budget
 func (c *AxonFlowClient) CreateBudget(ctx context.Context, req CreateBudgetRequest) (*Budget, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Creating budget: %s", req.ID)
-	}
+	c.debugf("Creating budget: %s", req.ID)
 
 	var budget Budget
 	if err := c.costRequest(ctx, "POST", "/api/v1/budgets", req, &budget); err != nil {
@@ -226,9 +223,7 @@ func (c *AxonFlowClient) CreateBudget(ctx context.Context, req CreateBudgetReque
 
 // GetBudget retrieves a budget by ID
 func (c *AxonFlowClient) GetBudget(ctx context.Context, id string) (*Budget, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting budget: %s", id)
-	}
+	c.debugf("Getting budget: %s", id)
 
 	var budget Budget
 	if err := c.costRequest(ctx, "GET", "/api/v1/budgets/"+id, nil, &budget); err != nil {
@@ -242,9 +237,7 @@ func (c *AxonFlowClient) GetBudget(ctx context.Context, id string) (*Budget, err
 func (c *AxonFlowClient) ListBudgets(ctx context.Context, options ListBudgetsOptions) (*BudgetsResponse, error) {
 	path := "/api/v1/budgets" + options.buildQueryParams()
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing budgets: %s", path)
-	}
+	c.debugf("Listing budgets: %s", path)
 
 	var response BudgetsResponse
 	if err := c.costRequest(ctx, "GET", path, nil, &response); err != nil {
@@ -256,9 +249,7 @@ func (c *AxonFlowClient) ListBudgets(ctx context.Context, options ListBudgetsOpt
 
 // UpdateBudget updates an existing budget
 func (c *AxonFlowClient) UpdateBudget(ctx context.Context, budget *Budget) (*Budget, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Updating budget: %s", budget.ID)
-	}
+	c.debugf("Updating budget: %s", budget.ID)
 
 	// Convert Budget to update request format
 	updateReq := map[string]interface{}{
@@ -278,9 +269,7 @@ func (c *AxonFlowClient) UpdateBudget(ctx context.Context, budget *Budget) (*Bud
 
 // DeleteBudget deletes a budget by ID
 func (c *AxonFlowClient) DeleteBudget(ctx context.Context, id string) error {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleting budget: %s", id)
-	}
+	c.debugf("Deleting budget: %s", id)
 
 	return c.costRequest(ctx, "DELETE", "/api/v1/budgets/"+id, nil, nil)
 }
@@ -291,9 +280,7 @@ func (c *AxonFlowClient) DeleteBudget(ctx context.Context, id string) error {
 
 // GetBudgetStatus retrieves the current status of a budget
 func (c *AxonFlowClient) GetBudgetStatus(ctx context.Context, id string) (*BudgetStatus, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting budget status: %s", id)
-	}
+	c.debugf("Getting budget status: %s", id)
 
 	var status BudgetStatus
 	if err := c.costRequest(ctx, "GET", "/api/v1/budgets/"+id+"/status", nil, &status); err != nil {
@@ -310,9 +297,7 @@ func (c *AxonFlowClient) GetBudgetAlerts(ctx context.Context, id string, limit i
 		path += fmt.Sprintf("?limit=%d", limit)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting budget alerts: %s", path)
-	}
+	c.debugf("Getting budget alerts: %s", path)
 
 	var response BudgetAlertsResponse
 	if err := c.costRequest(ctx, "GET", path, nil, &response); err != nil {
@@ -329,12 +314,16 @@ func (c *AxonFlowClient) GetBudgetAlerts(ctx context.Context, id string, limit i
 
 // CheckBudget performs a pre-flight budget check
 func (c *AxonFlowClient) CheckBudget(ctx context.Context, req CheckBudgetRequest) (*BudgetDecision, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Checking budget")
+	c.debugf("Checking budget")
+
+	httpReq, err := newJSONRequest("POST", c.config.Endpoint+"/api/v1/budgets/check", req)
+	if err != nil {
+		return nil, err
 	}
+	httpReq.retrySafe = true // a budget check does not consume budget
 
 	var decision BudgetDecision
-	if err := c.costRequest(ctx, "POST", "/api/v1/budgets/check", req, &decision); err != nil {
+	if err := c.sendJSON(ctx, httpReq, &decision); err != nil {
 		return nil, err
 	}
 
@@ -349,9 +338,7 @@ func (c *AxonFlowClient) CheckBudget(ctx context.Context, req CheckBudgetRequest
 func (c *AxonFlowClient) GetUsageSummary(ctx context.Context, options UsageQueryOptions) (*UsageSummary, error) {
 	path := "/api/v1/usage" + options.buildQueryParamsForSummary()
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting usage summary: %s", path)
-	}
+	c.debugf("Getting usage summary: %s", path)
 
 	var summary UsageSummary
 	if err := c.costRequest(ctx, "GET", path, nil, &summary); err != nil {
@@ -365,9 +352,7 @@ func (c *AxonFlowClient) GetUsageSummary(ctx context.Context, options UsageQuery
 func (c *AxonFlowClient) GetUsageBreakdown(ctx context.Context, groupBy string, options UsageQueryOptions) (*UsageBreakdown, error) {
 	path := "/api/v1/usage/breakdown" + options.buildQueryParamsForBreakdown(groupBy)
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting usage breakdown: %s", path)
-	}
+	c.debugf("Getting usage breakdown: %s", path)
 
 	var breakdown UsageBreakdown
 	if err := c.costRequest(ctx, "GET", path, nil, &breakdown); err != nil {
@@ -386,9 +371,7 @@ func (c *AxonFlowClient) GetUsageBreakdown(ctx context.Context, groupBy string,
 func (c *AxonFlowClient) ListUsageRecords(ctx context.Context, options UsageQueryOptions) (*UsageRecordsResponse, error) {
 	path := "/api/v1/usage/records" + options.buildQueryParamsForRecords()
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing usage records: %s", path)
-	}
+	c.debugf("Listing usage records: %s", path)
 
 	var response UsageRecordsResponse
 	if err := c.costRequest(ctx, "GET", path, nil, &response); err != nil {
@@ -422,9 +405,7 @@ func (c *AxonFlowClient) GetPricing(ctx context.Context, provider, model string)
 		path += "?" + encoded
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting pricing: %s", path)
-	}
+	c.debugf("Getting pricing: %s", path)
 
 	// API may return single object or array
 	var pricing PricingInfo
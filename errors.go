@@ -0,0 +1,386 @@
+// Error types returned by the AxonFlow SDK
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Sentinel Errors
+// ============================================================================
+
+// Sentinel errors for use with errors.Is. Every typed error below matches exactly
+// one of these, so callers that only care about the category do not need errors.As.
+var (
+	// ErrPolicyBlocked matches *PolicyBlockedError
+	ErrPolicyBlocked = errors.New("axonflow: request blocked by policy")
+	// ErrRateLimited matches *RateLimitedError
+	ErrRateLimited = errors.New("axonflow: rate limited")
+	// ErrUnauthorized matches *AuthError
+	ErrUnauthorized = errors.New("axonflow: unauthorized")
+	// ErrNotFound matches *NotFoundError
+	ErrNotFound = errors.New("axonflow: not found")
+	// ErrValidation matches *ValidationError
+	ErrValidation = errors.New("axonflow: validation failed")
+)
+
+// ============================================================================
+// APIError
+// ============================================================================
+
+// APIError represents an error response from the AxonFlow API.
+//
+// All typed errors that originate from an HTTP response (PolicyBlockedError,
+// RateLimitedError, AuthError, NotFoundError, ValidationError) wrap an *APIError,
+// so errors.As(err, &apiErr) works for every one of them.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Code is the machine-readable error code from the response body (if any)
+	Code string
+	// Message is the human-readable error message. It is taken from the "error" or
+	// "message" field of a JSON body, or is the raw body otherwise.
+	Message string
+	// RequestID is the server request ID (X-Request-ID header or "request_id" field)
+	RequestID string
+	// Body is the raw response body
+	Body []byte
+	// Details is the parsed JSON response body (nil if the body is not a JSON object)
+	Details map[string]interface{}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the error is likely transient (5xx, 408 or 429).
+func (e *APIError) Temporary() bool {
+	return e.StatusCode >= 500 ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests
+}
+
+// ============================================================================
+// Typed Errors
+// ============================================================================
+
+// PolicyBlockedError is returned when a request is rejected by an AxonFlow policy.
+type PolicyBlockedError struct {
+	*APIError
+	// BlockReason is the reason given by the policy engine
+	BlockReason string
+	// Policies lists the policies that caused the block (if reported)
+	Policies []string
+}
+
+func (e *PolicyBlockedError) Error() string {
+	return "request blocked by policy: " + e.BlockReason
+}
+
+// Unwrap returns the underlying *APIError (nil for blocks raised without a response).
+func (e *PolicyBlockedError) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// Is reports whether target is ErrPolicyBlocked.
+func (e *PolicyBlockedError) Is(target error) bool { return target == ErrPolicyBlocked }
+
+// RateLimitedError is returned for HTTP 429 responses.
+type RateLimitedError struct {
+	*APIError
+	// RetryAfter is how long the server asked the client to wait (0 if not specified)
+	RetryAfter time.Duration
+	// Limit is the request quota for the current window (0 if not reported)
+	Limit int
+	// Remaining is the remaining quota for the current window
+	Remaining int
+	// ResetAt is when the current window resets (zero if not reported)
+	ResetAt time.Time
+	// Local is set when the client rejected the call itself because the quota
+	// AxonFlow reported was used up (see RateLimitConfig); the call was not sent.
+	Local bool
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *RateLimitedError) Unwrap() error { return e.APIError }
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitedError) Is(target error) bool { return target == ErrRateLimited }
+
+// AuthError is returned when the server rejects the client's credentials
+// (HTTP 401, or HTTP 403 that is not a policy block).
+type AuthError struct {
+	*APIError
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *AuthError) Unwrap() error { return e.APIError }
+
+// Is reports whether target is ErrUnauthorized.
+func (e *AuthError) Is(target error) bool { return target == ErrUnauthorized }
+
+// NotFoundError is returned for HTTP 404 responses.
+type NotFoundError struct {
+	*APIError
+	// Resource is the kind of resource that was looked up (e.g. "connector"), if known
+	Resource string
+	// ID is the identifier that was looked up, if known
+	ID string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Resource != "" {
+		return fmt.Sprintf("%s not found: %s", e.Resource, e.ID)
+	}
+	return e.APIError.Error()
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *NotFoundError) Unwrap() error { return e.APIError }
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// ValidationError is returned when a request is invalid. It is raised either by the
+// server (HTTP 400/422, APIError set) or by the SDK before sending (APIError nil).
+type ValidationError struct {
+	*APIError
+	// Field is the offending field, if known
+	Field string
+	// Reason describes the problem for client-side validation failures
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.APIError != nil {
+		return e.APIError.Error()
+	}
+	return e.Reason
+}
+
+// Unwrap returns the underlying *APIError (nil for client-side validation failures).
+func (e *ValidationError) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// newValidationError creates a client-side ValidationError.
+func newValidationError(field, reason string) *ValidationError {
+	return &ValidationError{Field: field, Reason: reason}
+}
+
+// ============================================================================
+// Response Mapping
+// ============================================================================
+
+// newAPIError builds the typed error that corresponds to a non-success HTTP response.
+func newAPIError(resp *http.Response, body []byte) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+		Body:       body,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	var details map[string]interface{}
+	if json.Unmarshal(body, &details) == nil {
+		apiErr.Details = details
+		apiErr.parseDetails()
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		field, _ := apiErr.Details["field"].(string)
+		return &ValidationError{APIError: apiErr, Field: field}
+	case http.StatusUnauthorized:
+		return &AuthError{APIError: apiErr}
+	case http.StatusForbidden:
+		if blocked := apiErr.policyBlock(); blocked != nil {
+			return blocked
+		}
+		return &AuthError{APIError: apiErr}
+	case http.StatusNotFound:
+		return &NotFoundError{APIError: apiErr}
+	case http.StatusTooManyRequests:
+		return apiErr.rateLimited(resp.Header)
+	}
+	return apiErr
+}
+
+// newNotFoundError builds a NotFoundError for a 404 response to a lookup of the
+// given resource kind and ID.
+func newNotFoundError(resp *http.Response, body []byte, resource, id string) *NotFoundError {
+	nf, ok := newAPIError(resp, body).(*NotFoundError)
+	if !ok {
+		nf = &NotFoundError{APIError: &APIError{StatusCode: resp.StatusCode, Message: string(body), Body: body}}
+	}
+	nf.Resource = resource
+	nf.ID = id
+	return nf
+}
+
+// parseDetails fills Code, Message and RequestID from a JSON error body.
+// Both {"error": "msg", "code": "X"} and {"error": {"code": "X", "message": "msg"}}
+// shapes are understood.
+func (e *APIError) parseDetails() {
+	d := e.Details
+	switch v := d["error"].(type) {
+	case string:
+		if v != "" {
+			e.Message = v
+		}
+	case map[string]interface{}:
+		if msg, ok := v["message"].(string); ok && msg != "" {
+			e.Message = msg
+		}
+		if code, ok := v["code"].(string); ok {
+			e.Code = code
+		}
+	}
+	if _, hasError := d["error"]; !hasError {
+		if msg, ok := d["message"].(string); ok && msg != "" {
+			e.Message = msg
+		}
+	}
+	if e.Code == "" {
+		if code, ok := d["code"].(string); ok {
+			e.Code = code
+		} else if code, ok := d["error_code"].(string); ok {
+			e.Code = code
+		}
+	}
+	if e.RequestID == "" {
+		if id, ok := d["request_id"].(string); ok {
+			e.RequestID = id
+		}
+	}
+}
+
+// policyBlock returns a PolicyBlockedError if the body of a 403 response describes a
+// policy decision (blocked flag, block reason, or blocked policy_info).
+func (e *APIError) policyBlock() *PolicyBlockedError {
+	d := e.Details
+	if d == nil {
+		return nil
+	}
+
+	reason, _ := d["block_reason"].(string)
+	blocked, _ := d["blocked"].(bool)
+	var policies []string
+
+	if info, ok := d["policy_info"].(map[string]interface{}); ok {
+		if b, ok := info["blocked"].(bool); ok && b {
+			blocked = true
+		}
+		if reason == "" {
+			reason, _ = info["block_reason"].(string)
+		}
+		if evaluated, ok := info["policies_evaluated"].([]interface{}); ok {
+			policies = toStringSlice(evaluated)
+		}
+	}
+	if p, ok := d["policies"].([]interface{}); ok {
+		policies = toStringSlice(p)
+	}
+
+	if !blocked && reason == "" {
+		return nil
+	}
+	if reason == "" {
+		reason = e.Message
+	}
+	return &PolicyBlockedError{APIError: e, BlockReason: reason, Policies: policies}
+}
+
+// rateLimited builds a RateLimitedError from a 429 response.
+func (e *APIError) rateLimited(header http.Header) *RateLimitedError {
+	rl := &RateLimitedError{APIError: e}
+	rl.RetryAfter = parseRetryAfter(header.Get("Retry-After"), time.Now())
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		rl.Limit = v
+	}
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		rl.Remaining = v
+	}
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.ResetAt = time.Unix(v, 0)
+	}
+	return rl
+}
+
+// parseRetryAfter parses a Retry-After header value given either as delay-seconds
+// or as an HTTP-date. It returns 0 if the value is empty or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// toStringSlice converts a decoded JSON array to a []string, skipping non-strings.
+func toStringSlice(values []interface{}) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// isUnavailableError reports whether err means AxonFlow could not render a decision:
+// the agent was unreachable, timed out, or failed server-side. Definitive answers
+// (4xx, policy blocks, rate limits) and caller cancellation are not outages.
+func isUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	// A certificate that fails verification may mean interception, not an outage
+	if isCertificateError(err) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
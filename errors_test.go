@@ -0,0 +1,322 @@
+package axonflow
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header}
+}
+
+func TestNewAPIErrorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name:     "400 validation",
+			status:   400,
+			body:     `{"error": "query is required", "field": "query"}`,
+			sentinel: ErrValidation,
+			check: func(t *testing.T, err error) {
+				var ve *ValidationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("expected *ValidationError, got %T", err)
+				}
+				if ve.Field != "query" {
+					t.Errorf("expected field 'query', got %q", ve.Field)
+				}
+			},
+		},
+		{
+			name:     "422 validation",
+			status:   422,
+			body:     `{"error": "invalid"}`,
+			sentinel: ErrValidation,
+		},
+		{
+			name:     "401 auth",
+			status:   401,
+			body:     `{"error": "invalid credentials"}`,
+			sentinel: ErrUnauthorized,
+		},
+		{
+			name:     "403 without policy decision is auth",
+			status:   403,
+			body:     `{"error": "tenant mismatch"}`,
+			sentinel: ErrUnauthorized,
+		},
+		{
+			name:     "403 with policy decision is policy block",
+			status:   403,
+			body:     `{"blocked": true, "block_reason": "PII detected", "policy_info": {"policies_evaluated": ["pii-detection"]}}`,
+			sentinel: ErrPolicyBlocked,
+			check: func(t *testing.T, err error) {
+				var pb *PolicyBlockedError
+				if !errors.As(err, &pb) {
+					t.Fatalf("expected *PolicyBlockedError, got %T", err)
+				}
+				if pb.BlockReason != "PII detected" {
+					t.Errorf("unexpected block reason %q", pb.BlockReason)
+				}
+				if len(pb.Policies) != 1 || pb.Policies[0] != "pii-detection" {
+					t.Errorf("unexpected policies %v", pb.Policies)
+				}
+				if err.Error() != "request blocked by policy: PII detected" {
+					t.Errorf("unexpected message %q", err.Error())
+				}
+			},
+		},
+		{
+			name:     "404 not found",
+			status:   404,
+			body:     `not found`,
+			sentinel: ErrNotFound,
+		},
+		{
+			name:     "429 rate limited",
+			status:   429,
+			body:     `{"error": "slow down"}`,
+			sentinel: ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(newTestResponse(tt.status, nil), []byte(tt.body))
+			wrapped := fmt.Errorf("request failed: %w", err)
+
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("expected errors.Is(%v) to match %v", err, tt.sentinel)
+			}
+
+			var apiErr *APIError
+			if !errors.As(wrapped, &apiErr) {
+				t.Fatalf("expected *APIError in chain of %T", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if string(apiErr.Body) != tt.body {
+				t.Errorf("expected raw body to be preserved, got %q", apiErr.Body)
+			}
+			if tt.check != nil {
+				tt.check(t, err)
+			}
+		})
+	}
+}
+
+func TestNewAPIErrorServerError(t *testing.T) {
+	err := newAPIError(newTestResponse(503, nil), []byte("upstream down"))
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected plain *APIError, got %T", err)
+	}
+	if err.Error() != "HTTP 503: upstream down" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !apiErr.Temporary() {
+		t.Error("503 should be temporary")
+	}
+	for _, sentinel := range []error{ErrPolicyBlocked, ErrRateLimited, ErrUnauthorized, ErrNotFound, ErrValidation} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("503 should not match %v", sentinel)
+		}
+	}
+}
+
+func TestAPIErrorParsesBody(t *testing.T) {
+	t.Run("flat body", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Request-ID", "req-header")
+		err := newAPIError(newTestResponse(500, header), []byte(`{"error": "boom", "code": "INTERNAL", "request_id": "req-body"}`))
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
+		}
+		if apiErr.Message != "boom" || apiErr.Code != "INTERNAL" {
+			t.Errorf("unexpected message/code: %q/%q", apiErr.Message, apiErr.Code)
+		}
+		if apiErr.RequestID != "req-header" {
+			t.Errorf("header request ID should take precedence, got %q", apiErr.RequestID)
+		}
+		if apiErr.Details["code"] != "INTERNAL" {
+			t.Errorf("expected parsed details, got %v", apiErr.Details)
+		}
+	})
+
+	t.Run("nested body", func(t *testing.T) {
+		err := newAPIError(newTestResponse(500, nil), []byte(`{"error": {"code": "DB_DOWN", "message": "database unavailable"}, "request_id": "req-1"}`))
+
+		var apiErr *APIError
+		errors.As(err, &apiErr)
+		if apiErr.Message != "database unavailable" || apiErr.Code != "DB_DOWN" || apiErr.RequestID != "req-1" {
+			t.Errorf("unexpected parse: %+v", apiErr)
+		}
+	})
+
+	t.Run("message field", func(t *testing.T) {
+		err := newAPIError(newTestResponse(500, nil), []byte(`{"message": "something broke", "error_code": "E42"}`))
+
+		var apiErr *APIError
+		errors.As(err, &apiErr)
+		if apiErr.Message != "something broke" || apiErr.Code != "E42" {
+			t.Errorf("unexpected parse: %+v", apiErr)
+		}
+	})
+
+	t.Run("non-JSON body", func(t *testing.T) {
+		err := newAPIError(newTestResponse(502, nil), []byte("Bad Gateway"))
+
+		var apiErr *APIError
+		errors.As(err, &apiErr)
+		if apiErr.Message != "Bad Gateway" || apiErr.Details != nil {
+			t.Errorf("unexpected parse: %+v", apiErr)
+		}
+	})
+}
+
+func TestRateLimitedErrorHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	header.Set("X-RateLimit-Limit", "100")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "1767225600")
+
+	err := newAPIError(newTestResponse(429, header), []byte(`{"error": "rate limit exceeded"}`))
+
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected *RateLimitedError, got %T", err)
+	}
+	if rl.RetryAfter != 7*time.Second {
+		t.Errorf("expected 7s retry-after, got %v", rl.RetryAfter)
+	}
+	if rl.Limit != 100 || rl.Remaining != 0 {
+		t.Errorf("unexpected limit/remaining: %d/%d", rl.Limit, rl.Remaining)
+	}
+	if !rl.ResetAt.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("unexpected reset time: %v", rl.ResetAt)
+	}
+	if err.Error() != "HTTP 429: rate limit exceeded" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{" 5 ", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestNotFoundErrorMessage(t *testing.T) {
+	err := newNotFoundError(newTestResponse(404, nil), []byte("missing"), "connector", "postgres")
+
+	if err.Error() != "connector not found: postgres" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("expected errors.Is(err, ErrNotFound)")
+	}
+
+	bare := &NotFoundError{APIError: &APIError{StatusCode: 404, Message: "nope"}}
+	if bare.Error() != "HTTP 404: nope" {
+		t.Errorf("unexpected message %q", bare.Error())
+	}
+}
+
+func TestClientSideValidationError(t *testing.T) {
+	err := newValidationError("tenantID", "tenantID is required")
+
+	if err.Error() != "tenantID is required" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Error("expected errors.Is(err, ErrValidation)")
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Error("client-side validation errors should not carry an APIError")
+	}
+}
+
+func TestClientReturnsTypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/connectors/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "no such connector"}`))
+		case "/api/v1/static-policies":
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "rate limit exceeded"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "bad credentials"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:     server.URL,
+		ClientID:     "test",
+		ClientSecret: "secret",
+		Retry:        RetryConfig{MaxAttempts: 1},
+		Cache:        CacheConfig{Enabled: false},
+	})
+
+	_, err := client.GetConnector("missing")
+	var nf *NotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected *NotFoundError, got %T: %v", err, err)
+	}
+	if nf.Resource != "connector" || nf.ID != "missing" || nf.Message != "no such connector" {
+		t.Errorf("unexpected NotFoundError: %+v", nf)
+	}
+
+	_, err = client.ListStaticPolicies(nil)
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected *RateLimitedError, got %T: %v", err, err)
+	}
+	if rl.RetryAfter != 2*time.Second {
+		t.Errorf("expected 2s retry-after, got %v", rl.RetryAfter)
+	}
+
+	_, err = client.ListDynamicPolicies(nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -5,10 +5,10 @@
 package axonflow
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -125,6 +125,11 @@ type ExecutionExportOptions struct {
 //	    fmt.Printf("%s: %s (%d steps)\n", exec.RequestID, exec.Status, exec.TotalSteps)
 //	}
 func (c *AxonFlowClient) ListExecutions(options *ListExecutionsOptions) (*ListExecutionsResponse, error) {
+	return c.ListExecutionsContext(context.Background(), options)
+}
+
+// ListExecutionsContext is like ListExecutions but carries a context.
+func (c *AxonFlowClient) ListExecutionsContext(ctx context.Context, options *ListExecutionsOptions) (*ListExecutionsResponse, error) {
 	baseURL := c.config.Endpoint
 
 	// Build query parameters
@@ -155,7 +160,7 @@ func (c *AxonFlowClient) ListExecutions(options *ListExecutionsOptions) (*ListEx
 		reqURL += "?" + params.Encode()
 	}
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := c.getWithContext(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list executions: %w", err)
 	}
@@ -167,10 +172,7 @@ func (c *AxonFlowClient) ListExecutions(options *ListExecutionsOptions) (*ListEx
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var result ListExecutionsResponse
@@ -178,9 +180,7 @@ func (c *AxonFlowClient) ListExecutions(options *ListExecutionsOptions) (*ListEx
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listed %d executions (total: %d)", len(result.Executions), result.Total)
-	}
+	c.debugf("Listed %d executions (total: %d)", len(result.Executions), result.Total)
 
 	return &result, nil
 }
@@ -195,10 +195,15 @@ func (c *AxonFlowClient) ListExecutions(options *ListExecutionsOptions) (*ListEx
 //	    fmt.Printf("  Step %d: %s (%dms)\n", step.StepIndex, step.StepName, *step.DurationMs)
 //	}
 func (c *AxonFlowClient) GetExecution(executionID string) (*ExecutionDetail, error) {
+	return c.GetExecutionContext(context.Background(), executionID)
+}
+
+// GetExecutionContext is like GetExecution but carries a context.
+func (c *AxonFlowClient) GetExecutionContext(ctx context.Context, executionID string) (*ExecutionDetail, error) {
 	baseURL := c.config.Endpoint
 	reqURL := fmt.Sprintf("%s/api/v1/executions/%s", baseURL, executionID)
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := c.getWithContext(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get execution: %w", err)
 	}
@@ -210,14 +215,11 @@ func (c *AxonFlowClient) GetExecution(executionID string) (*ExecutionDetail, err
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("execution not found: %s", executionID)
+		return nil, newNotFoundError(resp, body, "execution", executionID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var result ExecutionDetail
@@ -225,10 +227,7 @@ func (c *AxonFlowClient) GetExecution(executionID string) (*ExecutionDetail, err
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Got execution %s: %s (%d steps)",
-			executionID, result.Summary.Status, len(result.Steps))
-	}
+	c.debugf("Got execution %s: %s (%d steps)", executionID, result.Summary.Status, len(result.Steps))
 
 	return &result, nil
 }
@@ -242,10 +241,15 @@ func (c *AxonFlowClient) GetExecution(executionID string) (*ExecutionDetail, err
 //	    fmt.Printf("Step %d: %s - %s\n", step.StepIndex, step.StepName, step.Status)
 //	}
 func (c *AxonFlowClient) GetExecutionSteps(executionID string) ([]ExecutionSnapshot, error) {
+	return c.GetExecutionStepsContext(context.Background(), executionID)
+}
+
+// GetExecutionStepsContext is like GetExecutionSteps but carries a context.
+func (c *AxonFlowClient) GetExecutionStepsContext(ctx context.Context, executionID string) ([]ExecutionSnapshot, error) {
 	baseURL := c.config.Endpoint
 	reqURL := fmt.Sprintf("%s/api/v1/executions/%s/steps", baseURL, executionID)
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := c.getWithContext(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get execution steps: %w", err)
 	}
@@ -257,14 +261,11 @@ func (c *AxonFlowClient) GetExecutionSteps(executionID string) ([]ExecutionSnaps
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("execution not found: %s", executionID)
+		return nil, newNotFoundError(resp, body, "execution", executionID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var result []ExecutionSnapshot
@@ -272,9 +273,7 @@ func (c *AxonFlowClient) GetExecutionSteps(executionID string) ([]ExecutionSnaps
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Got %d steps for execution %s", len(result), executionID)
-	}
+	c.debugf("Got %d steps for execution %s", len(result), executionID)
 
 	return result, nil
 }
@@ -292,10 +291,15 @@ func (c *AxonFlowClient) GetExecutionSteps(executionID string) ([]ExecutionSnaps
 //	    fmt.Println()
 //	}
 func (c *AxonFlowClient) GetExecutionTimeline(executionID string) ([]TimelineEntry, error) {
+	return c.GetExecutionTimelineContext(context.Background(), executionID)
+}
+
+// GetExecutionTimelineContext is like GetExecutionTimeline but carries a context.
+func (c *AxonFlowClient) GetExecutionTimelineContext(ctx context.Context, executionID string) ([]TimelineEntry, error) {
 	baseURL := c.config.Endpoint
 	reqURL := fmt.Sprintf("%s/api/v1/executions/%s/timeline", baseURL, executionID)
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := c.getWithContext(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get execution timeline: %w", err)
 	}
@@ -307,14 +311,11 @@ func (c *AxonFlowClient) GetExecutionTimeline(executionID string) ([]TimelineEnt
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("execution not found: %s", executionID)
+		return nil, newNotFoundError(resp, body, "execution", executionID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var result []TimelineEntry
@@ -322,9 +323,7 @@ func (c *AxonFlowClient) GetExecutionTimeline(executionID string) ([]TimelineEnt
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Got timeline with %d entries for execution %s", len(result), executionID)
-	}
+	c.debugf("Got timeline with %d entries for execution %s", len(result), executionID)
 
 	return result, nil
 }
@@ -341,6 +340,11 @@ func (c *AxonFlowClient) GetExecutionTimeline(executionID string) ([]TimelineEnt
 //	data, _ := json.MarshalIndent(export, "", "  ")
 //	os.WriteFile("audit-export.json", data, 0644)
 func (c *AxonFlowClient) ExportExecution(executionID string, options *ExecutionExportOptions) (map[string]interface{}, error) {
+	return c.ExportExecutionContext(context.Background(), executionID, options)
+}
+
+// ExportExecutionContext is like ExportExecution but carries a context.
+func (c *AxonFlowClient) ExportExecutionContext(ctx context.Context, executionID string, options *ExecutionExportOptions) (map[string]interface{}, error) {
 	baseURL := c.config.Endpoint
 
 	// Build query parameters
@@ -365,7 +369,7 @@ func (c *AxonFlowClient) ExportExecution(executionID string, options *ExecutionE
 		reqURL += "?" + params.Encode()
 	}
 
-	resp, err := c.httpClient.Get(reqURL)
+	resp, err := c.getWithContext(ctx, reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to export execution: %w", err)
 	}
@@ -377,14 +381,11 @@ func (c *AxonFlowClient) ExportExecution(executionID string, options *ExecutionE
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("execution not found: %s", executionID)
+		return nil, newNotFoundError(resp, body, "execution", executionID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return nil, newAPIError(resp, body)
 	}
 
 	var result map[string]interface{}
@@ -392,9 +393,7 @@ func (c *AxonFlowClient) ExportExecution(executionID string, options *ExecutionE
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Exported execution %s", executionID)
-	}
+	c.debugf("Exported execution %s", executionID)
 
 	return result, nil
 }
@@ -408,35 +407,31 @@ func (c *AxonFlowClient) ExportExecution(executionID string, options *ExecutionE
 //	    log.Printf("Failed to delete: %v", err)
 //	}
 func (c *AxonFlowClient) DeleteExecution(executionID string) error {
+	return c.DeleteExecutionContext(context.Background(), executionID)
+}
+
+// DeleteExecutionContext is like DeleteExecution but carries a context.
+func (c *AxonFlowClient) DeleteExecutionContext(ctx context.Context, executionID string) error {
 	baseURL := c.config.Endpoint
 	reqURL := fmt.Sprintf("%s/api/v1/executions/%s", baseURL, executionID)
 
-	req, err := http.NewRequest(http.MethodDelete, reqURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create delete request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, &request{method: http.MethodDelete, url: reqURL, auth: authNone})
 	if err != nil {
 		return fmt.Errorf("failed to delete execution: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("execution not found: %s", executionID)
+		body, _ := io.ReadAll(resp.Body)
+		return newNotFoundError(resp, body, "execution", executionID)
 	}
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(body),
-		}
+		return newAPIError(resp, body)
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleted execution %s", executionID)
-	}
+	c.debugf("Deleted execution %s", executionID)
 
 	return nil
 }
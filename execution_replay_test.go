@@ -383,6 +383,7 @@ func TestListExecutionsError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	_, err := client.ListExecutions(nil)
@@ -421,6 +422,7 @@ func TestGetExecutionError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	_, err := client.GetExecution("exec-123")
@@ -459,6 +461,7 @@ func TestGetExecutionStepsError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	_, err := client.GetExecutionSteps("exec-123")
@@ -497,6 +500,7 @@ func TestGetExecutionTimelineError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	_, err := client.GetExecutionTimeline("exec-123")
@@ -535,6 +539,7 @@ func TestExportExecutionError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	_, err := client.ExportExecution("exec-123", nil)
@@ -620,6 +625,7 @@ func TestDeleteExecutionError(t *testing.T) {
 		Endpoint:     server.URL,
 		ClientID:     "test-client",
 		ClientSecret: "test-secret",
+		Retry:        noRetry,
 	})
 
 	err := client.DeleteExecution("exec-123")
@@ -0,0 +1,422 @@
+// Multi-endpoint failover and load balancing across AxonFlow Agents
+package axonflow
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStrategy selects which healthy Agent endpoint serves a request.
+type EndpointStrategy string
+
+const (
+	// EndpointPriority sends every request to the first healthy endpoint, in the order
+	// Endpoint, Endpoints[0], Endpoints[1], ... (the default)
+	EndpointPriority EndpointStrategy = "priority"
+	// EndpointRoundRobin spreads requests evenly over the healthy endpoints
+	EndpointRoundRobin EndpointStrategy = "round-robin"
+	// EndpointLeastLatency sends requests to the healthy endpoint with the lowest
+	// recent latency, measured on requests and health probes
+	EndpointLeastLatency EndpointStrategy = "least-latency"
+)
+
+// maxStickyPlans bounds the number of plan IDs remembered for sticky routing.
+const maxStickyPlans = 10000
+
+// FailoverConfig configures how requests are spread over several Agent endpoints
+// (AxonFlowConfig.Endpoints). It has no effect with a single endpoint.
+//
+// An endpoint is ejected from rotation after FailureThreshold consecutive failures
+// that mean it is unavailable (network errors, timeouts and 5xx responses, on requests
+// or health probes). A failed attempt is retried on another endpoint, according to the
+// retry policy, without waiting for the backoff delay. Ejected endpoints are re-admitted
+// after RecoveryThreshold successful probes of the /health route used by HealthCheck.
+// If every endpoint is ejected, requests are still attempted in strategy order.
+type FailoverConfig struct {
+	Strategy          EndpointStrategy // Endpoint selection (default: EndpointPriority)
+	ProbeInterval     time.Duration    // Interval between /health probes of every endpoint (default: 10s; negative: no probing)
+	ProbeTimeout      time.Duration    // Timeout of a single probe (default: 2s)
+	FailureThreshold  int              // Consecutive failures that eject an endpoint (default: 3)
+	RecoveryThreshold int              // Consecutive successful probes that re-admit an endpoint (default: 1)
+	// ReadmitAfter is how long an endpoint stays ejected when probing is disabled
+	// (default: 30s)
+	ReadmitAfter time.Duration
+}
+
+// EndpointStatus is the health of one Agent endpoint, as seen by the client.
+type EndpointStatus struct {
+	URL                 string
+	Healthy             bool          // In rotation (not ejected)
+	ConsecutiveFailures int           // Failures since the last success
+	Latency             time.Duration // Moving average of request and probe latency (zero if unknown)
+	LastError           error         // Most recent failure, if any
+}
+
+// balancer tracks the health of the client's endpoints and picks one per attempt.
+type balancer struct {
+	config FailoverConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	next      int // round-robin position
+
+	// sticky maps plan IDs to the endpoint that handled them, most recent first
+	sticky      map[string]*list.Element
+	stickyOrder *list.List
+}
+
+type endpointState struct {
+	EndpointStatus
+	recoveries int // consecutive successful probes while ejected
+	ejectedAt  time.Time
+}
+
+type stickyPlan struct {
+	planID   string
+	endpoint string
+}
+
+// endpoints returns Endpoint followed by Endpoints, without duplicates.
+func (config AxonFlowConfig) endpoints() []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, u := range append([]string{config.Endpoint}, config.Endpoints...) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// newBalancer returns nil unless config has more than one endpoint.
+func newBalancer(config AxonFlowConfig, logger *slog.Logger) *balancer {
+	urls := config.endpoints()
+	if len(urls) < 2 {
+		return nil
+	}
+
+	fc := config.Failover
+	if fc.Strategy == "" {
+		fc.Strategy = EndpointPriority
+	}
+	if fc.ProbeInterval == 0 {
+		fc.ProbeInterval = 10 * time.Second
+	}
+	if fc.ProbeTimeout <= 0 {
+		fc.ProbeTimeout = 2 * time.Second
+	}
+	if fc.FailureThreshold <= 0 {
+		fc.FailureThreshold = 3
+	}
+	if fc.RecoveryThreshold <= 0 {
+		fc.RecoveryThreshold = 1
+	}
+	if fc.ReadmitAfter <= 0 {
+		fc.ReadmitAfter = 30 * time.Second
+	}
+
+	b := &balancer{
+		config:      fc,
+		logger:      logger,
+		now:         time.Now,
+		sticky:      make(map[string]*list.Element),
+		stickyOrder: list.New(),
+	}
+	for _, u := range urls {
+		b.endpoints = append(b.endpoints, &endpointState{EndpointStatus: EndpointStatus{URL: u, Healthy: true}})
+	}
+	return b
+}
+
+// pick returns the endpoint for the next attempt. It prefers pinned (if healthy),
+// then healthy endpoints not yet tried by this call, then any endpoint not yet tried,
+// then any endpoint.
+func (b *balancer) pick(pinned string, tried map[string]bool) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.readmitExpired()
+
+	var healthy, untried []*endpointState
+	for _, e := range b.endpoints {
+		if tried[e.URL] {
+			continue
+		}
+		if e.Healthy {
+			if e.URL == pinned {
+				return e.URL
+			}
+			healthy = append(healthy, e)
+		}
+		untried = append(untried, e)
+	}
+
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = untried
+	}
+	if len(candidates) == 0 {
+		candidates = b.endpoints
+	}
+
+	switch b.config.Strategy {
+	case EndpointRoundRobin:
+		b.next++
+		return candidates[b.next%len(candidates)].URL
+	case EndpointLeastLatency:
+		best := candidates[0]
+		for _, e := range candidates[1:] {
+			if e.Latency < best.Latency {
+				best = e
+			}
+		}
+		return best.URL
+	}
+	return candidates[0].URL
+}
+
+// hasUntried reports whether a healthy endpoint remains that this call has not tried.
+func (b *balancer) hasUntried(tried map[string]bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range b.endpoints {
+		if e.Healthy && !tried[e.URL] {
+			return true
+		}
+	}
+	return false
+}
+
+// readmitExpired re-admits endpoints ejected for ReadmitAfter when probing is
+// disabled. It must be called with b.mu held.
+func (b *balancer) readmitExpired() {
+	if b.config.ProbeInterval > 0 {
+		return
+	}
+	now := b.now()
+	for _, e := range b.endpoints {
+		if !e.Healthy && now.Sub(e.ejectedAt) >= b.config.ReadmitAfter {
+			b.readmit(e)
+		}
+	}
+}
+
+// report records the outcome of a request attempt (err is the attempt's failure, if
+// any) or probe sent to endpoint.
+func (b *balancer) report(endpoint string, err error, latency time.Duration, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := b.find(endpoint)
+	if e == nil {
+		return
+	}
+
+	if isUnavailableError(err) {
+		e.ConsecutiveFailures++
+		e.LastError = err
+		e.recoveries = 0
+		if e.Healthy && e.ConsecutiveFailures >= b.config.FailureThreshold {
+			e.Healthy = false
+			e.ejectedAt = b.now()
+			b.logger.Warn("AxonFlow endpoint ejected",
+				"endpoint", e.URL,
+				"failures", e.ConsecutiveFailures,
+				"error", err)
+		}
+		return
+	}
+
+	e.ConsecutiveFailures = 0
+	if e.Latency == 0 {
+		e.Latency = latency
+	} else {
+		// Exponentially weighted moving average, weighting the new sample by 1/4
+		e.Latency += (latency - e.Latency) / 4
+	}
+	if !e.Healthy {
+		// A successful request (sent because every endpoint was ejected) re-admits
+		// immediately; probes need RecoveryThreshold successes
+		e.recoveries++
+		if !probe || e.recoveries >= b.config.RecoveryThreshold {
+			b.readmit(e)
+		}
+	}
+}
+
+// readmit must be called with b.mu held.
+func (b *balancer) readmit(e *endpointState) {
+	e.Healthy = true
+	e.ConsecutiveFailures = 0
+	e.recoveries = 0
+	b.logger.Info("AxonFlow endpoint re-admitted", "endpoint", e.URL)
+}
+
+// find must be called with b.mu held.
+func (b *balancer) find(endpoint string) *endpointState {
+	for _, e := range b.endpoints {
+		if e.URL == endpoint {
+			return e
+		}
+	}
+	return nil
+}
+
+// status returns a snapshot of every endpoint.
+func (b *balancer) status() []EndpointStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := make([]EndpointStatus, len(b.endpoints))
+	for i, e := range b.endpoints {
+		status[i] = e.EndpointStatus
+	}
+	return status
+}
+
+// run probes every endpoint each ProbeInterval until ctx is done.
+func (b *balancer) run(ctx context.Context, client *http.Client) {
+	if b.config.ProbeInterval < 0 {
+		return
+	}
+	ticker := time.NewTicker(b.config.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.probeAll(ctx, client)
+		}
+	}
+}
+
+// probeAll probes every endpoint concurrently and waits for the results.
+func (b *balancer) probeAll(ctx context.Context, client *http.Client) {
+	var wg sync.WaitGroup
+	for _, s := range b.status() {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			start := b.now()
+			err := b.probe(ctx, client, endpoint)
+			b.report(endpoint, err, b.now().Sub(start), true)
+		}(s.URL)
+	}
+	wg.Wait()
+}
+
+// probe performs GET endpoint/health, like HealthCheck.
+func (b *balancer) probe(ctx context.Context, client *http.Client, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, b.config.ProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		// Any answer other than 200 means the agent is not ready to serve requests
+		return &APIError{
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    fmt.Sprintf("agent not healthy: %v", newAPIError(resp, body)),
+		}
+	}
+	return nil
+}
+
+// stick remembers that endpoint handled planID.
+func (b *balancer) stick(planID, endpoint string) {
+	if planID == "" || endpoint == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if el, ok := b.sticky[planID]; ok {
+		el.Value.(*stickyPlan).endpoint = endpoint
+		b.stickyOrder.MoveToFront(el)
+		return
+	}
+	b.sticky[planID] = b.stickyOrder.PushFront(&stickyPlan{planID: planID, endpoint: endpoint})
+	if b.stickyOrder.Len() > maxStickyPlans {
+		oldest := b.stickyOrder.Back()
+		b.stickyOrder.Remove(oldest)
+		delete(b.sticky, oldest.Value.(*stickyPlan).planID)
+	}
+}
+
+// stickyEndpoint returns the endpoint that handled planID, if known.
+func (b *balancer) stickyEndpoint(planID string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if el, ok := b.sticky[planID]; ok {
+		return el.Value.(*stickyPlan).endpoint
+	}
+	return ""
+}
+
+// route pins the requests made with a context to an endpoint and records which
+// endpoint served them (see withRoute).
+type route struct {
+	pinned string
+	served string
+}
+
+type routeKey struct{}
+
+// withRoute returns a context whose requests prefer the endpoint that handled planID
+// (if any), and the route recording the endpoint that actually served them.
+func (c *AxonFlowClient) withRoute(ctx context.Context, planID string) (context.Context, *route) {
+	rt := &route{}
+	if c.balancer != nil && planID != "" {
+		rt.pinned = c.balancer.stickyEndpoint(planID)
+	}
+	return context.WithValue(ctx, routeKey{}, rt), rt
+}
+
+// stickPlan remembers the endpoint recorded in rt as the one handling planID.
+func (c *AxonFlowClient) stickPlan(planID string, rt *route) {
+	if c.balancer != nil {
+		c.balancer.stick(planID, rt.served)
+	}
+}
+
+func routeFrom(ctx context.Context) *route {
+	rt, _ := ctx.Value(routeKey{}).(*route)
+	return rt
+}
+
+// Endpoints reports the health of the client's Agent endpoints, in priority order. A
+// client with a single endpoint reports it as healthy.
+func (c *AxonFlowClient) Endpoints() []EndpointStatus {
+	if c.balancer == nil {
+		return []EndpointStatus{{URL: c.config.Endpoint, Healthy: true}}
+	}
+	return c.balancer.status()
+}
+
+// targetURL returns the URL of r for an attempt sent to endpoint, which replaces the
+// configured Endpoint. URLs outside Endpoint are returned unchanged.
+func (c *AxonFlowClient) targetURL(r *request) string {
+	if r.endpoint == "" || r.endpoint == c.config.Endpoint || !strings.HasPrefix(r.url, c.config.Endpoint) {
+		return r.url
+	}
+	return r.endpoint + strings.TrimPrefix(r.url, c.config.Endpoint)
+}
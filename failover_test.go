@@ -0,0 +1,223 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// agentServer is a fake Agent counting the requests it serves.
+type agentServer struct {
+	*httptest.Server
+	name     string
+	requests int32
+	delay    time.Duration
+	down     atomic.Bool // /health answers 503
+}
+
+func newAgentServer(t *testing.T, name string) *agentServer {
+	t.Helper()
+	a := &agentServer{name: name}
+	a.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			if a.down.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"status": "healthy"}`))
+			return
+		}
+		atomic.AddInt32(&a.requests, 1)
+		time.Sleep(a.delay)
+		if strings.HasPrefix(r.URL.Path, "/api/v1/plan/") {
+			json.NewEncoder(w).Encode(PlanExecutionResponse{PlanID: strings.TrimPrefix(r.URL.Path, "/api/v1/plan/"), Status: a.name})
+			return
+		}
+		json.NewEncoder(w).Encode(ClientResponse{
+			Success: true,
+			PlanID:  "plan-" + a.name,
+			Data:    map[string]interface{}{"served_by": a.name},
+		})
+	}))
+	t.Cleanup(a.Close)
+	return a
+}
+
+// downURL returns the URL of a server that refuses connections.
+func downURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestFailoverPriority(t *testing.T) {
+	primary := downURL()
+	secondary := newAgentServer(t, "b")
+
+	client, err := New(primary,
+		WithEndpoints(secondary.URL),
+		WithFailover(FailoverConfig{ProbeInterval: -1, FailureThreshold: 2}),
+		WithoutCache(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.ExecuteQuery("user", "q", "chat", nil)
+		if err != nil {
+			t.Fatalf("request %d: expected failover to the secondary, got %v", i+1, err)
+		}
+		if served := resp.Data.(map[string]interface{})["served_by"]; served != "b" {
+			t.Errorf("expected the secondary to serve the request, got %v", served)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected failover without backoff delay, took %v", elapsed)
+	}
+
+	status := client.Endpoints()
+	if len(status) != 2 || status[0].Healthy || status[0].LastError == nil || !status[1].Healthy {
+		t.Errorf("expected the primary to be ejected, got %+v", status)
+	}
+}
+
+func TestFailoverRoundRobin(t *testing.T) {
+	a, b := newAgentServer(t, "a"), newAgentServer(t, "b")
+	client, _ := New(a.URL,
+		WithEndpoints(b.URL),
+		WithFailover(FailoverConfig{Strategy: EndpointRoundRobin, ProbeInterval: -1}),
+		WithoutCache(),
+	)
+	defer client.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		if _, err := client.ExecuteQuery("user", "q", "chat", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.requests != 5 || b.requests != 5 {
+		t.Errorf("expected an even split, got %d and %d", a.requests, b.requests)
+	}
+}
+
+func TestFailoverLeastLatency(t *testing.T) {
+	slow, fast := newAgentServer(t, "slow"), newAgentServer(t, "fast")
+	slow.delay = 30 * time.Millisecond
+	client, _ := New(slow.URL,
+		WithEndpoints(fast.URL),
+		WithFailover(FailoverConfig{Strategy: EndpointLeastLatency, ProbeInterval: -1}),
+		WithoutCache(),
+	)
+	defer client.Close(context.Background())
+
+	// Measure both endpoints once
+	client.balancer.report(fast.URL, nil, time.Millisecond, true)
+	client.balancer.report(slow.URL, nil, 30*time.Millisecond, true)
+
+	for i := 0; i < 5; i++ {
+		client.ExecuteQuery("user", "q", "chat", nil)
+	}
+	if fast.requests != 5 {
+		t.Errorf("expected all requests on the faster endpoint, got %d (slow: %d)", fast.requests, slow.requests)
+	}
+}
+
+func TestFailoverProbesEjectAndReadmit(t *testing.T) {
+	a, b := newAgentServer(t, "a"), newAgentServer(t, "b")
+	client, _ := New(a.URL,
+		WithEndpoints(b.URL),
+		WithFailover(FailoverConfig{ProbeInterval: 10 * time.Millisecond, FailureThreshold: 2, RecoveryThreshold: 2}),
+		WithoutCache(),
+	)
+	defer client.Close(context.Background())
+
+	waitFor := func(healthy bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if client.Endpoints()[0].Healthy == healthy {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for the primary to become healthy=%v: %+v", healthy, client.Endpoints())
+	}
+
+	a.down.Store(true)
+	waitFor(false)
+
+	resp, err := client.ExecuteQuery("user", "q", "chat", nil)
+	if err != nil || resp.Data.(map[string]interface{})["served_by"] != "b" {
+		t.Errorf("expected the ejected primary to be skipped, got %v, %v", resp, err)
+	}
+
+	a.down.Store(false)
+	waitFor(true)
+}
+
+func TestFailoverStickyPlans(t *testing.T) {
+	a, b := newAgentServer(t, "a"), newAgentServer(t, "b")
+	client, _ := New(a.URL,
+		WithEndpoints(b.URL),
+		WithFailover(FailoverConfig{Strategy: EndpointRoundRobin, ProbeInterval: -1}),
+		WithoutCache(),
+	)
+	defer client.Close(context.Background())
+
+	plan, err := client.GeneratePlan("research", "travel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generatedBy := strings.TrimPrefix(plan.PlanID, "plan-")
+
+	for i := 0; i < 4; i++ {
+		status, err := client.GetPlanStatus(plan.PlanID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Status != generatedBy {
+			t.Errorf("expected GetPlanStatus on agent %s, got %s", generatedBy, status.Status)
+		}
+	}
+}
+
+func TestFailoverConfig(t *testing.T) {
+	t.Setenv(EnvEndpoint, "")
+	t.Setenv(EnvEndpoints, "https://a.example.com, https://b.example.com")
+	t.Setenv(EnvFailoverStrategy, "round-robin")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+	if client.config.Endpoint != "https://a.example.com" || len(client.Endpoints()) != 2 {
+		t.Errorf("unexpected endpoints: %q, %+v", client.config.Endpoint, client.Endpoints())
+	}
+	if client.balancer.config.Strategy != EndpointRoundRobin {
+		t.Errorf("expected round-robin, got %q", client.balancer.config.Strategy)
+	}
+
+	_, err = New("https://a.example.com", WithEndpoints("b.example.com"), WithFailover(FailoverConfig{Strategy: "random"}))
+	var valErr *ValidationError
+	for _, field := range []string{"Endpoints[0]", "Failover.Strategy"} {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			if errors.As(e, &valErr) && valErr.Field == field {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s validation error, got %v", field, err)
+		}
+	}
+}
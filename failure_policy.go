@@ -0,0 +1,203 @@
+// Failure policies: what the client does when AxonFlow is unavailable
+package axonflow
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FailureMode selects how a call behaves when AxonFlow cannot render a decision
+// (network error, timeout, 5xx response or open circuit breaker).
+type FailureMode string
+
+const (
+	// FailOpen lets the call proceed as if it had been allowed
+	FailOpen FailureMode = "fail-open"
+	// FailClosed returns the error to the caller
+	FailClosed FailureMode = "fail-closed"
+	// FailCached returns the last decision AxonFlow made for the same request, and
+	// fails closed if there is none
+	FailCached FailureMode = "fail-cached"
+)
+
+// Request types that can be configured separately in a FailurePolicy.
+const (
+	RequestTypeLLMChat  = "llm_chat"
+	RequestTypeSQL      = "sql"
+	RequestTypeMCPQuery = "mcp-query"
+	RequestTypePreCheck = "pre-check"
+	RequestTypeAudit    = "audit"
+)
+
+// FailurePolicy selects a FailureMode per request type. Unset fields fall back to
+// Default; if Default is unset too, the client keeps its historical behavior:
+// ExecuteQuery fails open in production mode and closed in sandbox mode, and direct
+// MCP queries, Gateway Mode pre-checks and audits fail closed.
+type FailurePolicy struct {
+	Default  FailureMode // Mode for request types without an explicit setting
+	LLMChat  FailureMode // ExecuteQuery with request type "llm_chat" or "chat"
+	SQL      FailureMode // ExecuteQuery with request type "sql"
+	MCPQuery FailureMode // QueryConnector, MCPQuery and ExecuteQuery with request type "mcp-query"
+	PreCheck FailureMode // PreCheck / GetPolicyApprovedContext
+	Audit    FailureMode // AuditLLMCall
+
+	// CachedDecisions is the number of recent decisions kept for FailCached (default: 1000)
+	CachedDecisions int
+	// CachedDecisionTTL is how long a decision may be reused by FailCached (default: 1h)
+	CachedDecisionTTL time.Duration
+}
+
+// modeFor returns the mode configured for requestType, or fallback if none is set.
+func (p FailurePolicy) modeFor(requestType string, fallback FailureMode) FailureMode {
+	var mode FailureMode
+	switch requestType {
+	case RequestTypeLLMChat, "chat":
+		mode = p.LLMChat
+	case RequestTypeSQL:
+		mode = p.SQL
+	case RequestTypeMCPQuery:
+		mode = p.MCPQuery
+	case RequestTypePreCheck:
+		mode = p.PreCheck
+	case RequestTypeAudit:
+		mode = p.Audit
+	}
+	if mode == "" {
+		mode = p.Default
+	}
+	if mode == "" {
+		mode = fallback
+	}
+	return mode
+}
+
+// usesCache reports whether any request type is configured for FailCached.
+func (p FailurePolicy) usesCache() bool {
+	for _, m := range []FailureMode{p.Default, p.LLMChat, p.SQL, p.MCPQuery, p.PreCheck, p.Audit} {
+		if m == FailCached {
+			return true
+		}
+	}
+	return false
+}
+
+// failureMode returns the mode for requestType. ExecuteQuery passes queryFallback
+// true, which keeps the production-mode fail-open default.
+func (c *AxonFlowClient) failureMode(requestType string, queryFallback bool) FailureMode {
+	fallback := FailClosed
+	if queryFallback && c.config.Mode == "production" {
+		fallback = FailOpen
+	}
+	return c.config.FailurePolicy.modeFor(requestType, fallback)
+}
+
+// decisionKey builds the key under which a decision is remembered for FailCached.
+// Keys include the client ID, so the clients of a TenantPool, which share the store,
+// never recall each other's decisions.
+func (c *AxonFlowClient) decisionKey(requestType string, parts ...string) string {
+	return c.config.ClientID + "\x00" + requestType + "\x00" + strings.Join(parts, "\x00")
+}
+
+// canonicalJSON encodes value for use in a decision key. Map keys are encoded in
+// sorted order, so equal values give equal keys.
+func canonicalJSON(value interface{}) string {
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		// Not JSON-encodable; the request itself will fail to marshal too
+		return err.Error()
+	}
+	return string(canonical)
+}
+
+// decisionStore is a bounded, least-recently-used store of recent decisions.
+type decisionStore struct {
+	mu      sync.Mutex
+	max     int
+	ttl     time.Duration
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type storedDecision struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func newDecisionStore(max int, ttl time.Duration) *decisionStore {
+	if max <= 0 {
+		max = 1000
+	}
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &decisionStore{
+		max:     max,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (s *decisionStore) put(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := time.Now().Add(s.ttl)
+	if el, ok := s.entries[key]; ok {
+		d := el.Value.(*storedDecision)
+		d.value, d.expires = value, expires
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&storedDecision{key: key, value: value, expires: expires})
+	for s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*storedDecision).key)
+	}
+}
+
+func (s *decisionStore) get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	d := el.Value.(*storedDecision)
+	if time.Now().After(d.expires) {
+		s.order.Remove(el)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return d.value, true
+}
+
+// remember stores a decision for later use by FailCached. It is a no-op unless some
+// request type uses FailCached.
+func (c *AxonFlowClient) remember(key string, value interface{}) {
+	if c.decisions != nil {
+		c.decisions.put(key, value)
+	}
+}
+
+// recall returns a remembered decision.
+func (c *AxonFlowClient) recall(key string) (interface{}, bool) {
+	if c.decisions == nil {
+		return nil, false
+	}
+	return c.decisions.get(key)
+}
+
+// unavailableMessage is the Error text of results produced by fail-open.
+func unavailableMessage(err error) string {
+	return fmt.Sprintf("AxonFlow unavailable (fail-open): %v", err)
+}
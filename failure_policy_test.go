@@ -0,0 +1,325 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// noCache disables the response cache (a zero TTL would re-enable it).
+var noCache = CacheConfig{Enabled: false, TTL: time.Second}
+
+func TestFailurePolicyModeFor(t *testing.T) {
+	policy := FailurePolicy{
+		Default:  FailClosed,
+		LLMChat:  FailOpen,
+		MCPQuery: FailCached,
+	}
+
+	tests := []struct {
+		requestType string
+		expected    FailureMode
+	}{
+		{RequestTypeLLMChat, FailOpen},
+		{"chat", FailOpen},
+		{RequestTypeSQL, FailClosed},
+		{RequestTypeMCPQuery, FailCached},
+		{RequestTypePreCheck, FailClosed},
+		{"multi-agent-plan", FailClosed},
+	}
+	for _, tt := range tests {
+		if got := policy.modeFor(tt.requestType, FailOpen); got != tt.expected {
+			t.Errorf("modeFor(%q) = %q, want %q", tt.requestType, got, tt.expected)
+		}
+	}
+
+	if got := (FailurePolicy{}).modeFor(RequestTypeSQL, FailOpen); got != FailOpen {
+		t.Errorf("expected the fallback for an empty policy, got %q", got)
+	}
+}
+
+func TestFailureModeDefaults(t *testing.T) {
+	production := NewClient(AxonFlowConfig{Endpoint: "http://localhost:8080", Mode: "production"})
+	sandbox := NewClient(AxonFlowConfig{Endpoint: "http://localhost:8080", Mode: "sandbox"})
+
+	if m := production.failureMode(RequestTypeSQL, true); m != FailOpen {
+		t.Errorf("expected ExecuteQuery to fail open in production, got %q", m)
+	}
+	if m := sandbox.failureMode(RequestTypeSQL, true); m != FailClosed {
+		t.Errorf("expected ExecuteQuery to fail closed in sandbox, got %q", m)
+	}
+	if m := production.failureMode(RequestTypePreCheck, false); m != FailClosed {
+		t.Errorf("expected pre-check to fail closed by default, got %q", m)
+	}
+}
+
+func TestDecisionStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := newDecisionStore(2, time.Hour)
+	s.put("a", 1)
+	s.put("b", 2)
+	s.get("a") // a is now more recent than b
+	s.put("c", 3)
+
+	if _, ok := s.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok := s.get("a"); !ok || v != 1 {
+		t.Errorf("expected a=1, got %v (%v)", v, ok)
+	}
+	if v, ok := s.get("c"); !ok || v != 3 {
+		t.Errorf("expected c=3, got %v (%v)", v, ok)
+	}
+}
+
+func TestDecisionStoreExpires(t *testing.T) {
+	s := newDecisionStore(10, 10*time.Millisecond)
+	s.put("a", 1)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := s.get("a"); ok {
+		t.Error("expected expired decision to be dropped")
+	}
+}
+
+func TestExecuteQueryFailCached(t *testing.T) {
+	var down int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ClientResponse{Success: true, Blocked: true, BlockReason: "PII detected"})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		Cache:         noCache,
+		FailurePolicy: FailurePolicy{SQL: FailCached},
+	})
+
+	if _, err := client.ExecuteQuery("user", "SELECT ssn FROM users", "sql", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	atomic.StoreInt32(&down, 1)
+
+	resp, err := client.ExecuteQuery("user", "SELECT ssn FROM users", "sql", nil)
+	if err != nil {
+		t.Fatalf("expected cached decision, got error: %v", err)
+	}
+	if !resp.Blocked || resp.BlockReason != "PII detected" || resp.Fallback != FailCached {
+		t.Errorf("expected cached block decision, got %+v", resp)
+	}
+
+	// No decision for this query: fails closed
+	_, err = client.ExecuteQuery("user", "SELECT 1", "sql", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 503 {
+		t.Errorf("expected HTTP 503 APIError without a cached decision, got %v", err)
+	}
+}
+
+func TestExecuteQueryFailClosedInProduction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		Mode:          "production",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		Cache:         noCache,
+		FailurePolicy: FailurePolicy{LLMChat: FailClosed},
+	})
+
+	if _, err := client.ExecuteQuery("user", "hello", "chat", nil); err == nil {
+		t.Error("expected llm_chat to fail closed")
+	}
+
+	// Other request types keep the production fail-open default
+	resp, err := client.ExecuteQuery("user", "SELECT 1", "sql", nil)
+	if err != nil {
+		t.Fatalf("expected sql to fail open, got %v", err)
+	}
+	if !resp.Success || resp.Fallback != FailOpen {
+		t.Errorf("expected fail-open response, got %+v", resp)
+	}
+}
+
+func TestPreCheckFailOpenWhenCircuitOpen(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:       server.URL,
+		ClientID:       "test",
+		Retry:          RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: CircuitBreakerConfig{Enabled: true, FailureThreshold: 1, CoolDown: time.Hour},
+		FailurePolicy:  FailurePolicy{PreCheck: FailOpen},
+	})
+
+	for i := 0; i < 3; i++ {
+		result, err := client.PreCheck("user", "query", nil, nil)
+		if err != nil {
+			t.Fatalf("call %d: expected fail-open, got %v", i+1, err)
+		}
+		if !result.Approved || result.Fallback != FailOpen {
+			t.Errorf("call %d: expected fail-open approval, got %+v", i+1, result)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the open circuit to skip the agent, got %d calls", calls)
+	}
+}
+
+func TestPreCheckFailClosedByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Mode:     "production",
+		Retry:    RetryConfig{MaxAttempts: 1},
+	})
+
+	if _, err := client.PreCheck("user", "query", nil, nil); err == nil {
+		t.Error("expected pre-check to fail closed by default")
+	}
+}
+
+func TestPreCheckFailOpenDoesNotHideBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		FailurePolicy: FailurePolicy{Default: FailOpen},
+	})
+
+	if _, err := client.PreCheck("user", "query", nil, nil); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized to be returned despite fail-open, got %v", err)
+	}
+}
+
+func TestAuditFailOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		FailurePolicy: FailurePolicy{Audit: FailOpen},
+	})
+
+	result, err := client.AuditLLMCall("ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil)
+	if err != nil {
+		t.Fatalf("expected audit to fail open, got %v", err)
+	}
+	if result.Success || result.Fallback != FailOpen {
+		t.Errorf("expected dropped audit, got %+v", result)
+	}
+}
+
+func TestMCPQueryFailCached(t *testing.T) {
+	var down int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ConnectorResponse{Success: true, Data: []interface{}{"row"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		FailurePolicy: FailurePolicy{MCPQuery: FailCached},
+	})
+
+	req := MCPQueryRequest{Connector: "postgres", Statement: "SELECT 1"}
+	if _, err := client.MCPQuery(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	atomic.StoreInt32(&down, 1)
+
+	resp, err := client.MCPQuery(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected cached result, got %v", err)
+	}
+	if !resp.Success || resp.Fallback != FailCached {
+		t.Errorf("expected cached response, got %+v", resp)
+	}
+
+	req.Options = map[string]interface{}{"limit": 10}
+	if resp, err := client.MCPQuery(context.Background(), req); err == nil {
+		t.Errorf("expected no cached result for other options, got %+v", resp)
+	}
+}
+
+func TestPreCheckFailCachedKeys(t *testing.T) {
+	var down int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"context_id": "ctx-1",
+			"approved":   true,
+			"expires_at": time.Now().Add(time.Minute).Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      server.URL,
+		ClientID:      "test",
+		APIKey:        "key",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		FailurePolicy: FailurePolicy{PreCheck: FailCached},
+	})
+
+	queryContext := map[string]interface{}{"role": "admin"}
+	if _, err := client.PreCheck("user", "query", []string{"a,b"}, queryContext); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	atomic.StoreInt32(&down, 1)
+
+	result, err := client.PreCheck("user", "query", []string{"a,b"}, map[string]interface{}{"role": "admin"})
+	if err != nil || result.Fallback != FailCached {
+		t.Fatalf("expected cached decision, got %+v, %v", result, err)
+	}
+	if result, err := client.PreCheck("user", "query", []string{"a,b"}, map[string]interface{}{"role": "guest"}); err == nil {
+		t.Errorf("expected no cached decision for another context, got %+v", result)
+	}
+	if result, err := client.PreCheck("user", "query", []string{"a", "b"}, queryContext); err == nil {
+		t.Errorf("expected no cached decision for other data sources, got %+v", result)
+	}
+}
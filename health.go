@@ -0,0 +1,458 @@
+// Health and readiness reporting
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SDKVersion is the version of this SDK.
+const SDKVersion = "2.5.0"
+
+// MinServerVersion is the oldest AxonFlow platform version that supports every API
+// used by this SDK (MCP policy features were added in v3.2.0).
+const MinServerVersion = "3.2.0"
+
+// HealthStatus is the health of the Agent or one of its components.
+type HealthStatus string
+
+const (
+	HealthHealthy   HealthStatus = "healthy"
+	HealthDegraded  HealthStatus = "degraded"  // working, with problems worth attention
+	HealthUnhealthy HealthStatus = "unhealthy" // not able to serve requests
+	HealthUnknown   HealthStatus = "unknown"   // not reported or not checked
+)
+
+// ComponentHealth is the health of one component reported by the Agent, or of a
+// check made by Diagnose.
+type ComponentHealth struct {
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+}
+
+// HealthReport is the result of Diagnose.
+type HealthReport struct {
+	Status    HealthStatus  // Overall status: the worst of the checks below
+	Endpoint  string        // Agent endpoint that was checked
+	CheckedAt time.Time     // When the check started
+	Latency   time.Duration // Round-trip time of the /health request
+
+	SDKVersion    string // SDKVersion
+	ServerVersion string // Version reported by the Agent (empty if not reported)
+	// Compatible is false if the Agent reports a version older than MinServerVersion
+	Compatible    bool
+	Compatibility string // Explanation of Compatible
+
+	Agent        ComponentHealth            // The Agent's own /health status
+	Orchestrator ComponentHealth            // Orchestrator reachability, as reported by the Agent
+	Database     ComponentHealth            // Database, as reported by the Agent
+	Credentials  ComponentHealth            // Result of an authenticated call with the client's credentials
+	Components   map[string]ComponentHealth // Every component reported by the Agent
+	Connectors   map[string]ComponentHealth // Connector statuses reported by the Agent
+
+	// Endpoints is the health of every configured endpoint (see AxonFlowConfig.Endpoints)
+	Endpoints []EndpointStatus
+
+	Problems []string               // Human-readable description of every problem found
+	Body     map[string]interface{} // Decoded /health response body
+}
+
+// Ready reports whether the client can serve requests: the report is healthy or
+// degraded.
+func (r *HealthReport) Ready() bool {
+	return r.Status == HealthHealthy || r.Status == HealthDegraded
+}
+
+// MarshalJSON encodes the report with snake_case keys and durations as strings. The
+// raw /health body is omitted.
+func (r *HealthReport) MarshalJSON() ([]byte, error) {
+	type endpoint struct {
+		URL                 string `json:"url"`
+		Healthy             bool   `json:"healthy"`
+		ConsecutiveFailures int    `json:"consecutive_failures,omitempty"`
+		Latency             string `json:"latency,omitempty"`
+		LastError           string `json:"last_error,omitempty"`
+	}
+	endpoints := make([]endpoint, len(r.Endpoints))
+	for i, e := range r.Endpoints {
+		endpoints[i] = endpoint{URL: redactURL(e.URL), Healthy: e.Healthy, ConsecutiveFailures: e.ConsecutiveFailures}
+		if e.Latency > 0 {
+			endpoints[i].Latency = e.Latency.String()
+		}
+		if e.LastError != nil {
+			endpoints[i].LastError = e.LastError.Error()
+		}
+	}
+
+	return json.Marshal(struct {
+		Status        HealthStatus               `json:"status"`
+		Endpoint      string                     `json:"endpoint"`
+		CheckedAt     time.Time                  `json:"checked_at"`
+		Latency       string                     `json:"latency"`
+		SDKVersion    string                     `json:"sdk_version"`
+		ServerVersion string                     `json:"server_version,omitempty"`
+		Compatible    bool                       `json:"compatible"`
+		Compatibility string                     `json:"compatibility"`
+		Agent         ComponentHealth            `json:"agent"`
+		Orchestrator  ComponentHealth            `json:"orchestrator"`
+		Database      ComponentHealth            `json:"database"`
+		Credentials   ComponentHealth            `json:"credentials"`
+		Components    map[string]ComponentHealth `json:"components,omitempty"`
+		Connectors    map[string]ComponentHealth `json:"connectors,omitempty"`
+		Endpoints     []endpoint                 `json:"endpoints"`
+		Problems      []string                   `json:"problems,omitempty"`
+	}{
+		r.Status, redactURL(r.Endpoint), r.CheckedAt, r.Latency.String(), r.SDKVersion, r.ServerVersion,
+		r.Compatible, r.Compatibility, r.Agent, r.Orchestrator, r.Database, r.Credentials,
+		r.Components, r.Connectors, endpoints, r.Problems,
+	})
+}
+
+// Diagnose checks the Agent and the client's configuration. It fetches the Agent's
+// /health route and decodes the version and component statuses it reports, measures
+// its latency, validates the credentials with a cheap authenticated call, and compares
+// the server version with MinServerVersion.
+//
+// Each check is a single attempt: retries and the circuit breaker do not apply, and
+// the failure policy never turns a failed check into a healthy report. Problems are
+// reported in the HealthReport; the error is only non-nil if the client is closed.
+func (c *AxonFlowClient) Diagnose(ctx context.Context) (*HealthReport, error) {
+	if err := c.checkOpen(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &HealthReport{
+		Endpoint:     c.config.Endpoint,
+		CheckedAt:    time.Now(),
+		SDKVersion:   SDKVersion,
+		Compatible:   true,
+		Agent:        ComponentHealth{Status: HealthUnknown},
+		Orchestrator: ComponentHealth{Status: HealthUnknown},
+		Database:     ComponentHealth{Status: HealthUnknown},
+		Credentials:  ComponentHealth{Status: HealthUnknown},
+	}
+	if c.balancer != nil {
+		report.Endpoint = c.balancer.pick("", nil)
+	}
+
+	c.diagnoseAgent(ctx, report)
+	if report.Agent.Status != HealthUnhealthy {
+		c.diagnoseCredentials(ctx, report)
+	}
+	report.Endpoints = c.Endpoints()
+	report.Status = report.overallStatus()
+
+	c.logger.DebugContext(ctx, "AxonFlow diagnosis completed",
+		"status", report.Status,
+		"endpoint", report.Endpoint,
+		"duration", report.Latency,
+		"problems", len(report.Problems))
+
+	return report, nil
+}
+
+// diagnoseOnce performs a single attempt of r against the report's endpoint.
+func (c *AxonFlowClient) diagnoseOnce(ctx context.Context, r *request, endpoint string) (*http.Response, []byte, time.Duration, error) {
+	r.endpoint = endpoint
+	req, err := c.build(ctx, r)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, time.Since(start), err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp, body, time.Since(start), err
+}
+
+// diagnoseAgent checks /health and decodes its body.
+func (c *AxonFlowClient) diagnoseAgent(ctx context.Context, report *HealthReport) {
+	r := &request{method: http.MethodGet, url: c.config.Endpoint + "/health", auth: authNone}
+	resp, body, latency, err := c.diagnoseOnce(ctx, r, report.Endpoint)
+	report.Latency = latency
+	if err != nil {
+		report.Agent = ComponentHealth{Status: HealthUnhealthy, Message: err.Error()}
+		report.problem("agent unreachable: %v", err)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp, body)
+		report.Agent = ComponentHealth{Status: HealthUnhealthy, Message: apiErr.Error()}
+		report.problem("agent not healthy: %v", apiErr)
+		return
+	}
+
+	report.Agent = ComponentHealth{Status: HealthHealthy}
+	if err := json.Unmarshal(body, &report.Body); err != nil {
+		// A plain 200 is a healthy agent that reports no details
+		report.Body = nil
+		return
+	}
+
+	if status, ok := parseComponent(report.Body["status"]); ok {
+		report.Agent.Status = status.Status
+		if status.Status != HealthHealthy {
+			report.problem("agent reports status %q", report.Body["status"])
+		}
+	}
+	for _, key := range []string{"version", "server_version", "axonflow_version"} {
+		if v, ok := report.Body[key].(string); ok && v != "" {
+			report.ServerVersion = v
+			break
+		}
+	}
+	report.checkCompatibility()
+
+	report.Components = parseComponents(report.Body["components"])
+	for _, key := range []string{"orchestrator", "database"} {
+		if component, ok := parseComponent(report.Body[key]); ok {
+			if report.Components == nil {
+				report.Components = map[string]ComponentHealth{}
+			}
+			report.Components[key] = component
+		}
+	}
+	if component, ok := report.Components["orchestrator"]; ok {
+		report.Orchestrator = component
+	}
+	for _, key := range []string{"database", "db", "postgres"} {
+		if component, ok := report.Components[key]; ok {
+			report.Database = component
+			break
+		}
+	}
+	for _, name := range sortedKeys(report.Components) {
+		if status := report.Components[name].Status; status != HealthHealthy && status != HealthUnknown {
+			report.problem("component %s is %s", name, status)
+		}
+	}
+
+	report.Connectors = parseComponents(report.Body["connectors"])
+	for _, name := range sortedKeys(report.Connectors) {
+		if status := report.Connectors[name].Status; status != HealthHealthy && status != HealthUnknown {
+			report.problem("connector %s is %s", name, status)
+		}
+	}
+}
+
+// diagnoseCredentials makes an authenticated call that lists at most one static
+// policy.
+func (c *AxonFlowClient) diagnoseCredentials(ctx context.Context, report *HealthReport) {
+	if c.auth == nil && c.config.ClientID == "" {
+		report.Credentials = ComponentHealth{Status: HealthUnknown, Message: "no credentials configured"}
+		return
+	}
+
+	r := &request{method: http.MethodGet, url: c.config.Endpoint + "/api/v1/static-policies?limit=1", auth: authClient}
+	resp, body, _, err := c.diagnoseOnce(ctx, r, report.Endpoint)
+	switch {
+	case err != nil:
+		report.Credentials = ComponentHealth{Status: HealthUnknown, Message: err.Error()}
+		report.problem("credentials could not be checked: %v", err)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiErr := newAPIError(resp, body)
+		report.Credentials = ComponentHealth{Status: HealthUnhealthy, Message: apiErr.Error()}
+		report.problem("credentials rejected: %v", apiErr)
+	case resp.StatusCode >= 500:
+		apiErr := newAPIError(resp, body)
+		report.Credentials = ComponentHealth{Status: HealthUnknown, Message: apiErr.Error()}
+		report.problem("credentials could not be checked: %v", apiErr)
+	default:
+		// Any other answer, including 404 on agents without the policy API, means
+		// the credentials were accepted
+		report.Credentials = ComponentHealth{Status: HealthHealthy}
+	}
+}
+
+// checkCompatibility compares ServerVersion with MinServerVersion.
+func (r *HealthReport) checkCompatibility() {
+	if r.ServerVersion == "" {
+		r.Compatibility = "server version not reported"
+		return
+	}
+	server, ok := parseVersion(r.ServerVersion)
+	if !ok {
+		r.Compatibility = fmt.Sprintf("server version %q not recognized", r.ServerVersion)
+		return
+	}
+	minimum, _ := parseVersion(MinServerVersion)
+	if compareVersions(server, minimum) < 0 {
+		r.Compatible = false
+		r.Compatibility = fmt.Sprintf("server version %s is older than %s, the minimum supported by SDK %s",
+			r.ServerVersion, MinServerVersion, SDKVersion)
+		r.problem("%s", r.Compatibility)
+		return
+	}
+	r.Compatibility = fmt.Sprintf("server version %s is supported by SDK %s", r.ServerVersion, SDKVersion)
+}
+
+// overallStatus is unhealthy if the agent, its orchestrator or database, or the
+// credentials are unhealthy, and degraded for any other problem.
+func (r *HealthReport) overallStatus() HealthStatus {
+	for _, component := range []ComponentHealth{r.Agent, r.Orchestrator, r.Database, r.Credentials} {
+		if component.Status == HealthUnhealthy {
+			return HealthUnhealthy
+		}
+	}
+	if len(r.Problems) > 0 {
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+func (r *HealthReport) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// HealthHandler returns an http.Handler for readiness probes. Each request runs
+// Diagnose and answers 200 if the report is Ready, 503 otherwise, with the report as
+// JSON. Add "?verbose=0" for an empty body.
+func (c *AxonFlowClient) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := c.Diagnose(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		if r.URL.Query().Get("verbose") == "0" {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// parseComponents decodes a map of name to status, or a list of objects with a
+// "name" (or "id") and a status.
+func parseComponents(v interface{}) map[string]ComponentHealth {
+	components := map[string]ComponentHealth{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if component, ok := parseComponent(value); ok {
+				components[name] = component
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			obj, _ := item.(map[string]interface{})
+			name, _ := obj["name"].(string)
+			if name == "" {
+				name, _ = obj["id"].(string)
+			}
+			if component, ok := parseComponent(obj); ok && name != "" {
+				components[name] = component
+			}
+		}
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	return components
+}
+
+// parseComponent decodes a component status given as a string ("ok", "healthy",
+// "down", ...), a boolean, or an object with "status", "healthy" or "reachable" and an
+// optional "message" or "error".
+func parseComponent(v interface{}) (ComponentHealth, bool) {
+	switch v := v.(type) {
+	case string:
+		return ComponentHealth{Status: parseHealthStatus(v)}, true
+	case bool:
+		if v {
+			return ComponentHealth{Status: HealthHealthy}, true
+		}
+		return ComponentHealth{Status: HealthUnhealthy}, true
+	case map[string]interface{}:
+		var component ComponentHealth
+		var ok bool
+		for _, key := range []string{"status", "healthy", "reachable", "connected"} {
+			if component, ok = parseComponent(v[key]); ok {
+				break
+			}
+		}
+		if !ok {
+			return ComponentHealth{}, false
+		}
+		for _, key := range []string{"message", "error"} {
+			if msg, _ := v[key].(string); msg != "" {
+				component.Message = msg
+				break
+			}
+		}
+		return component, true
+	}
+	return ComponentHealth{}, false
+}
+
+func parseHealthStatus(s string) HealthStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "healthy", "ok", "up", "pass", "ready", "connected", "serving":
+		return HealthHealthy
+	case "degraded", "warn", "warning":
+		return HealthDegraded
+	case "", "unknown":
+		return HealthUnknown
+	}
+	return HealthUnhealthy
+}
+
+// parseVersion parses "v3.2.1", "3.2" or "3.2.1-beta.1" into major, minor and patch.
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]ComponentHealth) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newHealthServer serves health as the /health body and checks Basic credentials on
+// the policy API.
+func newHealthServer(t *testing.T, health string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte(health))
+		case "/api/v1/static-policies":
+			if _, secret, _ := r.BasicAuth(); secret != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid credentials"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(staticPoliciesResponse{})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiagnoseHealthy(t *testing.T) {
+	server := newHealthServer(t, `{
+		"status": "healthy",
+		"version": "v3.4.1",
+		"components": {"orchestrator": {"status": "ok"}, "database": "connected", "redis": true},
+		"connectors": [{"name": "postgres", "status": "healthy"}, {"name": "salesforce", "status": "down", "error": "token expired"}]
+	}`)
+	client, _ := New(server.URL, WithCredentials("tenant", "s3cret"))
+
+	report, err := client.Diagnose(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ServerVersion != "v3.4.1" || !report.Compatible {
+		t.Errorf("unexpected version check: %q, %v (%s)", report.ServerVersion, report.Compatible, report.Compatibility)
+	}
+	if report.Orchestrator.Status != HealthHealthy || report.Database.Status != HealthHealthy || report.Components["redis"].Status != HealthHealthy {
+		t.Errorf("unexpected components: %+v", report.Components)
+	}
+	if c := report.Connectors["salesforce"]; c.Status != HealthUnhealthy || c.Message != "token expired" {
+		t.Errorf("unexpected connector status: %+v", c)
+	}
+	if report.Credentials.Status != HealthHealthy {
+		t.Errorf("expected valid credentials, got %+v", report.Credentials)
+	}
+	// A failing connector is worth attention but does not make the client unready
+	if report.Status != HealthDegraded || !report.Ready() || len(report.Problems) != 1 {
+		t.Errorf("expected a degraded, ready report with one problem, got %s %v", report.Status, report.Problems)
+	}
+	if report.Latency <= 0 || report.SDKVersion != SDKVersion {
+		t.Errorf("unexpected latency %v or SDK version %q", report.Latency, report.SDKVersion)
+	}
+}
+
+func TestDiagnoseProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		health string
+		secret string
+		check  func(*HealthReport) bool
+	}{
+		{"invalid credentials", `{"status": "healthy"}`, "wrong",
+			func(r *HealthReport) bool { return r.Credentials.Status == HealthUnhealthy }},
+		{"orchestrator unreachable", `{"status": "degraded", "orchestrator": {"reachable": false}}`, "s3cret",
+			func(r *HealthReport) bool { return r.Orchestrator.Status == HealthUnhealthy }},
+		{"old server", `{"status": "healthy", "version": "3.1.9"}`, "s3cret",
+			func(r *HealthReport) bool { return !r.Compatible && r.Status == HealthDegraded }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newHealthServer(t, tt.health)
+			client, _ := New(server.URL, WithCredentials("tenant", tt.secret))
+			report, _ := client.Diagnose(context.Background())
+			if !tt.check(report) || report.Status == HealthHealthy {
+				t.Errorf("unexpected report: %s %+v", report.Status, report.Problems)
+			}
+		})
+	}
+}
+
+func TestDiagnoseUnreachable(t *testing.T) {
+	client, _ := New(downURL(), WithFailurePolicy(FailurePolicy{Default: FailOpen}))
+	report, err := client.Diagnose(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != HealthUnhealthy || report.Ready() || report.Credentials.Status != HealthUnknown {
+		t.Errorf("expected an unhealthy report without a credentials check, got %+v", report)
+	}
+
+	client.Close(context.Background())
+	if _, err := client.Diagnose(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	server := newHealthServer(t, `{"status": "healthy", "version": "3.2.0"}`)
+	client, _ := New(server.URL, WithCredentials("tenant", "s3cret"))
+
+	rec := httptest.NewRecorder()
+	client.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["status"] != "healthy" || body["server_version"] != "3.2.0" || body["compatible"] != true {
+		t.Errorf("unexpected body: %v", body)
+	}
+
+	client, _ = New(server.URL, WithCredentials("tenant", "wrong"))
+	rec = httptest.NewRecorder()
+	client.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz?verbose=0", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.Len() != 0 {
+		t.Errorf("expected an empty 503, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"3.2.0", "3.2.0", 0},
+		{"v3.10.0", "3.2.0", 1},
+		{"3.2.0-beta.1", "3.2.0", 0},
+		{"3.1", "3.2.0", -1},
+	} {
+		a, okA := parseVersion(tt.a)
+		b, okB := parseVersion(tt.b)
+		if !okA || !okB || compareVersions(a, b) != tt.want {
+			t.Errorf("compare(%q, %q) = %d, want %d", tt.a, tt.b, compareVersions(a, b), tt.want)
+		}
+	}
+	if _, ok := parseVersion("latest"); ok {
+		t.Error(`expected "latest" not to parse`)
+	}
+}
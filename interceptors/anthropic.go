@@ -119,7 +119,7 @@ func (w *WrappedAnthropicClient) CreateMessage(ctx context.Context, req Anthropi
 
 	// Check with AxonFlow
 	startTime := time.Now()
-	response, err := w.axonflow.ExecuteQuery(w.userToken, prompt, "llm_chat", evalContext)
+	response, err := w.axonflow.ExecuteQueryContext(ctx, w.userToken, prompt, "llm_chat", evalContext)
 	if err != nil {
 		return AnthropicMessageResponse{}, err
 	}
@@ -145,34 +145,34 @@ func (w *WrappedAnthropicClient) CreateMessage(ctx context.Context, req Anthropi
 	// Calculate latency
 	latencyMs := time.Since(startTime).Milliseconds()
 
-	// Audit the call (best effort - don't fail the response if audit fails)
-	go func() {
-		summary := extractAnthropicResponseSummary(result)
+	// Audit the call in the background (best effort - don't fail the response if audit fails;
+	// AxonFlowClient.Close waits for pending audits)
+	summary := extractAnthropicResponseSummary(result)
 
-		tokenUsage := axonflow.TokenUsage{
-			PromptTokens:     result.Usage.InputTokens,
-			CompletionTokens: result.Usage.OutputTokens,
-			TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
-		}
+	tokenUsage := axonflow.TokenUsage{
+		PromptTokens:     result.Usage.InputTokens,
+		CompletionTokens: result.Usage.OutputTokens,
+		TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
+	}
 
-		// Get context ID from response metadata if available
-		contextID := ""
-		if response.RequestID != "" {
-			contextID = response.RequestID
-		}
+	// Get context ID from response metadata if available
+	contextID := ""
+	if response.RequestID != "" {
+		contextID = response.RequestID
+	}
 
-		if contextID != "" {
-			_, _ = w.axonflow.AuditLLMCall(
-				contextID,
-				summary,
-				"anthropic",
-				req.Model,
-				tokenUsage,
-				latencyMs,
-				nil,
-			)
-		}
-	}()
+	if contextID != "" {
+		w.axonflow.AuditLLMCallAsync(
+			ctx,
+			contextID,
+			summary,
+			"anthropic",
+			req.Model,
+			tokenUsage,
+			latencyMs,
+			nil,
+		)
+	}
 
 	return result, nil
 }
@@ -233,7 +233,7 @@ func WrapAnthropicFunc(
 
 		// Check with AxonFlow
 		startTime := time.Now()
-		response, err := axonflowClient.ExecuteQuery(userToken, prompt, "llm_chat", evalContext)
+		response, err := axonflowClient.ExecuteQueryContext(ctx, userToken, prompt, "llm_chat", evalContext)
 		if err != nil {
 			return AnthropicMessageResponse{}, err
 		}
@@ -259,28 +259,27 @@ func WrapAnthropicFunc(
 		// Calculate latency
 		latencyMs := time.Since(startTime).Milliseconds()
 
-		// Audit the call (best effort)
-		go func() {
-			summary := extractAnthropicResponseSummary(result)
+		// Audit the call in the background (best effort)
+		summary := extractAnthropicResponseSummary(result)
 
-			tokenUsage := axonflow.TokenUsage{
-				PromptTokens:     result.Usage.InputTokens,
-				CompletionTokens: result.Usage.OutputTokens,
-				TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
-			}
+		tokenUsage := axonflow.TokenUsage{
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+			TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
+		}
 
-			if response.RequestID != "" {
-				_, _ = axonflowClient.AuditLLMCall(
-					response.RequestID,
-					summary,
-					"anthropic",
-					req.Model,
-					tokenUsage,
-					latencyMs,
-					nil,
-				)
-			}
-		}()
+		if response.RequestID != "" {
+			axonflowClient.AuditLLMCallAsync(
+				ctx,
+				response.RequestID,
+				summary,
+				"anthropic",
+				req.Model,
+				tokenUsage,
+				latencyMs,
+				nil,
+			)
+		}
 
 		return result, nil
 	}
@@ -156,7 +156,7 @@ func WrapBedrockInvokeModel(fn BedrockInvokeFunc, axonflowClient *axonflow.AxonF
 			"model":    input.ModelId,
 		}
 
-		policyResult, err := axonflowClient.GetPolicyApprovedContext(userToken, prompt, nil, preCheckCtx)
+		policyResult, err := axonflowClient.GetPolicyApprovedContextWithContext(ctx, userToken, prompt, nil, preCheckCtx)
 		if err != nil {
 			return nil, err
 		}
@@ -186,7 +186,8 @@ func WrapBedrockInvokeModel(fn BedrockInvokeFunc, axonflowClient *axonflow.AxonF
 				TotalTokens:      promptTokens + completionTokens,
 			}
 
-			_, _ = axonflowClient.AuditLLMCall(
+			_, _ = axonflowClient.AuditLLMCallContext(
+				ctx,
 				policyResult.ContextID,
 				summary,
 				"bedrock",
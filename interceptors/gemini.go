@@ -152,7 +152,7 @@ func (w *WrappedGeminiModel) GenerateContent(ctx context.Context, parts ...Gemin
 		"model":    w.modelName,
 	}
 
-	policyResult, err := w.axonflow.GetPolicyApprovedContext(w.userToken, prompt, nil, preCheckCtx)
+	policyResult, err := w.axonflow.GetPolicyApprovedContextWithContext(ctx, w.userToken, prompt, nil, preCheckCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +189,8 @@ func (w *WrappedGeminiModel) GenerateContent(ctx context.Context, parts ...Gemin
 			}
 		}
 
-		_, auditErr := w.axonflow.AuditLLMCall(
+		_, auditErr := w.axonflow.AuditLLMCallContext(
+			ctx,
 			policyResult.ContextID,
 			summary,
 			"gemini",
@@ -231,7 +232,7 @@ func WrapGeminiFunc(fn GeminiGenerateFunc, axonflowClient *axonflow.AxonFlowClie
 			"model":    modelName,
 		}
 
-		policyResult, err := axonflowClient.GetPolicyApprovedContext(userToken, prompt, nil, preCheckCtx)
+		policyResult, err := axonflowClient.GetPolicyApprovedContextWithContext(ctx, userToken, prompt, nil, preCheckCtx)
 		if err != nil {
 			return nil, err
 		}
@@ -266,7 +267,8 @@ func WrapGeminiFunc(fn GeminiGenerateFunc, axonflowClient *axonflow.AxonFlowClie
 				}
 			}
 
-			_, _ = axonflowClient.AuditLLMCall(
+			_, _ = axonflowClient.AuditLLMCallContext(
+				ctx,
 				policyResult.ContextID,
 				summary,
 				"gemini",
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	axonflow "github.com/getaxonflow/axonflow-sdk-go/v2"
@@ -1606,3 +1607,70 @@ func TestBedrockTitanRequestResponse(t *testing.T) {
 		t.Errorf("unexpected InputTextTokenCount: %d", resp.InputTextTokenCount)
 	}
 }
+
+func TestGatewayWrappers_AuditWithCallerContext(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	audits := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/audit/llm-call" {
+			audits <- r.Header.Get("traceparent")
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "audit_id": "audit-456"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"approved":   true,
+			"context_id": "ctx-123",
+			"expires_at": "2025-12-31T23:59:59Z",
+		})
+	}))
+	defer server.Close()
+
+	axonflowClient := axonflow.NewClient(axonflow.AxonFlowConfig{
+		Endpoint:     server.URL,
+		ClientID:     "test",
+		ClientSecret: "test-secret",
+		Cache:        axonflow.CacheConfig{Enabled: false},
+	})
+	ctx := axonflow.ContextWithTraceParent(context.Background(), "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	calls := map[string]func() error{
+		"gemini": func() error {
+			fn := WrapGeminiFunc(func(ctx context.Context, parts ...GeminiPart) (*GeminiGenerateContentResponse, error) {
+				return &GeminiGenerateContentResponse{}, nil
+			}, axonflowClient, "user-token", "gemini-pro")
+			_, err := fn(ctx, GeminiText("Hello"))
+			return err
+		},
+		"ollama": func() error {
+			fn := WrapOllamaChatFunc(func(ctx context.Context, req *OllamaChatRequest) (*OllamaChatResponse, error) {
+				return &OllamaChatResponse{Model: req.Model, Done: true}, nil
+			}, axonflowClient, "user-token")
+			_, err := fn(ctx, &OllamaChatRequest{Model: "llama3", Messages: []OllamaMessage{{Role: "user", Content: "Hello"}}})
+			return err
+		},
+		"bedrock": func() error {
+			fn := WrapBedrockInvokeModel(func(ctx context.Context, input *BedrockInvokeInput) (*BedrockInvokeOutput, error) {
+				return &BedrockInvokeOutput{Body: []byte(`{"content":[{"text":"Hi"}]}`)}, nil
+			}, axonflowClient, "user-token")
+			_, err := fn(ctx, &BedrockInvokeInput{
+				ModelId: "anthropic.claude-3-sonnet",
+				Body:    []byte(`{"messages":[{"role":"user","content":"Hello"}]}`),
+			})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		select {
+		case traceparent := <-audits:
+			if !strings.Contains(traceparent, traceID) {
+				t.Errorf("%s: expected the audit to join the caller's trace, got %q", name, traceparent)
+			}
+		default:
+			t.Errorf("%s: expected an audit", name)
+		}
+	}
+}
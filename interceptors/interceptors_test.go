@@ -3,7 +3,11 @@ package interceptors
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+
+	"github.com/getaxonflow/axonflow-sdk-go/v2"
 )
 
 // MockAxonFlowClient is a mock for testing
@@ -227,6 +231,31 @@ func TestPolicyViolationError(t *testing.T) {
 	}
 }
 
+func TestPolicyViolationErrorMatchesSDKErrors(t *testing.T) {
+	err := fmt.Errorf("chat failed: %w", &PolicyViolationError{
+		BlockReason: "PII detected",
+		Policies:    []string{"pii-detection"},
+	})
+
+	if !errors.Is(err, axonflow.ErrPolicyBlocked) {
+		t.Error("errors.Is should match axonflow.ErrPolicyBlocked")
+	}
+
+	var blocked *axonflow.PolicyBlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatal("errors.As should extract *axonflow.PolicyBlockedError")
+	}
+	if blocked.BlockReason != "PII detected" || len(blocked.Policies) != 1 {
+		t.Errorf("unexpected PolicyBlockedError: %+v", blocked)
+	}
+	if blocked.StatusCode != http.StatusForbidden || blocked.RequestID != "" {
+		t.Errorf("unexpected APIError fields: %d, %q", blocked.StatusCode, blocked.RequestID)
+	}
+	if errors.Is(err, axonflow.ErrRateLimited) {
+		t.Error("errors.Is should not match unrelated sentinels")
+	}
+}
+
 func TestCreateAnthropicMessage(t *testing.T) {
 	msg := CreateAnthropicMessage("user", "Hello world")
 
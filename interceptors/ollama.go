@@ -141,7 +141,7 @@ func (w *WrappedOllamaClient) Chat(ctx context.Context, req *OllamaChatRequest)
 		"model":    req.Model,
 	}
 
-	policyResult, err := w.axonflow.GetPolicyApprovedContext(w.userToken, prompt, nil, preCheckCtx)
+	policyResult, err := w.axonflow.GetPolicyApprovedContextWithContext(ctx, w.userToken, prompt, nil, preCheckCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +175,8 @@ func (w *WrappedOllamaClient) Chat(ctx context.Context, req *OllamaChatRequest)
 			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
 		}
 
-		_, _ = w.axonflow.AuditLLMCall(
+		_, _ = w.axonflow.AuditLLMCallContext(
+			ctx,
 			policyResult.ContextID,
 			summary,
 			"ollama",
@@ -209,7 +210,7 @@ func WrapOllamaChatFunc(fn OllamaChatFunc, axonflowClient *axonflow.AxonFlowClie
 			"model":    req.Model,
 		}
 
-		policyResult, err := axonflowClient.GetPolicyApprovedContext(userToken, prompt, nil, preCheckCtx)
+		policyResult, err := axonflowClient.GetPolicyApprovedContextWithContext(ctx, userToken, prompt, nil, preCheckCtx)
 		if err != nil {
 			return nil, err
 		}
@@ -241,7 +242,8 @@ func WrapOllamaChatFunc(fn OllamaChatFunc, axonflowClient *axonflow.AxonFlowClie
 				TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
 			}
 
-			_, _ = axonflowClient.AuditLLMCall(
+			_, _ = axonflowClient.AuditLLMCallContext(
+				ctx,
 				policyResult.ContextID,
 				summary,
 				"ollama",
@@ -264,7 +266,7 @@ func WrapOllamaGenerateFunc(fn OllamaGenerateFunc, axonflowClient *axonflow.Axon
 			"model":    req.Model,
 		}
 
-		policyResult, err := axonflowClient.GetPolicyApprovedContext(userToken, req.Prompt, nil, preCheckCtx)
+		policyResult, err := axonflowClient.GetPolicyApprovedContextWithContext(ctx, userToken, req.Prompt, nil, preCheckCtx)
 		if err != nil {
 			return nil, err
 		}
@@ -296,7 +298,8 @@ func WrapOllamaGenerateFunc(fn OllamaGenerateFunc, axonflowClient *axonflow.Axon
 				TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
 			}
 
-			_, _ = axonflowClient.AuditLLMCall(
+			_, _ = axonflowClient.AuditLLMCallContext(
+				ctx,
 				policyResult.ContextID,
 				summary,
 				"ollama",
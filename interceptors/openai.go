@@ -25,6 +25,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"strings"
 	"time"
 
@@ -91,6 +92,26 @@ func (e *PolicyViolationError) Error() string {
 	return "request blocked by policy: " + e.BlockReason
 }
 
+// Is reports whether target is axonflow.ErrPolicyBlocked, so interceptor blocks
+// can be checked the same way as blocks returned by the client itself.
+func (e *PolicyViolationError) Is(target error) bool {
+	return target == axonflow.ErrPolicyBlocked
+}
+
+// As allows errors.As to extract an *axonflow.PolicyBlockedError from a
+// PolicyViolationError. Its APIError has status 403 and the block reason as message.
+func (e *PolicyViolationError) As(target interface{}) bool {
+	if t, ok := target.(**axonflow.PolicyBlockedError); ok {
+		*t = &axonflow.PolicyBlockedError{
+			APIError:    &axonflow.APIError{StatusCode: http.StatusForbidden, Message: e.BlockReason},
+			BlockReason: e.BlockReason,
+			Policies:    e.Policies,
+		}
+		return true
+	}
+	return false
+}
+
 // WrappedOpenAIClient wraps an OpenAI client with AxonFlow governance
 type WrappedOpenAIClient struct {
 	client    OpenAIChatCompleter
@@ -132,7 +153,7 @@ func (w *WrappedOpenAIClient) CreateChatCompletion(ctx context.Context, req Chat
 
 	// Check with AxonFlow
 	startTime := time.Now()
-	response, err := w.axonflow.ExecuteQuery(w.userToken, prompt, "llm_chat", evalContext)
+	response, err := w.axonflow.ExecuteQueryContext(ctx, w.userToken, prompt, "llm_chat", evalContext)
 	if err != nil {
 		return ChatCompletionResponse{}, err
 	}
@@ -158,42 +179,42 @@ func (w *WrappedOpenAIClient) CreateChatCompletion(ctx context.Context, req Chat
 	// Calculate latency
 	latencyMs := time.Since(startTime).Milliseconds()
 
-	// Audit the call (best effort - don't fail the response if audit fails)
-	go func() {
-		summary := ""
-		if len(result.Choices) > 0 {
-			content := result.Choices[0].Message.Content
-			if len(content) > 100 {
-				summary = content[:100]
-			} else {
-				summary = content
-			}
+	// Audit the call in the background (best effort - don't fail the response if audit fails;
+	// AxonFlowClient.Close waits for pending audits)
+	summary := ""
+	if len(result.Choices) > 0 {
+		content := result.Choices[0].Message.Content
+		if len(content) > 100 {
+			summary = content[:100]
+		} else {
+			summary = content
 		}
+	}
 
-		tokenUsage := axonflow.TokenUsage{
-			PromptTokens:     result.Usage.PromptTokens,
-			CompletionTokens: result.Usage.CompletionTokens,
-			TotalTokens:      result.Usage.TotalTokens,
-		}
+	tokenUsage := axonflow.TokenUsage{
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+	}
 
-		// Get context ID from response metadata if available
-		contextID := ""
-		if response.RequestID != "" {
-			contextID = response.RequestID
-		}
+	// Get context ID from response metadata if available
+	contextID := ""
+	if response.RequestID != "" {
+		contextID = response.RequestID
+	}
 
-		if contextID != "" {
-			_, _ = w.axonflow.AuditLLMCall(
-				contextID,
-				summary,
-				"openai",
-				req.Model,
-				tokenUsage,
-				latencyMs,
-				nil,
-			)
-		}
-	}()
+	if contextID != "" {
+		w.axonflow.AuditLLMCallAsync(
+			ctx,
+			contextID,
+			summary,
+			"openai",
+			req.Model,
+			tokenUsage,
+			latencyMs,
+			nil,
+		)
+	}
 
 	return result, nil
 }
@@ -240,7 +261,7 @@ func WrapOpenAIFunc(
 
 		// Check with AxonFlow
 		startTime := time.Now()
-		response, err := axonflowClient.ExecuteQuery(userToken, prompt, "llm_chat", evalContext)
+		response, err := axonflowClient.ExecuteQueryContext(ctx, userToken, prompt, "llm_chat", evalContext)
 		if err != nil {
 			return ChatCompletionResponse{}, err
 		}
@@ -266,36 +287,35 @@ func WrapOpenAIFunc(
 		// Calculate latency
 		latencyMs := time.Since(startTime).Milliseconds()
 
-		// Audit the call (best effort)
-		go func() {
-			summary := ""
-			if len(result.Choices) > 0 {
-				content := result.Choices[0].Message.Content
-				if len(content) > 100 {
-					summary = content[:100]
-				} else {
-					summary = content
-				}
+		// Audit the call in the background (best effort)
+		summary := ""
+		if len(result.Choices) > 0 {
+			content := result.Choices[0].Message.Content
+			if len(content) > 100 {
+				summary = content[:100]
+			} else {
+				summary = content
 			}
+		}
 
-			tokenUsage := axonflow.TokenUsage{
-				PromptTokens:     result.Usage.PromptTokens,
-				CompletionTokens: result.Usage.CompletionTokens,
-				TotalTokens:      result.Usage.TotalTokens,
-			}
+		tokenUsage := axonflow.TokenUsage{
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+		}
 
-			if response.RequestID != "" {
-				_, _ = axonflowClient.AuditLLMCall(
-					response.RequestID,
-					summary,
-					"openai",
-					req.Model,
-					tokenUsage,
-					latencyMs,
-					nil,
-				)
-			}
-		}()
+		if response.RequestID != "" {
+			axonflowClient.AuditLLMCallAsync(
+				ctx,
+				response.RequestID,
+				summary,
+				"openai",
+				req.Model,
+				tokenUsage,
+				latencyMs,
+				nil,
+			)
+		}
 
 		return result, nil
 	}
@@ -0,0 +1,167 @@
+// Client lifecycle: asynchronous work and Close
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClientClosed is returned by calls made after Close.
+var ErrClientClosed = errors.New("axonflow: client closed")
+
+// ErrAuditQueueFull is reported (to the Observer and the log) when AuditLLMCallAsync
+// drops an audit because MaxPendingAudits audits are already in flight.
+var ErrAuditQueueFull = errors.New("axonflow: too many pending async audits")
+
+// lifecycle tracks background work owned by a client.
+type lifecycle struct {
+	mu     sync.Mutex // orders Close against starting background work
+	closed atomic.Bool
+
+	// ctx is cancelled when Close gives up waiting for pending work
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg      sync.WaitGroup
+	pending chan struct{} // semaphore bounding async audits
+
+	closeOnce sync.Once
+	closers   []func() // run by Close after pending work has finished
+}
+
+func newLifecycle(maxPending int) *lifecycle {
+	if maxPending <= 0 {
+		maxPending = 1000
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: make(chan struct{}, maxPending),
+	}
+}
+
+// start registers background work, which Close waits for. It reports false if the
+// client is closed.
+func (l *lifecycle) start() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed.Load() {
+		return false
+	}
+	l.wg.Add(1)
+	return true
+}
+
+// drainingKey marks the context of background work that may still send requests
+// while the client is closing.
+type drainingKey struct{}
+
+// checkOpen returns ErrClientClosed if the client has been closed, except for
+// background work started before Close, which is allowed to finish.
+func (c *AxonFlowClient) checkOpen(ctx context.Context) error {
+	if c.life.closed.Load() && ctx.Value(drainingKey{}) == nil {
+		return ErrClientClosed
+	}
+	return nil
+}
+
+// AuditLLMCallAsync is like AuditLLMCallContext but runs in the background and does
+// not report its result; failures are logged and reported to the Observer's
+// OnAuditFailure. ctx supplies values (such as a trace) but its cancellation is
+// ignored, since the caller has usually returned by the time the audit is sent.
+//
+// At most MaxPendingAudits audits run at once; further audits are dropped. Close waits
+// for pending audits to finish.
+func (c *AxonFlowClient) AuditLLMCallAsync(
+	ctx context.Context,
+	contextID string,
+	responseSummary string,
+	provider string,
+	model string,
+	tokenUsage TokenUsage,
+	latencyMs int64,
+	metadata map[string]interface{},
+) {
+	if !c.life.start() {
+		c.dropAudit(ctx, contextID, ErrClientClosed)
+		return
+	}
+
+	select {
+	case c.life.pending <- struct{}{}:
+	default:
+		c.life.wg.Done()
+		c.dropAudit(ctx, contextID, ErrAuditQueueFull)
+		return
+	}
+
+	// Cancelled only if Close times out
+	auditCtx, cancel := context.WithCancel(context.WithValue(context.WithoutCancel(ctx), drainingKey{}, true))
+	stop := context.AfterFunc(c.life.ctx, cancel)
+
+	go func() {
+		defer c.life.wg.Done()
+		defer func() { <-c.life.pending }()
+		defer cancel()
+		defer stop()
+
+		if _, err := c.AuditLLMCallContext(auditCtx, contextID, responseSummary, provider, model, tokenUsage, latencyMs, metadata); err != nil {
+			c.logger.WarnContext(auditCtx, "Async AxonFlow audit failed",
+				"context_id", contextID,
+				"error", err)
+		}
+	}()
+}
+
+func (c *AxonFlowClient) dropAudit(ctx context.Context, contextID string, err error) {
+	c.logger.WarnContext(ctx, "Dropping async AxonFlow audit",
+		"context_id", contextID,
+		"error", err)
+	c.observer.OnAuditFailure(ctx, ObserverEvent{RequestType: RequestTypeAudit, Err: err})
+}
+
+// Close shuts the client down. Calls made after Close fail with ErrClientClosed.
+// Close waits for pending async audits until ctx is done, then cancels any still
+// running, stops background workers and closes idle connections. It returns
+// ctx.Err() if pending audits had to be cancelled. Calling Close more than once is
+// safe; later calls return nil immediately.
+//
+// Clients obtained from a TenantPool share the pool's resources; Close does nothing
+// for them. Close the pool instead.
+func (c *AxonFlowClient) Close(ctx context.Context) error {
+	if c.pooled {
+		return nil
+	}
+
+	var err error
+	c.life.closeOnce.Do(func() {
+		c.life.mu.Lock()
+		c.life.closed.Store(true)
+		c.life.mu.Unlock()
+
+		done := make(chan struct{})
+		go func() {
+			c.life.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			err = ctx.Err()
+			c.life.cancel()
+			<-done
+		}
+		c.life.cancel()
+
+		for _, closer := range c.life.closers {
+			closer()
+		}
+		c.httpClient.CloseIdleConnections()
+		c.mapHttpClient.CloseIdleConnections()
+	})
+	return err
+}
@@ -0,0 +1,178 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloseRejectsLaterCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClientResponse{Success: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+	if _, err := client.ExecuteQuery("user", "q", "chat", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	// Even a cached query must not be served after Close
+	if _, err := client.ExecuteQuery("user", "q", "chat", nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed from ExecuteQuery, got %v", err)
+	}
+	if _, err := client.ListStaticPolicies(nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed from ListStaticPolicies, got %v", err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("expected repeated Close to return nil, got %v", err)
+	}
+}
+
+func TestClosedClientDoesNotFailOpen(t *testing.T) {
+	client := NewClient(AxonFlowConfig{
+		Endpoint:      "http://localhost:8080",
+		ClientID:      "test",
+		FailurePolicy: FailurePolicy{Default: FailOpen},
+	})
+	client.Close(context.Background())
+
+	if _, err := client.PreCheck("user", "q", nil, nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestCloseDrainsAsyncAudits(t *testing.T) {
+	var audits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&audits, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "audit_id": "a-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		client.AuditLLMCallAsync(ctx, "ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil)
+	}
+	cancel() // the caller's cancellation must not abort the audits
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer closeCancel()
+	if err := client.Close(closeCtx); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if n := atomic.LoadInt32(&audits); n != 3 {
+		t.Errorf("expected Close to wait for 3 audits, got %d", n)
+	}
+}
+
+func TestCloseDeadlineCancelsPendingAudits(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	obs := &recordingObserver{}
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test", Observer: obs})
+	client.AuditLLMCallAsync(context.Background(), "ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %v after its deadline", elapsed)
+	}
+	if _, ok := obs.last["audit_failure"]; !ok {
+		t.Error("expected the cancelled audit to be reported")
+	}
+}
+
+func TestAuditLLMCallAsyncBounded(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	}))
+	defer server.Close()
+
+	obs := &recordingObserver{}
+	client := NewClient(AxonFlowConfig{
+		Endpoint:         server.URL,
+		ClientID:         "test",
+		Observer:         obs,
+		MaxPendingAudits: 1,
+	})
+
+	client.AuditLLMCallAsync(context.Background(), "ctx-1", "s", "openai", "gpt-4", TokenUsage{}, 10, nil)
+	client.AuditLLMCallAsync(context.Background(), "ctx-2", "s", "openai", "gpt-4", TokenUsage{}, 10, nil)
+
+	if e, ok := obs.last["audit_failure"]; !ok || !errors.Is(e.Err, ErrAuditQueueFull) {
+		t.Errorf("expected the second audit to be dropped, got %+v (%v)", e, ok)
+	}
+
+	close(release)
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("unexpected close error: %v", err)
+	}
+
+	client.AuditLLMCallAsync(context.Background(), "ctx-3", "s", "openai", "gpt-4", TokenUsage{}, 10, nil)
+	if e := obs.last["audit_failure"]; !errors.Is(e.Err, ErrClientClosed) {
+		t.Errorf("expected audits after Close to be dropped, got %+v", e)
+	}
+}
+
+func TestCloseRacesAsyncAudits(t *testing.T) {
+	var closed, late int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&closed) == 1 {
+			atomic.AddInt32(&late, 1)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "audit_id": "a-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				client.AuditLLMCallAsync(context.Background(), "ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 1, nil)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	atomic.StoreInt32(&closed, 1)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&late); n != 0 {
+		t.Errorf("expected no audit after Close returned, got %d", n)
+	}
+}
@@ -0,0 +1,332 @@
+// Offline evaluation of static policies
+package axonflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// actionRank orders policy actions from least to most restrictive.
+var actionRank = map[PolicyAction]int{
+	ActionAllow:           0,
+	ActionLog:             1,
+	ActionWarn:            2,
+	ActionRedact:          3,
+	ActionRequireApproval: 4,
+	ActionBlock:           5,
+}
+
+// LocalEvaluatorConfig configures a LocalEvaluator.
+type LocalEvaluatorConfig struct {
+	// Category restricts the evaluator to one policy category (default: all)
+	Category PolicyCategory
+	// RefreshInterval is the time between policy reloads (default: 5m). Negative
+	// disables background refresh; call Refresh instead.
+	RefreshInterval time.Duration
+	// Policies are used until the first load succeeds, e.g. a copy saved while
+	// AxonFlow was reachable. If set, NewLocalEvaluator does not fail when the
+	// initial load does.
+	Policies []StaticPolicy
+}
+
+// LocalEvaluation is the result of evaluating text against the static policies.
+type LocalEvaluation struct {
+	// Action is the most restrictive action of the matched policies, or ActionAllow
+	Action PolicyAction
+	// Blocked is set when Action is ActionBlock or ActionRequireApproval
+	Blocked bool
+	// Matches lists the matched policies, most restrictive first
+	Matches []PolicyMatchInfo
+	// PoliciesEvaluated is the number of policies the text was checked against
+	PoliciesEvaluated int
+}
+
+// LocalEvaluatorStatus describes the policies loaded by a LocalEvaluator.
+type LocalEvaluatorStatus struct {
+	Policies  int              // Policies in use
+	Skipped   map[string]error // Policies whose pattern could not be compiled, by ID
+	LoadedAt  time.Time        // Time of the last successful load (zero if none)
+	LastError error            // Error of the last load, nil if it succeeded
+}
+
+// compiledPolicy is a static policy with its pattern compiled.
+type compiledPolicy struct {
+	policy StaticPolicy
+	re     *regexp.Regexp
+}
+
+// action returns the policy's action, taking an active override into account.
+func (p *compiledPolicy) action(now time.Time) PolicyAction {
+	if o := p.policy.Override; p.policy.HasOverride && o != nil && o.Active && (o.ExpiresAt == nil || now.Before(*o.ExpiresAt)) {
+		return PolicyAction(o.Action)
+	}
+	if p.policy.Action == "" {
+		return ActionBlock
+	}
+	return p.policy.Action
+}
+
+// LocalEvaluator evaluates text against a tenant's effective static policies in
+// memory. Policies are loaded with GetEffectiveStaticPolicies, their patterns compiled
+// once, and reloaded in the background. Use it to enforce PII and SQL injection rules
+// while AxonFlow is unreachable, or to reject obvious violations without a network
+// round trip (see PreCheck).
+//
+// Patterns are compiled with Go's regexp package (RE2 syntax). Policies using
+// features RE2 lacks, such as lookaheads, are skipped and listed in Status.
+//
+// A LocalEvaluator is safe for concurrent use. Close it, or the client, to stop the
+// background refresh.
+type LocalEvaluator struct {
+	client *AxonFlowClient
+	config LocalEvaluatorConfig
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	policies []*compiledPolicy
+	skipped  map[string]error
+	loadedAt time.Time
+	lastErr  error
+
+	cancel context.CancelFunc
+}
+
+// NewLocalEvaluator loads the effective static policies and returns an evaluator
+// for them. It fails if the policies cannot be loaded and config.Policies is empty.
+func (c *AxonFlowClient) NewLocalEvaluator(ctx context.Context, config LocalEvaluatorConfig) (*LocalEvaluator, error) {
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = 5 * time.Minute
+	}
+	e := &LocalEvaluator{client: c, config: config, now: time.Now}
+
+	if len(config.Policies) > 0 {
+		e.set(config.Policies)
+	}
+	if err := e.Refresh(ctx); err != nil {
+		if len(config.Policies) == 0 {
+			return nil, err
+		}
+		c.logger.WarnContext(ctx, "AxonFlow policies could not be loaded, evaluating the configured policies",
+			"error", err,
+			"policies", len(config.Policies))
+	}
+
+	refreshCtx, cancel := context.WithCancel(c.life.ctx)
+	e.cancel = cancel
+	if config.RefreshInterval > 0 {
+		go e.run(refreshCtx)
+	}
+	return e, nil
+}
+
+// Refresh reloads the policies now. On failure the previous policies stay in use.
+func (e *LocalEvaluator) Refresh(ctx context.Context) error {
+	var options *EffectivePoliciesOptions
+	if e.config.Category != "" {
+		options = &EffectivePoliciesOptions{Category: e.config.Category}
+	}
+	policies, err := e.client.GetEffectiveStaticPoliciesContext(ctx, options)
+
+	e.mu.Lock()
+	e.lastErr = err
+	e.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to load static policies: %w", err)
+	}
+
+	e.set(policies)
+	e.client.logger.DebugContext(ctx, "AxonFlow local policies loaded", "policies", len(policies))
+	return nil
+}
+
+// set compiles and installs policies, reusing compiled patterns of the current set.
+func (e *LocalEvaluator) set(policies []StaticPolicy) {
+	e.mu.RLock()
+	compiled := make(map[string]*regexp.Regexp, len(e.policies))
+	for _, p := range e.policies {
+		compiled[p.policy.Pattern] = p.re
+	}
+	e.mu.RUnlock()
+
+	next, skipped := compilePolicies(policies, compiled)
+	if len(skipped) > 0 {
+		e.client.logger.Warn("AxonFlow policies skipped by the local evaluator", "skipped", len(skipped))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policies = next
+	e.skipped = skipped
+	e.loadedAt = e.now()
+}
+
+// compilePolicies compiles the patterns of the enabled policies. compiled holds
+// already compiled patterns to reuse and may be nil. Policies whose pattern does not
+// compile are returned in skipped, by ID.
+func compilePolicies(policies []StaticPolicy, compiled map[string]*regexp.Regexp) (next []*compiledPolicy, skipped map[string]error) {
+	if compiled == nil {
+		compiled = map[string]*regexp.Regexp{}
+	}
+	next = make([]*compiledPolicy, 0, len(policies))
+	skipped = map[string]error{}
+	for _, p := range policies {
+		if !p.Enabled || p.Pattern == "" {
+			continue
+		}
+		re, ok := compiled[p.Pattern]
+		if !ok {
+			var err error
+			if re, err = regexp.Compile(p.Pattern); err != nil {
+				skipped[p.ID] = err
+				continue
+			}
+			compiled[p.Pattern] = re
+		}
+		next = append(next, &compiledPolicy{policy: p, re: re})
+	}
+	return next, skipped
+}
+
+// compiled returns the compiled policies in use.
+func (e *LocalEvaluator) compiled() []*compiledPolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policies
+}
+
+// run reloads the policies every RefreshInterval until ctx is done.
+func (e *LocalEvaluator) run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Refresh(ctx); err != nil && ctx.Err() == nil {
+				e.client.logger.WarnContext(ctx, "AxonFlow local policy refresh failed, keeping the current policies",
+					"error", err)
+			}
+		}
+	}
+}
+
+// Evaluate checks text against the loaded policies.
+func (e *LocalEvaluator) Evaluate(text string) *LocalEvaluation {
+	policies := e.compiled()
+
+	now := e.now()
+	result := &LocalEvaluation{Action: ActionAllow, PoliciesEvaluated: len(policies)}
+	for _, p := range policies {
+		if !p.re.MatchString(text) {
+			continue
+		}
+		action := p.action(now)
+		result.Matches = append(result.Matches, PolicyMatchInfo{
+			PolicyID:   p.policy.ID,
+			PolicyName: p.policy.Name,
+			Category:   string(p.policy.Category),
+			Severity:   string(p.policy.Severity),
+			Action:     string(action),
+		})
+		if actionRank[action] > actionRank[result.Action] {
+			result.Action = action
+		}
+	}
+
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return actionRank[PolicyAction(result.Matches[i].Action)] > actionRank[PolicyAction(result.Matches[j].Action)]
+	})
+	result.Blocked = result.Action == ActionBlock || result.Action == ActionRequireApproval
+	return result
+}
+
+// PreCheck is like AxonFlowClient.GetPolicyApprovedContextWithContext with local
+// evaluation in front of it. Queries the local policies block are rejected without
+// asking AxonFlow. When AxonFlow is unavailable the client's FailurePolicy still
+// applies: where it fails open, the local evaluation decides instead of approving
+// everything; fail-closed errors and fail-cached decisions are returned unchanged.
+// Results produced locally have Local set and no ContextID.
+func (e *LocalEvaluator) PreCheck(
+	ctx context.Context,
+	userToken string,
+	query string,
+	dataSources []string,
+	queryContext map[string]interface{},
+) (*PolicyApprovalResult, error) {
+	local := e.Evaluate(query)
+	if local.Blocked {
+		e.client.observer.OnPolicyBlock(ctx, ObserverEvent{RequestType: RequestTypePreCheck, BlockReason: local.blockReason()})
+		return local.approval(), nil
+	}
+
+	result, err := e.client.GetPolicyApprovedContextWithContext(ctx, userToken, query, dataSources, queryContext)
+	if err == nil && result.Fallback == FailOpen {
+		e.client.logger.WarnContext(ctx, "AxonFlow unavailable, using local policy evaluation",
+			"request_type", RequestTypePreCheck,
+			"action", local.Action)
+		return local.approval(), nil
+	}
+	return result, err
+}
+
+// approval converts a local evaluation into a pre-check result.
+func (l *LocalEvaluation) approval() *PolicyApprovalResult {
+	result := &PolicyApprovalResult{
+		Approved:          !l.Blocked,
+		RequiresRedaction: l.Action == ActionRedact,
+		ExpiresAt:         time.Now().Add(5 * time.Minute),
+		Local:             true,
+	}
+	for _, m := range l.Matches {
+		result.Policies = append(result.Policies, m.PolicyID)
+	}
+	if l.Blocked {
+		result.BlockReason = l.blockReason()
+	}
+	return result
+}
+
+func (l *LocalEvaluation) blockReason() string {
+	if len(l.Matches) == 0 {
+		return ""
+	}
+	return "blocked by local policy: " + l.Matches[0].PolicyName
+}
+
+// Status reports the loaded policies and the outcome of the last load.
+func (e *LocalEvaluator) Status() LocalEvaluatorStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	skipped := make(map[string]error, len(e.skipped))
+	for id, err := range e.skipped {
+		skipped[id] = err
+	}
+	return LocalEvaluatorStatus{
+		Policies:  len(e.policies),
+		Skipped:   skipped,
+		LoadedAt:  e.loadedAt,
+		LastError: e.lastErr,
+	}
+}
+
+// Policies returns the policies in use.
+func (e *LocalEvaluator) Policies() []StaticPolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	policies := make([]StaticPolicy, len(e.policies))
+	for i, p := range e.policies {
+		policies[i] = p.policy
+	}
+	return policies
+}
+
+// Close stops the background refresh. The evaluator keeps working with the
+// policies it has.
+func (e *LocalEvaluator) Close() {
+	e.cancel()
+}
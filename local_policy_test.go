@@ -0,0 +1,161 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testPolicies are static policies for local evaluation tests.
+var testPolicies = []StaticPolicy{
+	{ID: "pii-ssn", Name: "US SSN", Category: CategoryPIIUS, Severity: SeverityCritical,
+		Pattern: `\b\d{3}-\d{2}-\d{4}\b`, Action: ActionRedact, Enabled: true},
+	{ID: "sqli-drop", Name: "SQL DROP", Category: CategorySecuritySQLI, Severity: SeverityHigh,
+		Pattern: `(?i);\s*drop\s+table`, Action: ActionBlock, Enabled: true},
+	{ID: "lookahead", Name: "PCRE only", Pattern: `(?=foo)`, Action: ActionBlock, Enabled: true},
+	{ID: "disabled", Name: "Disabled", Pattern: `.`, Action: ActionBlock, Enabled: false},
+}
+
+// newPolicyServer serves policies as the effective static policies and, with agent
+// set, answers pre-checks as approved.
+func newPolicyServer(t *testing.T, policies *atomic.Value, preChecks *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/static-policies/effective":
+			json.NewEncoder(w).Encode(effectivePoliciesResponse{Static: policies.Load().([]StaticPolicy)})
+		case "/api/policy/pre-check":
+			atomic.AddInt32(preChecks, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"context_id": "ctx-1", "approved": true})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLocalEvaluator(t *testing.T) {
+	var policies atomic.Value
+	policies.Store(testPolicies)
+	server := newPolicyServer(t, &policies, new(int32))
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+
+	e, err := client.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{RefreshInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := e.Status()
+	if status.Policies != 2 || status.Skipped["lookahead"] == nil || status.LoadedAt.IsZero() {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	result := e.Evaluate("SSN 123-45-6789'; DROP TABLE users")
+	if result.Action != ActionBlock || !result.Blocked || len(result.Matches) != 2 || result.Matches[0].PolicyID != "sqli-drop" {
+		t.Errorf("expected a block with the SQL policy first, got %+v", result)
+	}
+	if m := result.Matches[1]; m.PolicyID != "pii-ssn" || m.Action != "redact" || m.Category != "pii-us" || m.Severity != "critical" {
+		t.Errorf("unexpected PII match: %+v", m)
+	}
+
+	if result := e.Evaluate("SSN 123-45-6789"); result.Action != ActionRedact || result.Blocked {
+		t.Errorf("expected redact, got %+v", result)
+	}
+	if result := e.Evaluate("hello"); result.Action != ActionAllow || len(result.Matches) != 0 || result.PoliciesEvaluated != 2 {
+		t.Errorf("expected allow, got %+v", result)
+	}
+}
+
+func TestLocalEvaluatorOverrideAndRefresh(t *testing.T) {
+	var policies atomic.Value
+	policies.Store(testPolicies[:1])
+	server := newPolicyServer(t, &policies, new(int32))
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+
+	e, err := client.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{RefreshInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	overridden := testPolicies[0]
+	overridden.HasOverride = true
+	overridden.Override = &PolicyOverride{Action: OverrideActionBlock, Active: true}
+	policies.Store([]StaticPolicy{overridden})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for e.Evaluate("123-45-6789").Action != ActionBlock {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the refreshed override")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Expired overrides no longer apply
+	expired := time.Now().Add(-time.Minute)
+	overridden.Override.ExpiresAt = &expired
+	e.set([]StaticPolicy{overridden})
+	if action := e.Evaluate("123-45-6789").Action; action != ActionRedact {
+		t.Errorf("expected the policy action after the override expired, got %s", action)
+	}
+}
+
+func TestLocalEvaluatorPreCheck(t *testing.T) {
+	var policies atomic.Value
+	policies.Store(testPolicies)
+	var preChecks int32
+	server := newPolicyServer(t, &policies, &preChecks)
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+	e, _ := client.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{RefreshInterval: -1})
+
+	// Obvious violations are rejected without a round trip
+	result, err := e.PreCheck(context.Background(), "user", "x'; drop table users", nil, nil)
+	if err != nil || result.Approved || !result.Local || result.BlockReason != "blocked by local policy: SQL DROP" {
+		t.Errorf("expected a local block, got %+v, %v", result, err)
+	}
+	if preChecks != 0 {
+		t.Errorf("expected no pre-check request, got %d", preChecks)
+	}
+
+	result, err = e.PreCheck(context.Background(), "user", "hello", nil, nil)
+	if err != nil || !result.Approved || result.Local || result.ContextID != "ctx-1" {
+		t.Errorf("expected AxonFlow's result, got %+v, %v", result, err)
+	}
+
+	// With AxonFlow down, the local policies decide
+	down, _ := New(downURL(), WithCredentials("tenant", "secret"), WithoutRetry(),
+		WithFailurePolicy(FailurePolicy{PreCheck: FailOpen}))
+	offline, err := down.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{Policies: testPolicies, RefreshInterval: -1})
+	if err != nil {
+		t.Fatalf("expected the configured policies to be used, got %v", err)
+	}
+	if offline.Status().LastError == nil {
+		t.Error("expected the failed load to be reported")
+	}
+	result, err = offline.PreCheck(context.Background(), "user", "SSN 123-45-6789", nil, nil)
+	if err != nil || !result.Approved || !result.Local || !result.RequiresRedaction || result.Fallback != "" {
+		t.Errorf("expected a local redact result instead of failing open, got %+v, %v", result, err)
+	}
+
+	// Fail-closed stays closed: only local blocks are decided without AxonFlow
+	closed, _ := New(downURL(), WithCredentials("tenant", "secret"), WithoutRetry(),
+		WithFailurePolicy(FailurePolicy{PreCheck: FailClosed}))
+	defer closed.Close(context.Background())
+	strict, _ := closed.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{Policies: testPolicies, RefreshInterval: -1})
+	if result, err := strict.PreCheck(context.Background(), "user", "hello", nil, nil); err == nil {
+		t.Errorf("expected the fail-closed error, got %+v", result)
+	}
+	result, err = strict.PreCheck(context.Background(), "user", "x'; drop table users", nil, nil)
+	if err != nil || result.Approved || !result.Local {
+		t.Errorf("expected a local block, got %+v, %v", result, err)
+	}
+
+	if _, err := down.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{}); err == nil {
+		t.Error("expected an error without policies")
+	}
+}
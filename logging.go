@@ -0,0 +1,48 @@
+// Structured logging for the AxonFlow client
+package axonflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// newLogger returns the logger used by a client: config.Logger if set; otherwise a
+// text logger at debug level writing to the standard logger's output when Debug is
+// set; otherwise a logger that discards everything.
+func newLogger(config AxonFlowConfig) *slog.Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+	if config.Debug {
+		return slog.New(slog.NewTextHandler(log.Writer(), &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return slog.New(discardHandler{})
+}
+
+// discardHandler is a slog.Handler that is never enabled.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// debugf logs a formatted message at debug level. The message is only formatted when
+// debug logging is enabled.
+func (c *AxonFlowClient) debugf(format string, args ...interface{}) {
+	if c.logger.Enabled(context.Background(), slog.LevelDebug) {
+		c.logger.Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+// content returns a log attribute for query or response text. Unless LogContent is
+// set, only the length is logged, so prompts and results (which may contain PII)
+// never reach the logs.
+func (c *AxonFlowClient) content(key, text string) slog.Attr {
+	if c.config.LogContent {
+		return slog.String(key, text)
+	}
+	return slog.String(key, fmt.Sprintf("[redacted, %d bytes]", len(text)))
+}
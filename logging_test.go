@@ -0,0 +1,126 @@
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const secretQuery = "my SSN is 123-45-6789"
+
+func newLoggingTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-42")
+		json.NewEncoder(w).Encode(ClientResponse{Success: true, Result: "the SSN on file is 123-45-6789"})
+	}))
+}
+
+func TestLoggerRedactsContentByDefault(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Logger:   slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	if _, err := client.ExecuteQuery("user", secretQuery, "chat", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "123-45-6789") {
+		t.Errorf("expected query and result to be redacted, got:\n%s", out)
+	}
+	for _, want := range []string{`"request_type":"chat"`, `"request_id":"req-42"`, `"status":200`, `"attempt":1`, `"duration"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected log to contain %s, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoggerLogContent(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(AxonFlowConfig{
+		Endpoint:   server.URL,
+		ClientID:   "test",
+		Logger:     slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogContent: true,
+	})
+
+	if _, err := client.ExecuteQuery("user", secretQuery, "chat", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), secretQuery) {
+		t.Errorf("expected query in log with LogContent, got:\n%s", buf.String())
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Logger:   slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})),
+	})
+
+	client.ExecuteQuery("user", "hello", "chat", nil)
+	if buf.Len() != 0 {
+		t.Errorf("expected no debug output at info level, got:\n%s", buf.String())
+	}
+}
+
+func TestNoLoggingWithoutDebug(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	out := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(out)
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+	client.ExecuteQuery("user", secretQuery, "chat", nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output without Debug or Logger, got:\n%s", buf.String())
+	}
+	if client.logger.Enabled(context.Background(), slog.LevelError) {
+		t.Error("expected the default logger to be disabled")
+	}
+}
+
+func TestDebugLogsToStandardLogger(t *testing.T) {
+	server := newLoggingTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	out := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(out)
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test", Debug: true})
+	client.ExecuteQuery("user", secretQuery, "chat", nil)
+
+	logged := buf.String()
+	if !strings.Contains(logged, "level=DEBUG") || !strings.Contains(logged, "request_type=chat") {
+		t.Errorf("expected debug logs with Debug set, got:\n%s", logged)
+	}
+	if strings.Contains(logged, "123-45-6789") {
+		t.Errorf("expected content to be redacted, got:\n%s", logged)
+	}
+}
@@ -0,0 +1,319 @@
+// In-memory metrics collector with a Prometheus text-format handler
+package axonflow
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request duration
+// histogram used by NewMetrics.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics is an Observer that keeps counters and latency histograms in memory and
+// serves them in the Prometheus text exposition format. It has no dependencies beyond
+// the standard library. It is safe for concurrent use.
+//
+// Endpoint labels use the URL path with ID-like segments (those containing a digit,
+// other than version segments such as "v1") replaced by ":id", to keep the number of
+// series bounded.
+type Metrics struct {
+	requests    *counterVec
+	duration    *histogramVec
+	retries     *counterVec
+	cacheHits   *counterVec
+	cacheMisses *counterVec
+	failOpen    *counterVec
+	blocks      *counterVec
+	auditErrors *counterVec
+	collapsed   *counterVec
+	// tenantRequests counts calls by clients of a TenantPool
+	tenantRequests *counterVec
+}
+
+var _ Observer = (*Metrics)(nil)
+
+// NewMetrics creates a metrics collector. Pass it as AxonFlowConfig.Observer and
+// expose Handler on your metrics endpoint.
+func NewMetrics() *Metrics {
+	return NewMetricsWithBuckets(DefaultLatencyBuckets)
+}
+
+// NewMetricsWithBuckets is like NewMetrics with custom latency histogram bucket upper
+// bounds, in seconds.
+func NewMetricsWithBuckets(buckets []float64) *Metrics {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Metrics{
+		requests: newCounterVec("axonflow_requests_total",
+			"AxonFlow API calls by method, endpoint and final HTTP status.", "method", "endpoint", "status"),
+		duration: newHistogramVec("axonflow_request_duration_seconds",
+			"Latency of AxonFlow API calls, including retries.", b, "method", "endpoint"),
+		retries: newCounterVec("axonflow_retries_total",
+			"Retried AxonFlow API call attempts.", "method", "endpoint"),
+		cacheHits: newCounterVec("axonflow_cache_hits_total",
+			"ExecuteQuery responses served from the cache.", "request_type"),
+		cacheMisses: newCounterVec("axonflow_cache_misses_total",
+			"ExecuteQuery cache lookups that missed.", "request_type"),
+		failOpen: newCounterVec("axonflow_fail_open_total",
+			"Calls allowed by the failure policy while AxonFlow was unavailable.", "request_type", "mode"),
+		blocks: newCounterVec("axonflow_policy_blocks_total",
+			"Requests blocked by AxonFlow policies.", "request_type"),
+		auditErrors: newCounterVec("axonflow_audit_failures_total",
+			"Failed or dropped audit calls."),
+		collapsed: newCounterVec("axonflow_requests_collapsed_total",
+			"Queries and pre-checks that shared an identical call in flight (SingleFlight).", "request_type"),
+		tenantRequests: newCounterVec("axonflow_tenant_requests_total",
+			"AxonFlow API calls by TenantPool clients, by tenant and final HTTP status.", "tenant", "status"),
+	}
+}
+
+// OnRequestStart implements Observer.
+func (m *Metrics) OnRequestStart(context.Context, ObserverEvent) {}
+
+// OnRequestEnd implements Observer.
+func (m *Metrics) OnRequestEnd(_ context.Context, e ObserverEvent) {
+	endpoint := metricEndpoint(e.Endpoint)
+	status := "error"
+	if e.Status != 0 {
+		status = strconv.Itoa(e.Status)
+	}
+	m.requests.inc(e.Method, endpoint, status)
+	if e.TenantID != "" {
+		m.tenantRequests.inc(e.TenantID, status)
+	}
+	m.duration.observe(e.Duration.Seconds(), e.Method, endpoint)
+}
+
+// OnRetry implements Observer.
+func (m *Metrics) OnRetry(_ context.Context, e ObserverEvent) {
+	m.retries.inc(e.Method, metricEndpoint(e.Endpoint))
+}
+
+// OnCacheHit implements Observer.
+func (m *Metrics) OnCacheHit(_ context.Context, e ObserverEvent) {
+	m.cacheHits.inc(e.RequestType)
+}
+
+// OnCacheMiss implements Observer.
+func (m *Metrics) OnCacheMiss(_ context.Context, e ObserverEvent) {
+	m.cacheMisses.inc(e.RequestType)
+}
+
+// OnFailOpen implements Observer.
+func (m *Metrics) OnFailOpen(_ context.Context, e ObserverEvent) {
+	m.failOpen.inc(e.RequestType, string(e.Fallback))
+}
+
+// OnPolicyBlock implements Observer.
+func (m *Metrics) OnPolicyBlock(_ context.Context, e ObserverEvent) {
+	m.blocks.inc(e.RequestType)
+}
+
+// OnAuditFailure implements Observer.
+func (m *Metrics) OnAuditFailure(context.Context, ObserverEvent) {
+	m.auditErrors.inc()
+}
+
+// OnRequestCollapsed implements Observer.
+func (m *Metrics) OnRequestCollapsed(_ context.Context, e ObserverEvent) {
+	m.collapsed.inc(e.RequestType)
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	m.requests.write(bw)
+	m.duration.write(bw)
+	m.retries.write(bw)
+	m.cacheHits.write(bw)
+	m.cacheMisses.write(bw)
+	m.failOpen.write(bw)
+	m.blocks.write(bw)
+	m.auditErrors.write(bw)
+	m.collapsed.write(bw)
+	m.tenantRequests.write(bw)
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler serving the metrics for Prometheus to scrape.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w)
+	})
+}
+
+// metricEndpoint replaces ID-like path segments with ":id".
+func metricEndpoint(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if isVersionSegment(s) {
+			continue
+		}
+		for _, r := range s {
+			if unicode.IsDigit(r) {
+				segments[i] = ":id"
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isVersionSegment(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// counterVec is a counter partitioned by label values.
+type counterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+	series map[string][]string
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+		series: make(map[string][]string),
+	}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.series[key]; !ok {
+		c.series[key] = labelValues
+	}
+	c.values[key]++
+}
+
+func (c *counterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	keys := make([]string, 0, len(c.series))
+	for k := range c.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, c.series[key]), formatFloat(c.values[key]))
+	}
+}
+
+// histogramVec is a histogram partitioned by label values.
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+}
+
+func (h *histogramVec) observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *histogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	labels := append(append([]string(nil), h.labels...), "le")
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			values := append(append([]string(nil), s.labelValues...), formatFloat(upper))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), cumulative)
+		}
+		values := append(append([]string(nil), s.labelValues...), "+Inf")
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, s.labelValues), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues), s.count)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,106 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsPrometheusOutput(t *testing.T) {
+	m := NewMetricsWithBuckets([]float64{0.1, 1})
+	ctx := context.Background()
+
+	m.OnRequestEnd(ctx, ObserverEvent{Method: "GET", Endpoint: "/api/v1/static-policies/pol-123", Status: 200, Duration: 50 * time.Millisecond})
+	m.OnRequestEnd(ctx, ObserverEvent{Method: "GET", Endpoint: "/api/v1/static-policies/pol-456", Status: 200, Duration: 500 * time.Millisecond})
+	m.OnRequestEnd(ctx, ObserverEvent{Method: "POST", Endpoint: "/api/request", Err: errors.New("refused"), Duration: 2 * time.Second})
+	m.OnRetry(ctx, ObserverEvent{Method: "POST", Endpoint: "/api/request"})
+	m.OnCacheHit(ctx, ObserverEvent{RequestType: "chat"})
+	m.OnCacheMiss(ctx, ObserverEvent{RequestType: "chat"})
+	m.OnFailOpen(ctx, ObserverEvent{RequestType: "sql", Fallback: FailOpen})
+	m.OnPolicyBlock(ctx, ObserverEvent{RequestType: "chat"})
+	m.OnAuditFailure(ctx, ObserverEvent{})
+
+	var out strings.Builder
+	if err := m.WritePrometheus(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := out.String()
+
+	for _, want := range []string{
+		"# TYPE axonflow_requests_total counter",
+		`axonflow_requests_total{method="GET",endpoint="/api/v1/static-policies/:id",status="200"} 2`,
+		`axonflow_requests_total{method="POST",endpoint="/api/request",status="error"} 1`,
+		"# TYPE axonflow_request_duration_seconds histogram",
+		`axonflow_request_duration_seconds_bucket{method="GET",endpoint="/api/v1/static-policies/:id",le="0.1"} 1`,
+		`axonflow_request_duration_seconds_bucket{method="GET",endpoint="/api/v1/static-policies/:id",le="1"} 2`,
+		`axonflow_request_duration_seconds_bucket{method="GET",endpoint="/api/v1/static-policies/:id",le="+Inf"} 2`,
+		`axonflow_request_duration_seconds_sum{method="GET",endpoint="/api/v1/static-policies/:id"} 0.55`,
+		`axonflow_request_duration_seconds_count{method="GET",endpoint="/api/v1/static-policies/:id"} 2`,
+		`axonflow_request_duration_seconds_bucket{method="POST",endpoint="/api/request",le="1"} 0`,
+		`axonflow_request_duration_seconds_bucket{method="POST",endpoint="/api/request",le="+Inf"} 1`,
+		`axonflow_retries_total{method="POST",endpoint="/api/request"} 1`,
+		`axonflow_cache_hits_total{request_type="chat"} 1`,
+		`axonflow_cache_misses_total{request_type="chat"} 1`,
+		`axonflow_fail_open_total{request_type="sql",mode="fail-open"} 1`,
+		`axonflow_policy_blocks_total{request_type="chat"} 1`,
+		"axonflow_audit_failures_total 1",
+	} {
+		if !strings.Contains(text, want+"\n") {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestMetricEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/api/request":                         "/api/request",
+		"/api/v1/static-policies":              "/api/v1/static-policies",
+		"/api/v1/static-policies/pol-1/toggle": "/api/v1/static-policies/:id/toggle",
+		"/api/v1/executions/3f2a-9b":           "/api/v1/executions/:id",
+		"/api/v1/budgets/monthly":              "/api/v1/budgets/monthly",
+	}
+	for in, want := range tests {
+		if got := metricEndpoint(in); got != want {
+			t.Errorf("metricEndpoint(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	m := NewMetrics()
+	m.OnPolicyBlock(context.Background(), ObserverEvent{RequestType: "a\"b\\c\nd"})
+
+	var out strings.Builder
+	m.WritePrometheus(&out)
+	if !strings.Contains(out.String(), `request_type="a\"b\\c\nd"`) {
+		t.Errorf("expected escaped label, got:\n%s", out.String())
+	}
+}
+
+func TestMetricsHandlerWithClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClientResponse{Success: true})
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test", Observer: metrics})
+	client.ExecuteQuery("user", "query", "chat", nil)
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `axonflow_requests_total{method="POST",endpoint="/api/request",status="200"} 1`) {
+		t.Errorf("expected request counter, got:\n%s", body)
+	}
+}
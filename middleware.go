@@ -0,0 +1,80 @@
+// Request/response middleware for AxonFlow API calls
+package axonflow
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the RoundTripper that sends AxonFlow API requests. It sees every
+// attempt of every call made by the client, after authentication headers have been
+// added, and can add headers, rewrite the request, observe timings or inspect the
+// response.
+//
+// Requests are built afresh for each attempt (including retries), so a middleware may
+// modify the request it receives in place.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to the http.RoundTripper interface.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// HeaderMiddleware returns a Middleware that sets the given headers on every request,
+// replacing any existing values.
+func HeaderMiddleware(headers http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// chain wraps base in middleware so that middleware[0] is the outermost, i.e. sees
+// each request first and each response last.
+func chain(base http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	rt := base
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			rt = middleware[i](rt)
+		}
+	}
+	return rt
+}
+
+// newHTTPClients creates the client used for regular calls and the one used for MAP
+// operations from config. Both share one transport chain.
+func newHTTPClients(config AxonFlowConfig) (httpClient, mapHTTPClient *http.Client) {
+	base := &http.Client{}
+	if config.HTTPClient != nil {
+		// Copy so that the caller's client is left untouched
+		*base = *config.HTTPClient
+	}
+
+	transport := base.Transport
+	if transport == nil {
+		transport = config.Transport
+	}
+	if config.TLS != nil {
+		transport = tlsTransport(transport, config.TLS)
+	} else if transport == nil {
+		transport = defaultTransport()
+	}
+	base.Transport = chain(transport, config.Middleware)
+
+	httpClient = base
+	if httpClient.Timeout == 0 {
+		httpClient.Timeout = config.Timeout
+	}
+
+	mapHTTPClient = &http.Client{}
+	*mapHTTPClient = *base
+	mapHTTPClient.Timeout = config.MapTimeout
+
+	return httpClient, mapHTTPClient
+}
@@ -0,0 +1,156 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrderAndHeaders(t *testing.T) {
+	var gotCorrelation, gotTenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCorrelation = r.Header.Get("X-Correlation-ID")
+		gotTenant = r.Header.Get("X-Tenant-ID")
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" request")
+				resp, err := next.RoundTrip(req)
+				order = append(order, name+" response")
+				return resp, err
+			})
+		}
+	}
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "tenant-a",
+		Middleware: []Middleware{
+			trace("outer"),
+			HeaderMiddleware(http.Header{"X-Correlation-ID": {"abc-123"}}),
+			trace("inner"),
+		},
+	})
+
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotCorrelation != "abc-123" {
+		t.Errorf("expected correlation header, got %q", gotCorrelation)
+	}
+	if gotTenant != "tenant-a" {
+		t.Errorf("expected auth headers to be added before middleware, got tenant %q", gotTenant)
+	}
+
+	expected := "outer request,inner request,inner response,outer response"
+	if got := strings.Join(order, ","); got != expected {
+		t.Errorf("expected order %q, got %q", expected, got)
+	}
+}
+
+func TestMiddlewareSeesEveryRetryAttempt(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	defer server.Close()
+
+	attempts := 0
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+		Middleware: []Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				return next.RoundTrip(req)
+			})
+		}},
+	})
+
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected middleware to see 2 attempts, got %d", attempts)
+	}
+}
+
+func TestMiddlewareAppliesToMapRequests(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Custom")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint:   server.URL,
+		ClientID:   "test",
+		Middleware: []Middleware{HeaderMiddleware(http.Header{"X-Custom": {"yes"}})},
+	})
+
+	client.GeneratePlanContext(context.Background(), "query", "travel")
+	if got != "yes" {
+		t.Errorf("expected MAP request to carry middleware header, got %q", got)
+	}
+}
+
+func TestCustomTransport(t *testing.T) {
+	var used bool
+	client := NewClient(AxonFlowConfig{
+		Endpoint: "http://axonflow.invalid",
+		Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			used = true
+			rec := httptest.NewRecorder()
+			rec.WriteHeader(http.StatusOK)
+			rec.WriteString(`{"status": "healthy"}`)
+			return rec.Result(), nil
+		}),
+	})
+
+	if err := client.HealthCheck(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !used {
+		t.Error("expected the configured transport to be used")
+	}
+}
+
+func TestCustomHTTPClient(t *testing.T) {
+	base := &http.Client{Timeout: 5 * time.Second}
+	client := NewClient(AxonFlowConfig{
+		Endpoint:   "http://localhost:8080",
+		HTTPClient: base,
+		MapTimeout: time.Minute,
+		Middleware: []Middleware{HeaderMiddleware(http.Header{"X-A": {"1"}})},
+	})
+
+	if client.httpClient == base {
+		t.Error("expected the caller's client to be copied")
+	}
+	if base.Transport != nil {
+		t.Error("expected the caller's client to be left untouched")
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected the caller's timeout, got %v", client.httpClient.Timeout)
+	}
+	if client.mapHttpClient.Timeout != time.Minute {
+		t.Errorf("expected MapTimeout for MAP client, got %v", client.mapHttpClient.Timeout)
+	}
+}
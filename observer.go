@@ -0,0 +1,141 @@
+// Observability hooks for AxonFlow API calls
+package axonflow
+
+import (
+	"context"
+	"time"
+)
+
+// ObserverEvent describes something that happened during a call. Fields that do not
+// apply to a callback are left at their zero value.
+type ObserverEvent struct {
+	// Method is the HTTP method (request callbacks only)
+	Method string
+	// Endpoint is the URL path of the AxonFlow API (request callbacks only)
+	Endpoint string
+	// RequestType is the governance request type ("chat", "sql", "mcp-query",
+	// "pre-check", "audit", ...), empty for management calls
+	RequestType string
+	// Attempt is the attempt number, starting at 1
+	Attempt int
+	// Status is the HTTP status of the last response, or 0 if none was received
+	Status int
+	// Duration is the time taken: the whole call for OnRequestEnd, the failed attempt
+	// for OnRetry
+	Duration time.Duration
+	// Err is the error, if any
+	Err error
+	// TraceID and SpanID identify the call in the W3C traceparent header sent to AxonFlow
+	TraceID string
+	SpanID  string
+	// BlockReason is the reason given for a policy block
+	BlockReason string
+	// PolicyInfo is the policy evaluation metadata of an ExecuteQuery response
+	PolicyInfo *PolicyEvaluationInfo
+	// ConnectorPolicyInfo is the policy evaluation metadata of an MCP query response
+	ConnectorPolicyInfo *PolicyInfo
+	// Fallback is the failure mode that produced the result (OnFailOpen only)
+	Fallback FailureMode
+	// TenantID is the tenant of a client obtained from a TenantPool, empty otherwise
+	TenantID string
+}
+
+// Observer receives callbacks for every call made by a client, e.g. to record metrics
+// or trace spans. Callbacks run synchronously on the calling goroutine and must not
+// block. Embed NopObserver to implement only some of them.
+type Observer interface {
+	// OnRequestStart is called before an HTTP call to AxonFlow, once per call
+	OnRequestStart(ctx context.Context, e ObserverEvent)
+	// OnRequestEnd is called when the call finishes, after any retries
+	OnRequestEnd(ctx context.Context, e ObserverEvent)
+	// OnRetry is called after a failed attempt that will be retried
+	OnRetry(ctx context.Context, e ObserverEvent)
+	// OnCacheHit and OnCacheMiss report ExecuteQuery response cache lookups
+	OnCacheHit(ctx context.Context, e ObserverEvent)
+	OnCacheMiss(ctx context.Context, e ObserverEvent)
+	// OnFailOpen is called when AxonFlow was unavailable and the FailurePolicy let the
+	// call proceed, either failing open or with a cached decision (see e.Fallback)
+	OnFailOpen(ctx context.Context, e ObserverEvent)
+	// OnPolicyBlock is called when AxonFlow blocks a query, pre-check or MCP query
+	OnPolicyBlock(ctx context.Context, e ObserverEvent)
+	// OnAuditFailure is called when AuditLLMCall fails or its record is dropped
+	OnAuditFailure(ctx context.Context, e ObserverEvent)
+	// OnRequestCollapsed is called when a query or pre-check shared an identical call
+	// already in flight instead of sending its own (see AxonFlowConfig.SingleFlight)
+	OnRequestCollapsed(ctx context.Context, e ObserverEvent)
+}
+
+// NopObserver implements Observer with callbacks that do nothing.
+type NopObserver struct{}
+
+func (NopObserver) OnRequestStart(context.Context, ObserverEvent)     {}
+func (NopObserver) OnRequestEnd(context.Context, ObserverEvent)       {}
+func (NopObserver) OnRetry(context.Context, ObserverEvent)            {}
+func (NopObserver) OnCacheHit(context.Context, ObserverEvent)         {}
+func (NopObserver) OnCacheMiss(context.Context, ObserverEvent)        {}
+func (NopObserver) OnFailOpen(context.Context, ObserverEvent)         {}
+func (NopObserver) OnPolicyBlock(context.Context, ObserverEvent)      {}
+func (NopObserver) OnAuditFailure(context.Context, ObserverEvent)     {}
+func (NopObserver) OnRequestCollapsed(context.Context, ObserverEvent) {}
+
+// MultiObserver returns an Observer that forwards every callback to each of observers
+// in order.
+func MultiObserver(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) OnRequestStart(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnRequestStart(ctx, e)
+	}
+}
+
+func (m multiObserver) OnRequestEnd(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnRequestEnd(ctx, e)
+	}
+}
+
+func (m multiObserver) OnRetry(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnRetry(ctx, e)
+	}
+}
+
+func (m multiObserver) OnCacheHit(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnCacheHit(ctx, e)
+	}
+}
+
+func (m multiObserver) OnCacheMiss(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnCacheMiss(ctx, e)
+	}
+}
+
+func (m multiObserver) OnFailOpen(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnFailOpen(ctx, e)
+	}
+}
+
+func (m multiObserver) OnPolicyBlock(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnPolicyBlock(ctx, e)
+	}
+}
+
+func (m multiObserver) OnAuditFailure(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnAuditFailure(ctx, e)
+	}
+}
+
+func (m multiObserver) OnRequestCollapsed(ctx context.Context, e ObserverEvent) {
+	for _, o := range m {
+		o.OnRequestCollapsed(ctx, e)
+	}
+}
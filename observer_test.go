@@ -0,0 +1,179 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordingObserver records the callbacks it receives.
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+	last   map[string]ObserverEvent
+}
+
+func (o *recordingObserver) record(name string, e ObserverEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, name)
+	if o.last == nil {
+		o.last = map[string]ObserverEvent{}
+	}
+	o.last[name] = e
+}
+
+func (o *recordingObserver) OnRequestStart(_ context.Context, e ObserverEvent) { o.record("start", e) }
+func (o *recordingObserver) OnRequestEnd(_ context.Context, e ObserverEvent)   { o.record("end", e) }
+func (o *recordingObserver) OnRetry(_ context.Context, e ObserverEvent)        { o.record("retry", e) }
+func (o *recordingObserver) OnCacheHit(_ context.Context, e ObserverEvent)     { o.record("cache_hit", e) }
+func (o *recordingObserver) OnCacheMiss(_ context.Context, e ObserverEvent) {
+	o.record("cache_miss", e)
+}
+func (o *recordingObserver) OnFailOpen(_ context.Context, e ObserverEvent)    { o.record("fail_open", e) }
+func (o *recordingObserver) OnPolicyBlock(_ context.Context, e ObserverEvent) { o.record("block", e) }
+func (o *recordingObserver) OnAuditFailure(_ context.Context, e ObserverEvent) {
+	o.record("audit_failure", e)
+}
+func (o *recordingObserver) OnRequestCollapsed(_ context.Context, e ObserverEvent) {
+	o.record("collapsed", e)
+}
+
+func (o *recordingObserver) sequence() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := ""
+	for i, e := range o.events {
+		if i > 0 {
+			out += ","
+		}
+		out += e
+	}
+	return out
+}
+
+func TestObserverExecuteQuery(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ClientResponse{
+			Success:     true,
+			Blocked:     true,
+			BlockReason: "PII detected",
+			PolicyInfo:  &PolicyEvaluationInfo{PoliciesEvaluated: []string{"pii"}},
+		})
+	}))
+	defer server.Close()
+
+	obs := &recordingObserver{}
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+		Observer: obs,
+	})
+
+	if _, err := client.ExecuteQuery("user", "query", "chat", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := obs.sequence(), "cache_miss,start,retry,end,block"; got != want {
+		t.Fatalf("expected callbacks %q, got %q", want, got)
+	}
+
+	end := obs.last["end"]
+	if end.Method != "POST" || end.Endpoint != "/api/request" || end.RequestType != "chat" {
+		t.Errorf("unexpected request info: %+v", end)
+	}
+	if end.Status != 200 || end.Attempt != 2 || end.Duration <= 0 || end.Err != nil {
+		t.Errorf("unexpected outcome: %+v", end)
+	}
+	if end.TraceID == "" || end.SpanID == "" {
+		t.Errorf("expected trace and span IDs, got %+v", end)
+	}
+	if retry := obs.last["retry"]; retry.Status != 503 || retry.Attempt != 1 {
+		t.Errorf("unexpected retry event: %+v", retry)
+	}
+
+	block := obs.last["block"]
+	if block.BlockReason != "PII detected" || block.PolicyInfo == nil || block.PolicyInfo.PoliciesEvaluated[0] != "pii" {
+		t.Errorf("unexpected block event: %+v", block)
+	}
+}
+
+func TestObserverCacheHitAndFailOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClientResponse{Success: true})
+	}))
+
+	obs := &recordingObserver{}
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Mode:     "production",
+		Retry:    RetryConfig{MaxAttempts: 1},
+		Observer: obs,
+	})
+
+	client.ExecuteQuery("user", "query", "chat", nil)
+	client.ExecuteQuery("user", "query", "chat", nil)
+	if _, ok := obs.last["cache_hit"]; !ok {
+		t.Errorf("expected a cache hit, got %q", obs.sequence())
+	}
+
+	server.Close()
+	if _, err := client.ExecuteQuery("user", "other", "sql", nil); err != nil {
+		t.Fatalf("expected fail-open, got %v", err)
+	}
+	failOpen, ok := obs.last["fail_open"]
+	if !ok || failOpen.RequestType != "sql" || failOpen.Fallback != FailOpen || failOpen.Err == nil {
+		t.Errorf("unexpected fail-open event: %+v (%v)", failOpen, ok)
+	}
+	if end := obs.last["end"]; end.Status != 0 || end.Err == nil {
+		t.Errorf("expected transport error in end event, got %+v", end)
+	}
+}
+
+func TestObserverAuditFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	obs := &recordingObserver{}
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Observer: obs,
+	})
+
+	if _, err := client.AuditLLMCall("ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	e, ok := obs.last["audit_failure"]
+	if !ok || e.RequestType != RequestTypeAudit || e.Err == nil {
+		t.Errorf("unexpected audit failure event: %+v (%v)", e, ok)
+	}
+	if end := obs.last["end"]; end.Status != 400 || end.Err == nil {
+		t.Errorf("expected HTTP error in end event, got %+v", end)
+	}
+}
+
+func TestMultiObserver(t *testing.T) {
+	a, b := &recordingObserver{}, &recordingObserver{}
+	o := MultiObserver(a, NopObserver{}, b)
+
+	o.OnPolicyBlock(context.Background(), ObserverEvent{RequestType: "sql"})
+	o.OnRequestEnd(context.Background(), ObserverEvent{})
+
+	if a.sequence() != "block,end" || b.sequence() != "block,end" {
+		t.Errorf("expected both observers to receive callbacks, got %q and %q", a.sequence(), b.sequence())
+	}
+}
@@ -0,0 +1,346 @@
+// Functional options constructor and configuration validation
+package axonflow
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a client created with New.
+type Option func(*AxonFlowConfig)
+
+// New creates a client for the AxonFlow endpoint, configured by opts.
+//
+// Unlike NewClient, New validates the resulting configuration and returns a
+// *ValidationError (matching ErrValidation) for a missing or malformed endpoint,
+// negative durations or limits, or an unknown mode. When several settings are invalid
+// the errors are joined. Retries and caching are on by default; WithRetry and
+// WithCache take their Enabled field as given.
+//
+//	client, err := axonflow.New("https://agent.example.com",
+//		axonflow.WithCredentials(clientID, clientSecret),
+//		axonflow.WithTimeout(10*time.Second),
+//	)
+func New(endpoint string, opts ...Option) (*AxonFlowClient, error) {
+	config := defaultConfig()
+	config.Endpoint = endpoint
+	return newValidatedClient(config, opts)
+}
+
+// defaultConfig is the starting point for New: retries and caching on, everything
+// else unset.
+func defaultConfig() AxonFlowConfig {
+	return AxonFlowConfig{
+		Retry: RetryConfig{Enabled: true},
+		Cache: CacheConfig{Enabled: true},
+	}
+}
+
+// newValidatedClient applies opts to config, validates it and builds the client.
+func newValidatedClient(config AxonFlowConfig, opts []Option) (*AxonFlowClient, error) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&config)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return newClient(config), nil
+}
+
+// WithCredentials sets the OAuth2 client ID and secret.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(c *AxonFlowConfig) {
+		c.ClientID = clientID
+		c.ClientSecret = clientSecret
+	}
+}
+
+// WithAuthenticator sets a custom Authenticator.
+func WithAuthenticator(auth Authenticator) Option {
+	return func(c *AxonFlowConfig) { c.Authenticator = auth }
+}
+
+// WithAPIKey authenticates with a bearer API key.
+func WithAPIKey(apiKey string) Option {
+	return func(c *AxonFlowConfig) { c.APIKey = apiKey }
+}
+
+// WithOAuth2 exchanges the client credentials (see WithCredentials) for bearer tokens
+// at tokenURL.
+func WithOAuth2(tokenURL string, scopes ...string) Option {
+	return func(c *AxonFlowConfig) {
+		c.TokenURL = tokenURL
+		c.Scopes = scopes
+	}
+}
+
+// WithMode sets the mode, "production" (the default) or "sandbox".
+func WithMode(mode string) Option {
+	return func(c *AxonFlowConfig) { c.Mode = mode }
+}
+
+// WithDebug enables debug logging.
+func WithDebug(debug bool) Option {
+	return func(c *AxonFlowConfig) { c.Debug = debug }
+}
+
+// WithTimeout sets the request timeout (default: 60s).
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *AxonFlowConfig) { c.Timeout = timeout }
+}
+
+// WithMapTimeout sets the timeout for MAP operations (default: 120s).
+func WithMapTimeout(timeout time.Duration) Option {
+	return func(c *AxonFlowConfig) { c.MapTimeout = timeout }
+}
+
+// WithRetry replaces the retry configuration. Zero delays and attempts get their
+// defaults, but Enabled is used as given.
+func WithRetry(retry RetryConfig) Option {
+	return func(c *AxonFlowConfig) { c.Retry = retry }
+}
+
+// WithRetryPolicy enables retries with a custom policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *AxonFlowConfig) {
+		c.Retry.Enabled = true
+		c.Retry.Policy = policy
+	}
+}
+
+// WithoutRetry disables retries.
+func WithoutRetry() Option {
+	return func(c *AxonFlowConfig) { c.Retry.Enabled = false }
+}
+
+// WithCache replaces the ExecuteQuery cache configuration. A zero TTL gets the
+// default, but Enabled is used as given.
+func WithCache(cache CacheConfig) Option {
+	return func(c *AxonFlowConfig) { c.Cache = cache }
+}
+
+// WithoutCache disables the ExecuteQuery cache.
+func WithoutCache() Option {
+	return func(c *AxonFlowConfig) { c.Cache.Enabled = false }
+}
+
+// WithCircuitBreaker configures the circuit breaker.
+func WithCircuitBreaker(breaker CircuitBreakerConfig) Option {
+	return func(c *AxonFlowConfig) { c.CircuitBreaker = breaker }
+}
+
+// WithFailurePolicy sets the behavior per request type when AxonFlow is unavailable.
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(c *AxonFlowConfig) { c.FailurePolicy = policy }
+}
+
+// WithSingleFlight makes concurrent identical queries and pre-checks share one call.
+func WithSingleFlight() Option {
+	return func(c *AxonFlowConfig) { c.SingleFlight = true }
+}
+
+// WithRateLimit configures client-side throttling on the quota reported by AxonFlow.
+func WithRateLimit(rateLimit RateLimitConfig) Option {
+	return func(c *AxonFlowConfig) { c.RateLimit = rateLimit }
+}
+
+// WithEndpoints adds Agent endpoints to fail over to or balance across.
+func WithEndpoints(endpoints ...string) Option {
+	return func(c *AxonFlowConfig) { c.Endpoints = append(c.Endpoints, endpoints...) }
+}
+
+// WithFailover configures how requests are spread over several endpoints.
+func WithFailover(failover FailoverConfig) Option {
+	return func(c *AxonFlowConfig) { c.Failover = failover }
+}
+
+// WithCapabilityNegotiation fetches the server's capabilities before the first call to
+// an enterprise feature, so that unavailable features fail with ErrFeatureUnavailable.
+func WithCapabilityNegotiation() Option {
+	return func(c *AxonFlowConfig) { c.NegotiateCapabilities = true }
+}
+
+// WithHTTPClient sets the HTTP client used as the basis for all API calls.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *AxonFlowConfig) { c.HTTPClient = client }
+}
+
+// WithTransport sets the base RoundTripper.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *AxonFlowConfig) { c.Transport = transport }
+}
+
+// WithMiddleware appends transport middleware. Earlier middleware is outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *AxonFlowConfig) { c.Middleware = append(c.Middleware, middleware...) }
+}
+
+// WithTLS configures TLS.
+func WithTLS(tls TLSConfig) Option {
+	return func(c *AxonFlowConfig) { c.TLS = &tls }
+}
+
+// WithLogger sets the structured logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *AxonFlowConfig) { c.Logger = logger }
+}
+
+// WithObserver sets the observer notified of every call.
+func WithObserver(observer Observer) Option {
+	return func(c *AxonFlowConfig) { c.Observer = observer }
+}
+
+// WithMaxPendingAudits bounds the number of AuditLLMCallAsync audits in flight.
+func WithMaxPendingAudits(n int) Option {
+	return func(c *AxonFlowConfig) { c.MaxPendingAudits = n }
+}
+
+// Validate checks the configuration and returns a *ValidationError for each problem;
+// several problems are joined with errors.Join. Zero values are valid and select the
+// documented defaults.
+func (config AxonFlowConfig) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, newValidationError(field, "invalid config: "+field+" "+fmt.Sprintf(format, args...)))
+	}
+
+	checkEndpoint := func(field, endpoint string) {
+		if u, err := url.Parse(endpoint); err != nil {
+			invalid(field, "is not a valid URL: %v", err)
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			invalid(field, "must be an http or https URL, got %q", endpoint)
+		} else if u.Host == "" {
+			invalid(field, "has no host: %q", endpoint)
+		}
+	}
+	if config.Endpoint == "" && len(config.Endpoints) == 0 {
+		invalid("Endpoint", "is required")
+	} else if config.Endpoint != "" {
+		checkEndpoint("Endpoint", config.Endpoint)
+	}
+	for i, endpoint := range config.Endpoints {
+		checkEndpoint(fmt.Sprintf("Endpoints[%d]", i), endpoint)
+	}
+	switch config.Failover.Strategy {
+	case "", EndpointPriority, EndpointRoundRobin, EndpointLeastLatency:
+	default:
+		invalid("Failover.Strategy", "must be %q, %q or %q, got %q",
+			EndpointPriority, EndpointRoundRobin, EndpointLeastLatency, config.Failover.Strategy)
+	}
+
+	if config.ClientSecret != "" && config.ClientID == "" {
+		invalid("ClientID", "is required when ClientSecret is set")
+	}
+
+	if config.TokenURL != "" && config.Authenticator == nil && config.APIKey == "" {
+		if u, err := url.Parse(config.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("TokenURL", "must be an http or https URL, got %q", config.TokenURL)
+		}
+		if config.ClientID == "" || config.ClientSecret == "" {
+			invalid("TokenURL", "requires ClientID and ClientSecret")
+		}
+	}
+
+	switch config.Mode {
+	case "", "production", "sandbox":
+	default:
+		invalid("Mode", "must be \"production\" or \"sandbox\", got %q", config.Mode)
+	}
+
+	durations := []struct {
+		field string
+		value time.Duration
+	}{
+		{"Timeout", config.Timeout},
+		{"MapTimeout", config.MapTimeout},
+		{"Retry.InitialDelay", config.Retry.InitialDelay},
+		{"Retry.MaxDelay", config.Retry.MaxDelay},
+		{"Cache.TTL", config.Cache.TTL},
+		{"CircuitBreaker.CoolDown", config.CircuitBreaker.CoolDown},
+		{"FailurePolicy.CachedDecisionTTL", config.FailurePolicy.CachedDecisionTTL},
+		{"Failover.ProbeTimeout", config.Failover.ProbeTimeout},
+		{"Failover.ReadmitAfter", config.Failover.ReadmitAfter},
+		{"RateLimit.MaxWait", config.RateLimit.MaxWait},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			invalid(d.field, "must not be negative, got %v", d.value)
+		}
+	}
+
+	limits := []struct {
+		field string
+		value int64
+	}{
+		{"Retry.MaxAttempts", int64(config.Retry.MaxAttempts)},
+		{"Cache.MaxEntries", int64(config.Cache.MaxEntries)},
+		{"Cache.MaxBytes", config.Cache.MaxBytes},
+		{"CircuitBreaker.FailureThreshold", int64(config.CircuitBreaker.FailureThreshold)},
+		{"CircuitBreaker.HalfOpenMaxRequests", int64(config.CircuitBreaker.HalfOpenMaxRequests)},
+		{"CircuitBreaker.SuccessThreshold", int64(config.CircuitBreaker.SuccessThreshold)},
+		{"FailurePolicy.CachedDecisions", int64(config.FailurePolicy.CachedDecisions)},
+		{"MaxPendingAudits", int64(config.MaxPendingAudits)},
+		{"BatchConcurrency", int64(config.BatchConcurrency)},
+		{"Failover.FailureThreshold", int64(config.Failover.FailureThreshold)},
+		{"Failover.RecoveryThreshold", int64(config.Failover.RecoveryThreshold)},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			invalid(l.field, "must not be negative, got %d", l.value)
+		}
+	}
+
+	modes := []struct {
+		field string
+		value FailureMode
+	}{
+		{"FailurePolicy.Default", config.FailurePolicy.Default},
+		{"FailurePolicy.LLMChat", config.FailurePolicy.LLMChat},
+		{"FailurePolicy.SQL", config.FailurePolicy.SQL},
+		{"FailurePolicy.MCPQuery", config.FailurePolicy.MCPQuery},
+		{"FailurePolicy.PreCheck", config.FailurePolicy.PreCheck},
+		{"FailurePolicy.Audit", config.FailurePolicy.Audit},
+	}
+	for _, m := range modes {
+		switch m.value {
+		case "", FailOpen, FailClosed, FailCached:
+		default:
+			invalid(m.field, "must be %q, %q or %q, got %q", FailOpen, FailClosed, FailCached, m.value)
+		}
+	}
+
+	if config.TLS != nil {
+		if _, err := config.TLS.build(); err != nil {
+			errs = append(errs, err)
+		}
+		transport := config.Transport
+		if config.HTTPClient != nil && config.HTTPClient.Transport != nil {
+			transport = config.HTTPClient.Transport
+		}
+		if _, ok := transport.(*http.Transport); transport != nil && !ok {
+			invalid("TLS", "requires the base transport to be an *http.Transport, got %T", transport)
+		}
+	}
+
+	for i, mw := range config.Middleware {
+		if mw == nil {
+			invalid(fmt.Sprintf("Middleware[%d]", i), "is nil")
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+// joinErrors returns nil, the only error, or all errors joined.
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
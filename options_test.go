@@ -0,0 +1,173 @@
+package axonflow
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewAppliesOptions(t *testing.T) {
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	logger := slog.New(discardHandler{})
+
+	client, err := New("https://agent.example.com",
+		WithCredentials("id", "secret"),
+		WithMode("sandbox"),
+		WithTimeout(10*time.Second),
+		WithHTTPClient(httpClient),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if client.config.ClientID != "id" || client.config.ClientSecret != "secret" {
+		t.Errorf("credentials not applied: %+v", client.config)
+	}
+	if client.config.Mode != "sandbox" {
+		t.Errorf("expected sandbox mode, got %q", client.config.Mode)
+	}
+	if client.config.Timeout != 10*time.Second {
+		t.Errorf("expected 10s timeout, got %v", client.config.Timeout)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected the HTTP client's own timeout to win, got %v", client.httpClient.Timeout)
+	}
+	if client.logger != logger {
+		t.Error("expected the configured logger")
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	client, err := New("http://localhost:8080")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if client.config.Mode != "production" {
+		t.Errorf("expected production mode, got %q", client.config.Mode)
+	}
+	if client.config.Timeout != 60*time.Second || client.config.MapTimeout != 120*time.Second {
+		t.Errorf("unexpected timeouts: %v, %v", client.config.Timeout, client.config.MapTimeout)
+	}
+	if client.retryPolicy == nil {
+		t.Error("expected retries to be enabled by default")
+	}
+	if client.cache == nil {
+		t.Error("expected caching to be enabled by default")
+	}
+}
+
+func TestNewRespectsDisabledRetryAndCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// With NewClient, a zero MaxAttempts or TTL would switch these back on
+	client, err := New(server.URL,
+		WithCredentials("id", ""),
+		WithRetry(RetryConfig{Enabled: false}),
+		WithCache(CacheConfig{Enabled: false}),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if client.retryPolicy != nil {
+		t.Error("expected retries to stay disabled")
+	}
+	if client.cache != nil {
+		t.Error("expected caching to stay disabled")
+	}
+	if client.config.Retry.MaxAttempts != 3 || client.config.Cache.TTL != 60*time.Second {
+		t.Errorf("expected defaults for unset fields, got %+v %+v", client.config.Retry, client.config.Cache)
+	}
+
+	client.ListStaticPolicies(nil)
+	if calls != 1 {
+		t.Errorf("expected 1 call with retries disabled, got %d", calls)
+	}
+}
+
+func TestNewWithoutRetryAndCache(t *testing.T) {
+	client, err := New("https://agent.example.com", WithoutRetry(), WithoutCache())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if client.retryPolicy != nil || client.cache != nil {
+		t.Error("expected retries and caching to be disabled")
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		opts     []Option
+		field    string
+	}{
+		{"missing endpoint", "", nil, "Endpoint"},
+		{"no scheme", "agent.example.com", nil, "Endpoint"},
+		{"bad scheme", "ftp://agent.example.com", nil, "Endpoint"},
+		{"no host", "http://", nil, "Endpoint"},
+		{"malformed", "http://[::1", nil, "Endpoint"},
+		{"unknown mode", "http://localhost", []Option{WithMode("staging")}, "Mode"},
+		{"negative timeout", "http://localhost", []Option{WithTimeout(-time.Second)}, "Timeout"},
+		{"negative map timeout", "http://localhost", []Option{WithMapTimeout(-1)}, "MapTimeout"},
+		{"negative retry delay", "http://localhost", []Option{WithRetry(RetryConfig{Enabled: true, InitialDelay: -1})}, "Retry.InitialDelay"},
+		{"negative attempts", "http://localhost", []Option{WithRetry(RetryConfig{MaxAttempts: -1})}, "Retry.MaxAttempts"},
+		{"negative cache TTL", "http://localhost", []Option{WithCache(CacheConfig{TTL: -time.Second})}, "Cache.TTL"},
+		{"negative cool-down", "http://localhost", []Option{WithCircuitBreaker(CircuitBreakerConfig{CoolDown: -1})}, "CircuitBreaker.CoolDown"},
+		{"unknown failure mode", "http://localhost", []Option{WithFailurePolicy(FailurePolicy{SQL: "fail-silently"})}, "FailurePolicy.SQL"},
+		{"secret without id", "http://localhost", []Option{WithCredentials("", "secret")}, "ClientID"},
+		{"nil middleware", "http://localhost", []Option{WithMiddleware(nil)}, "Middleware[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.endpoint, tt.opts...)
+			if client != nil {
+				t.Error("expected no client")
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("expected ErrValidation, got %v", err)
+			}
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) || valErr.Field != tt.field {
+				t.Errorf("expected field %q, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestValidateJoinsErrors(t *testing.T) {
+	err := AxonFlowConfig{Endpoint: "localhost", Mode: "dev", Timeout: -1}.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, field := range []string{"Endpoint", "Mode", "Timeout"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("expected %s in %q", field, err)
+		}
+	}
+}
+
+func TestValidateAcceptsZeroConfig(t *testing.T) {
+	if err := (AxonFlowConfig{Endpoint: "https://agent.example.com"}).Validate(); err != nil {
+		t.Errorf("expected a zero config to be valid, got %v", err)
+	}
+}
+
+func TestNewClientKeepsHistoricalDefaults(t *testing.T) {
+	client := NewClient(AxonFlowConfig{Endpoint: "http://localhost", Retry: RetryConfig{Enabled: false}})
+	if client.retryPolicy == nil {
+		t.Error("NewClient should still enable retries when MaxAttempts is zero")
+	}
+}
@@ -2,12 +2,8 @@
 package axonflow
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"net/url"
 	"time"
 )
@@ -323,173 +319,36 @@ type GetEffectiveRequest = EffectivePoliciesOptions
 // ============================================================================
 
 // orchestratorPolicyRequest makes an HTTP request to the Orchestrator policy API (for dynamic policies)
-func (c *AxonFlowClient) orchestratorPolicyRequest(method, path string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(bodyBytes)
-	}
-
-	fullURL := c.config.Endpoint + path
-
-	req, err := http.NewRequest(method, fullURL, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Add OAuth2 Basic auth headers
-	c.addAuthHeaders(req)
-
-	// Always set tenant ID for policy APIs (uses ClientID as tenant)
-	if c.config.ClientID != "" {
-		req.Header.Set("X-Tenant-ID", c.config.ClientID)
-	}
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Orchestrator policy request: %s %s", method, path)
-	}
-
-	resp, err := c.httpClient.Do(req)
+func (c *AxonFlowClient) orchestratorPolicyRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	req, err := newJSONRequest(method, c.config.Endpoint+path, body)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
+	c.debugf("Orchestrator policy request: %s %s", method, path)
 
-	if resp.StatusCode >= 400 {
-		return &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
-	}
-
-	// Handle no-content responses
-	if resp.StatusCode == 204 || len(respBody) == 0 {
-		return nil
-	}
-
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-	}
-
-	return nil
+	// OAuth2 Basic auth and X-Tenant-ID (ClientID as tenant) are added by send
+	return c.sendJSON(ctx, req, result)
 }
 
 // policyRequest makes an HTTP request to the policy API
-func (c *AxonFlowClient) policyRequest(method, path string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(bodyBytes)
-	}
-
-	fullURL := c.config.Endpoint + path
-
-	req, err := http.NewRequest(method, fullURL, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	// Add OAuth2 Basic auth headers
-	c.addAuthHeaders(req)
-
-	// Always set tenant ID for policy APIs (uses ClientID as tenant)
-	if c.config.ClientID != "" {
-		req.Header.Set("X-Tenant-ID", c.config.ClientID)
-	}
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Policy request: %s %s", method, path)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+func (c *AxonFlowClient) policyRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	req, err := newJSONRequest(method, c.config.Endpoint+path, body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return err
 	}
 
-	if resp.StatusCode >= 400 {
-		return &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
-	}
-
-	// Handle no-content responses
-	if resp.StatusCode == 204 || len(respBody) == 0 {
-		return nil
-	}
+	c.debugf("Policy request: %s %s", method, path)
 
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-	}
-
-	return nil
+	// OAuth2 Basic auth and X-Tenant-ID (ClientID as tenant) are added by send
+	return c.sendJSON(ctx, req, result)
 }
 
 // policyRequestRaw makes an HTTP request and returns raw bytes (for CSV export)
-func (c *AxonFlowClient) policyRequestRaw(method, path string) ([]byte, error) {
-	fullURL := c.config.Endpoint + path
+func (c *AxonFlowClient) policyRequestRaw(ctx context.Context, method, path string) ([]byte, error) {
+	c.debugf("Raw policy request: %s %s", method, path)
 
-	req, err := http.NewRequest(method, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add OAuth2 Basic auth headers
-	c.addAuthHeaders(req)
-
-	// Always set tenant ID for policy APIs (uses ClientID as tenant)
-	if c.config.ClientID != "" {
-		req.Header.Set("X-Tenant-ID", c.config.ClientID)
-	}
-
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Raw policy request: %s %s", method, path)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, &httpError{
-			statusCode: resp.StatusCode,
-			message:    string(respBody),
-		}
-	}
-
-	return respBody, nil
+	return c.sendRaw(ctx, newRequest(method, c.config.Endpoint+path))
 }
 
 // buildQueryParams builds query parameters from options
@@ -593,17 +452,20 @@ type staticPoliciesResponse struct {
 
 // ListStaticPolicies lists all static policies with optional filtering.
 func (c *AxonFlowClient) ListStaticPolicies(options *ListStaticPoliciesOptions) ([]StaticPolicy, error) {
+	return c.ListStaticPoliciesContext(context.Background(), options)
+}
+
+// ListStaticPoliciesContext is like ListStaticPolicies but carries a context.
+func (c *AxonFlowClient) ListStaticPoliciesContext(ctx context.Context, options *ListStaticPoliciesOptions) ([]StaticPolicy, error) {
 	path := "/api/v1/static-policies"
 	if options != nil {
 		path += options.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing static policies: %s", path)
-	}
+	c.debugf("Listing static policies: %s", path)
 
 	var response staticPoliciesResponse
-	if err := c.policyRequest("GET", path, nil, &response); err != nil {
+	if err := c.policyRequest(ctx, "GET", path, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -612,12 +474,15 @@ func (c *AxonFlowClient) ListStaticPolicies(options *ListStaticPoliciesOptions)
 
 // GetStaticPolicy gets a specific static policy by ID.
 func (c *AxonFlowClient) GetStaticPolicy(id string) (*StaticPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting static policy: %s", id)
-	}
+	return c.GetStaticPolicyContext(context.Background(), id)
+}
+
+// GetStaticPolicyContext is like GetStaticPolicy but carries a context.
+func (c *AxonFlowClient) GetStaticPolicyContext(ctx context.Context, id string) (*StaticPolicy, error) {
+	c.debugf("Getting static policy: %s", id)
 
 	var policy StaticPolicy
-	if err := c.policyRequest("GET", "/api/v1/static-policies/"+id, nil, &policy); err != nil {
+	if err := c.policyRequest(ctx, "GET", "/api/v1/static-policies/"+id, nil, &policy); err != nil {
 		return nil, err
 	}
 
@@ -626,9 +491,12 @@ func (c *AxonFlowClient) GetStaticPolicy(id string) (*StaticPolicy, error) {
 
 // CreateStaticPolicy creates a new static policy.
 func (c *AxonFlowClient) CreateStaticPolicy(req *CreateStaticPolicyRequest) (*StaticPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Creating static policy: %s", req.Name)
-	}
+	return c.CreateStaticPolicyContext(context.Background(), req)
+}
+
+// CreateStaticPolicyContext is like CreateStaticPolicy but carries a context.
+func (c *AxonFlowClient) CreateStaticPolicyContext(ctx context.Context, req *CreateStaticPolicyRequest) (*StaticPolicy, error) {
+	c.debugf("Creating static policy: %s", req.Name)
 
 	// Set default tier if not specified
 	if req.Tier == "" {
@@ -636,7 +504,7 @@ func (c *AxonFlowClient) CreateStaticPolicy(req *CreateStaticPolicyRequest) (*St
 	}
 
 	var policy StaticPolicy
-	if err := c.policyRequest("POST", "/api/v1/static-policies", req, &policy); err != nil {
+	if err := c.policyRequest(ctx, "POST", "/api/v1/static-policies", req, &policy); err != nil {
 		return nil, err
 	}
 
@@ -645,12 +513,15 @@ func (c *AxonFlowClient) CreateStaticPolicy(req *CreateStaticPolicyRequest) (*St
 
 // UpdateStaticPolicy updates an existing static policy.
 func (c *AxonFlowClient) UpdateStaticPolicy(id string, req *UpdateStaticPolicyRequest) (*StaticPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Updating static policy: %s", id)
-	}
+	return c.UpdateStaticPolicyContext(context.Background(), id, req)
+}
+
+// UpdateStaticPolicyContext is like UpdateStaticPolicy but carries a context.
+func (c *AxonFlowClient) UpdateStaticPolicyContext(ctx context.Context, id string, req *UpdateStaticPolicyRequest) (*StaticPolicy, error) {
+	c.debugf("Updating static policy: %s", id)
 
 	var policy StaticPolicy
-	if err := c.policyRequest("PUT", "/api/v1/static-policies/"+id, req, &policy); err != nil {
+	if err := c.policyRequest(ctx, "PUT", "/api/v1/static-policies/"+id, req, &policy); err != nil {
 		return nil, err
 	}
 
@@ -659,22 +530,28 @@ func (c *AxonFlowClient) UpdateStaticPolicy(id string, req *UpdateStaticPolicyRe
 
 // DeleteStaticPolicy deletes a static policy.
 func (c *AxonFlowClient) DeleteStaticPolicy(id string) error {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleting static policy: %s", id)
-	}
+	return c.DeleteStaticPolicyContext(context.Background(), id)
+}
+
+// DeleteStaticPolicyContext is like DeleteStaticPolicy but carries a context.
+func (c *AxonFlowClient) DeleteStaticPolicyContext(ctx context.Context, id string) error {
+	c.debugf("Deleting static policy: %s", id)
 
-	return c.policyRequest("DELETE", "/api/v1/static-policies/"+id, nil, nil)
+	return c.policyRequest(ctx, "DELETE", "/api/v1/static-policies/"+id, nil, nil)
 }
 
 // ToggleStaticPolicy toggles a static policy's enabled status.
 func (c *AxonFlowClient) ToggleStaticPolicy(id string, enabled bool) (*StaticPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Toggling static policy: %s (enabled=%v)", id, enabled)
-	}
+	return c.ToggleStaticPolicyContext(context.Background(), id, enabled)
+}
+
+// ToggleStaticPolicyContext is like ToggleStaticPolicy but carries a context.
+func (c *AxonFlowClient) ToggleStaticPolicyContext(ctx context.Context, id string, enabled bool) (*StaticPolicy, error) {
+	c.debugf("Toggling static policy: %s (enabled=%v)", id, enabled)
 
 	body := map[string]bool{"enabled": enabled}
 	var policy StaticPolicy
-	if err := c.policyRequest("PATCH", "/api/v1/static-policies/"+id, body, &policy); err != nil {
+	if err := c.policyRequest(ctx, "PATCH", "/api/v1/static-policies/"+id, body, &policy); err != nil {
 		return nil, err
 	}
 
@@ -689,17 +566,20 @@ type effectivePoliciesResponse struct {
 
 // GetEffectiveStaticPolicies gets effective static policies with tier inheritance applied.
 func (c *AxonFlowClient) GetEffectiveStaticPolicies(options *EffectivePoliciesOptions) ([]StaticPolicy, error) {
+	return c.GetEffectiveStaticPoliciesContext(context.Background(), options)
+}
+
+// GetEffectiveStaticPoliciesContext is like GetEffectiveStaticPolicies but carries a context.
+func (c *AxonFlowClient) GetEffectiveStaticPoliciesContext(ctx context.Context, options *EffectivePoliciesOptions) ([]StaticPolicy, error) {
 	path := "/api/v1/static-policies/effective"
 	if options != nil {
 		path += options.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting effective static policies: %s", path)
-	}
+	c.debugf("Getting effective static policies: %s", path)
 
 	var response effectivePoliciesResponse
-	if err := c.policyRequest("GET", path, nil, &response); err != nil {
+	if err := c.policyRequest(ctx, "GET", path, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -708,17 +588,26 @@ func (c *AxonFlowClient) GetEffectiveStaticPolicies(options *EffectivePoliciesOp
 
 // TestPattern tests a regex pattern against sample inputs.
 func (c *AxonFlowClient) TestPattern(pattern string, testInputs []string) (*TestPatternResult, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Testing pattern: %s (%d inputs)", pattern, len(testInputs))
-	}
+	return c.TestPatternContext(context.Background(), pattern, testInputs)
+}
+
+// TestPatternContext is like TestPattern but carries a context.
+func (c *AxonFlowClient) TestPatternContext(ctx context.Context, pattern string, testInputs []string) (*TestPatternResult, error) {
+	c.debugf("Testing pattern: %s (%d inputs)", pattern, len(testInputs))
 
 	body := map[string]interface{}{
 		"pattern": pattern,
 		"inputs":  testInputs,
 	}
 
+	req, err := newJSONRequest("POST", c.config.Endpoint+"/api/v1/static-policies/test", body)
+	if err != nil {
+		return nil, err
+	}
+	req.retrySafe = true // testing a pattern has no side effects
+
 	var result TestPatternResult
-	if err := c.policyRequest("POST", "/api/v1/static-policies/test", body, &result); err != nil {
+	if err := c.sendJSON(ctx, req, &result); err != nil {
 		return nil, err
 	}
 
@@ -727,16 +616,19 @@ func (c *AxonFlowClient) TestPattern(pattern string, testInputs []string) (*Test
 
 // GetStaticPolicyVersions gets version history for a static policy.
 func (c *AxonFlowClient) GetStaticPolicyVersions(id string) ([]PolicyVersion, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting static policy versions: %s", id)
-	}
+	return c.GetStaticPolicyVersionsContext(context.Background(), id)
+}
+
+// GetStaticPolicyVersionsContext is like GetStaticPolicyVersions but carries a context.
+func (c *AxonFlowClient) GetStaticPolicyVersionsContext(ctx context.Context, id string) ([]PolicyVersion, error) {
+	c.debugf("Getting static policy versions: %s", id)
 
 	var response struct {
 		PolicyID string          `json:"policy_id"`
 		Versions []PolicyVersion `json:"versions"`
 		Count    int             `json:"count"`
 	}
-	if err := c.policyRequest("GET", "/api/v1/static-policies/"+id+"/versions", nil, &response); err != nil {
+	if err := c.policyRequest(ctx, "GET", "/api/v1/static-policies/"+id+"/versions", nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -749,12 +641,18 @@ func (c *AxonFlowClient) GetStaticPolicyVersions(id string) ([]PolicyVersion, er
 
 // CreatePolicyOverride creates an override for a static policy.
 func (c *AxonFlowClient) CreatePolicyOverride(policyID string, req *CreatePolicyOverrideRequest) (*PolicyOverride, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Creating policy override for: %s", policyID)
+	return c.CreatePolicyOverrideContext(context.Background(), policyID, req)
+}
+
+// CreatePolicyOverrideContext is like CreatePolicyOverride but carries a context.
+func (c *AxonFlowClient) CreatePolicyOverrideContext(ctx context.Context, policyID string, req *CreatePolicyOverrideRequest) (*PolicyOverride, error) {
+	if err := c.requireFeature(ctx, FeaturePolicyOverrides); err != nil {
+		return nil, err
 	}
+	c.debugf("Creating policy override for: %s", policyID)
 
 	var override PolicyOverride
-	if err := c.policyRequest("POST", "/api/v1/static-policies/"+policyID+"/override", req, &override); err != nil {
+	if err := c.policyRequest(ctx, "POST", "/api/v1/static-policies/"+policyID+"/override", req, &override); err != nil {
 		return nil, err
 	}
 
@@ -763,24 +661,36 @@ func (c *AxonFlowClient) CreatePolicyOverride(policyID string, req *CreatePolicy
 
 // DeletePolicyOverride deletes an override for a static policy.
 func (c *AxonFlowClient) DeletePolicyOverride(policyID string) error {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleting policy override for: %s", policyID)
+	return c.DeletePolicyOverrideContext(context.Background(), policyID)
+}
+
+// DeletePolicyOverrideContext is like DeletePolicyOverride but carries a context.
+func (c *AxonFlowClient) DeletePolicyOverrideContext(ctx context.Context, policyID string) error {
+	if err := c.requireFeature(ctx, FeaturePolicyOverrides); err != nil {
+		return err
 	}
+	c.debugf("Deleting policy override for: %s", policyID)
 
-	return c.policyRequest("DELETE", "/api/v1/static-policies/"+policyID+"/override", nil, nil)
+	return c.policyRequest(ctx, "DELETE", "/api/v1/static-policies/"+policyID+"/override", nil, nil)
 }
 
 // ListPolicyOverrides lists all active policy overrides (Enterprise).
 func (c *AxonFlowClient) ListPolicyOverrides() ([]PolicyOverride, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing policy overrides")
+	return c.ListPolicyOverridesContext(context.Background())
+}
+
+// ListPolicyOverridesContext is like ListPolicyOverrides but carries a context.
+func (c *AxonFlowClient) ListPolicyOverridesContext(ctx context.Context) ([]PolicyOverride, error) {
+	if err := c.requireFeature(ctx, FeaturePolicyOverrides); err != nil {
+		return nil, err
 	}
+	c.debugf("Listing policy overrides")
 
 	var response struct {
 		Overrides []PolicyOverride `json:"overrides"`
 		Count     int              `json:"count"`
 	}
-	err := c.policyRequest("GET", "/api/v1/static-policies/overrides", nil, &response)
+	err := c.policyRequest(ctx, "GET", "/api/v1/static-policies/overrides", nil, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -804,17 +714,20 @@ type dynamicPolicyResponse struct {
 // ListDynamicPolicies lists all dynamic policies with optional filtering.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) ListDynamicPolicies(options *ListDynamicPoliciesOptions) ([]DynamicPolicy, error) {
+	return c.ListDynamicPoliciesContext(context.Background(), options)
+}
+
+// ListDynamicPoliciesContext is like ListDynamicPolicies but carries a context.
+func (c *AxonFlowClient) ListDynamicPoliciesContext(ctx context.Context, options *ListDynamicPoliciesOptions) ([]DynamicPolicy, error) {
 	path := "/api/v1/dynamic-policies"
 	if options != nil {
 		path += options.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Listing dynamic policies: %s", path)
-	}
+	c.debugf("Listing dynamic policies: %s", path)
 
 	var response dynamicPoliciesResponse
-	if err := c.orchestratorPolicyRequest("GET", path, nil, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "GET", path, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -824,12 +737,15 @@ func (c *AxonFlowClient) ListDynamicPolicies(options *ListDynamicPoliciesOptions
 // GetDynamicPolicy gets a specific dynamic policy by ID.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) GetDynamicPolicy(id string) (*DynamicPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting dynamic policy: %s", id)
-	}
+	return c.GetDynamicPolicyContext(context.Background(), id)
+}
+
+// GetDynamicPolicyContext is like GetDynamicPolicy but carries a context.
+func (c *AxonFlowClient) GetDynamicPolicyContext(ctx context.Context, id string) (*DynamicPolicy, error) {
+	c.debugf("Getting dynamic policy: %s", id)
 
 	var response dynamicPolicyResponse
-	if err := c.orchestratorPolicyRequest("GET", "/api/v1/dynamic-policies/"+id, nil, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "GET", "/api/v1/dynamic-policies/"+id, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -839,12 +755,15 @@ func (c *AxonFlowClient) GetDynamicPolicy(id string) (*DynamicPolicy, error) {
 // CreateDynamicPolicy creates a new dynamic policy.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) CreateDynamicPolicy(req *CreateDynamicPolicyRequest) (*DynamicPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Creating dynamic policy: %s", req.Name)
-	}
+	return c.CreateDynamicPolicyContext(context.Background(), req)
+}
+
+// CreateDynamicPolicyContext is like CreateDynamicPolicy but carries a context.
+func (c *AxonFlowClient) CreateDynamicPolicyContext(ctx context.Context, req *CreateDynamicPolicyRequest) (*DynamicPolicy, error) {
+	c.debugf("Creating dynamic policy: %s", req.Name)
 
 	var response dynamicPolicyResponse
-	if err := c.orchestratorPolicyRequest("POST", "/api/v1/dynamic-policies", req, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "POST", "/api/v1/dynamic-policies", req, &response); err != nil {
 		return nil, err
 	}
 
@@ -854,12 +773,15 @@ func (c *AxonFlowClient) CreateDynamicPolicy(req *CreateDynamicPolicyRequest) (*
 // UpdateDynamicPolicy updates an existing dynamic policy.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) UpdateDynamicPolicy(id string, req *UpdateDynamicPolicyRequest) (*DynamicPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Updating dynamic policy: %s", id)
-	}
+	return c.UpdateDynamicPolicyContext(context.Background(), id, req)
+}
+
+// UpdateDynamicPolicyContext is like UpdateDynamicPolicy but carries a context.
+func (c *AxonFlowClient) UpdateDynamicPolicyContext(ctx context.Context, id string, req *UpdateDynamicPolicyRequest) (*DynamicPolicy, error) {
+	c.debugf("Updating dynamic policy: %s", id)
 
 	var response dynamicPolicyResponse
-	if err := c.orchestratorPolicyRequest("PUT", "/api/v1/dynamic-policies/"+id, req, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "PUT", "/api/v1/dynamic-policies/"+id, req, &response); err != nil {
 		return nil, err
 	}
 
@@ -869,23 +791,29 @@ func (c *AxonFlowClient) UpdateDynamicPolicy(id string, req *UpdateDynamicPolicy
 // DeleteDynamicPolicy deletes a dynamic policy.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) DeleteDynamicPolicy(id string) error {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Deleting dynamic policy: %s", id)
-	}
+	return c.DeleteDynamicPolicyContext(context.Background(), id)
+}
 
-	return c.orchestratorPolicyRequest("DELETE", "/api/v1/dynamic-policies/"+id, nil, nil)
+// DeleteDynamicPolicyContext is like DeleteDynamicPolicy but carries a context.
+func (c *AxonFlowClient) DeleteDynamicPolicyContext(ctx context.Context, id string) error {
+	c.debugf("Deleting dynamic policy: %s", id)
+
+	return c.orchestratorPolicyRequest(ctx, "DELETE", "/api/v1/dynamic-policies/"+id, nil, nil)
 }
 
 // ToggleDynamicPolicy toggles a dynamic policy's enabled status.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) ToggleDynamicPolicy(id string, enabled bool) (*DynamicPolicy, error) {
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Toggling dynamic policy: %s (enabled=%v)", id, enabled)
-	}
+	return c.ToggleDynamicPolicyContext(context.Background(), id, enabled)
+}
+
+// ToggleDynamicPolicyContext is like ToggleDynamicPolicy but carries a context.
+func (c *AxonFlowClient) ToggleDynamicPolicyContext(ctx context.Context, id string, enabled bool) (*DynamicPolicy, error) {
+	c.debugf("Toggling dynamic policy: %s (enabled=%v)", id, enabled)
 
 	body := map[string]bool{"enabled": enabled}
 	var response dynamicPolicyResponse
-	if err := c.orchestratorPolicyRequest("PUT", "/api/v1/dynamic-policies/"+id, body, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "PUT", "/api/v1/dynamic-policies/"+id, body, &response); err != nil {
 		return nil, err
 	}
 
@@ -895,18 +823,21 @@ func (c *AxonFlowClient) ToggleDynamicPolicy(id string, enabled bool) (*DynamicP
 // GetEffectiveDynamicPolicies gets effective dynamic policies with tier inheritance applied.
 // Dynamic policies are stored on the Orchestrator (not Agent).
 func (c *AxonFlowClient) GetEffectiveDynamicPolicies(options *EffectivePoliciesOptions) ([]DynamicPolicy, error) {
+	return c.GetEffectiveDynamicPoliciesContext(context.Background(), options)
+}
+
+// GetEffectiveDynamicPoliciesContext is like GetEffectiveDynamicPolicies but carries a context.
+func (c *AxonFlowClient) GetEffectiveDynamicPoliciesContext(ctx context.Context, options *EffectivePoliciesOptions) ([]DynamicPolicy, error) {
 	path := "/api/v1/dynamic-policies/effective"
 	if options != nil {
 		path += options.buildQueryParams()
 	}
 
-	if c.config.Debug {
-		log.Printf("[AxonFlow] Getting effective dynamic policies: %s", path)
-	}
+	c.debugf("Getting effective dynamic policies: %s", path)
 
 	// Agent proxy (Issue #886) returns {"policies": [...]} wrapper
 	var response dynamicPoliciesResponse
-	if err := c.orchestratorPolicyRequest("GET", path, nil, &response); err != nil {
+	if err := c.orchestratorPolicyRequest(ctx, "GET", path, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,9 @@
 package axonflow
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -584,3 +586,23 @@ func TestGetEffectiveDynamicPolicies(t *testing.T) {
 		t.Errorf("Expected 1 policy, got %d", len(policies))
 	}
 }
+
+func TestPolicyContextVariantsRespectCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test-org"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.ListStaticPoliciesContext(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListStaticPoliciesContext: expected context.DeadlineExceeded, got %v", err)
+	}
+	if _, err := client.ListDynamicPoliciesContext(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListDynamicPoliciesContext: expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,601 @@
+// Policy export and import between tenants and environments
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// policyArchiveFormat identifies policy archives
+	policyArchiveFormat = "axonflow-policy-archive"
+	// policyArchiveVersion is the archive version written and understood by the SDK
+	policyArchiveVersion = 1
+)
+
+// PolicyArchive is a snapshot of a tenant's policies: its static and dynamic policies
+// (system policies excluded) and its overrides. It is plain JSON, so it can be stored
+// and reviewed as a file.
+//
+// Server-generated IDs and timestamps are not exported. Policies are identified
+// within the archive by Ref, which overrides use to refer to them; overrides of system
+// policies refer to the system policy by name.
+type PolicyArchive struct {
+	Format          string                  `json:"format"`
+	Version         int                     `json:"version"`
+	ExportedAt      time.Time               `json:"exported_at"`
+	Source          PolicyArchiveSource     `json:"source"`
+	StaticPolicies  []ArchivedStaticPolicy  `json:"static_policies"`
+	DynamicPolicies []ArchivedDynamicPolicy `json:"dynamic_policies"`
+	Overrides       []ArchivedOverride      `json:"overrides"`
+}
+
+// PolicyArchiveSource describes where an archive was exported from.
+type PolicyArchiveSource struct {
+	Tenant        string `json:"tenant,omitempty"`
+	ServerVersion string `json:"server_version,omitempty"` // If the capabilities are known
+	SDKVersion    string `json:"sdk_version"`
+}
+
+// ArchivedStaticPolicy is an exported static policy.
+type ArchivedStaticPolicy struct {
+	Ref         string         `json:"ref"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Category    PolicyCategory `json:"category"`
+	Tier        PolicyTier     `json:"tier"`
+	Pattern     string         `json:"pattern"`
+	Severity    PolicySeverity `json:"severity"`
+	Enabled     bool           `json:"enabled"`
+	Action      PolicyAction   `json:"action"`
+}
+
+// ArchivedDynamicPolicy is an exported dynamic policy.
+type ArchivedDynamicPolicy struct {
+	Ref         string                   `json:"ref"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Type        string                   `json:"type"`
+	Category    string                   `json:"category,omitempty"`
+	Conditions  []DynamicPolicyCondition `json:"conditions,omitempty"`
+	Actions     []DynamicPolicyAction    `json:"actions,omitempty"`
+	Priority    int                      `json:"priority"`
+	Enabled     bool                     `json:"enabled"`
+}
+
+// ArchivedOverride is an exported override. Exactly one of PolicyRef (a static policy
+// in the archive) and SystemPolicy (the name of a system policy) is set.
+type ArchivedOverride struct {
+	PolicyRef    string         `json:"policy_ref,omitempty"`
+	SystemPolicy string         `json:"system_policy,omitempty"`
+	Action       OverrideAction `json:"action_override"`
+	Reason       string         `json:"override_reason"`
+	ExpiresAt    *time.Time     `json:"expires_at,omitempty"`
+}
+
+// PolicyExportFilter selects the policies ExportPolicies exports. The zero value
+// exports everything.
+type PolicyExportFilter struct {
+	// Kinds limits the export to some resource kinds (default: all)
+	Kinds []ResourceKind
+	// Categories limits the static policies to some categories (default: all)
+	Categories []PolicyCategory
+	// DynamicTypes limits the dynamic policies to some types, e.g. "risk" (default: all)
+	DynamicTypes []string
+	// ExcludeDisabled leaves out disabled policies
+	ExcludeDisabled bool
+}
+
+// includes reports whether the filter selects resources of kind.
+func (f *PolicyExportFilter) includes(kind ResourceKind) bool {
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, k := range f.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportPolicies exports the tenant's policies selected by filter (nil for all).
+// Exported overrides are those of system policies and of the exported static policies;
+// overrides of policies the filter leaves out are not exported. Servers without
+// overrides export none.
+func (c *AxonFlowClient) ExportPolicies(ctx context.Context, filter *PolicyExportFilter) (*PolicyArchive, error) {
+	if filter == nil {
+		filter = &PolicyExportFilter{}
+	}
+	state, err := c.loadPolicyState(ctx, filter.includes(ResourceOverride))
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &PolicyArchive{
+		Format:          policyArchiveFormat,
+		Version:         policyArchiveVersion,
+		ExportedAt:      time.Now().UTC(),
+		Source:          PolicyArchiveSource{Tenant: c.config.ClientID, SDKVersion: SDKVersion},
+		StaticPolicies:  []ArchivedStaticPolicy{},
+		DynamicPolicies: []ArchivedDynamicPolicy{},
+		Overrides:       []ArchivedOverride{},
+	}
+	if caps := c.caps.last(); caps != nil {
+		archive.Source.ServerVersion = caps.ServerVersion
+	}
+
+	refs := map[string]string{} // Archive refs of exported static policies, by ID
+	if filter.includes(ResourceStaticPolicy) {
+		categories := map[PolicyCategory]bool{}
+		for _, category := range filter.Categories {
+			categories[category] = true
+		}
+		for _, name := range sortedNames(state.static) {
+			p := state.static[name]
+			if (len(categories) > 0 && !categories[p.Category]) || (filter.ExcludeDisabled && !p.Enabled) {
+				continue
+			}
+			ref := "static-" + strconv.Itoa(len(archive.StaticPolicies)+1)
+			refs[p.ID] = ref
+			archive.StaticPolicies = append(archive.StaticPolicies, ArchivedStaticPolicy{
+				Ref:         ref,
+				Name:        p.Name,
+				Description: p.Description,
+				Category:    p.Category,
+				Tier:        p.Tier,
+				Pattern:     p.Pattern,
+				Severity:    p.Severity,
+				Enabled:     p.Enabled,
+				Action:      p.Action,
+			})
+		}
+	}
+
+	if filter.includes(ResourceDynamicPolicy) {
+		types := map[string]bool{}
+		for _, t := range filter.DynamicTypes {
+			types[t] = true
+		}
+		for _, name := range sortedNames(state.dynamic) {
+			p := state.dynamic[name]
+			if (len(types) > 0 && !types[p.Type]) || (filter.ExcludeDisabled && !p.Enabled) {
+				continue
+			}
+			archive.DynamicPolicies = append(archive.DynamicPolicies, ArchivedDynamicPolicy{
+				Ref:         "dynamic-" + strconv.Itoa(len(archive.DynamicPolicies)+1),
+				Name:        p.Name,
+				Description: p.Description,
+				Type:        p.Type,
+				Category:    p.Category,
+				Conditions:  p.Conditions,
+				Actions:     p.Actions,
+				Priority:    p.Priority,
+				Enabled:     p.Enabled,
+			})
+		}
+	}
+
+	for _, id := range sortedNames(state.overrides) {
+		o := state.overrides[id]
+		archived := ArchivedOverride{PolicyRef: refs[id], Action: o.Action, Reason: o.Reason, ExpiresAt: o.ExpiresAt}
+		if archived.PolicyRef == "" {
+			p, ok := state.byID[id]
+			if !ok || p.Tier != TierSystem {
+				continue
+			}
+			archived.SystemPolicy = p.Name
+		}
+		archive.Overrides = append(archive.Overrides, archived)
+	}
+
+	c.logger.InfoContext(ctx, "AxonFlow policies exported",
+		"static_policies", len(archive.StaticPolicies),
+		"dynamic_policies", len(archive.DynamicPolicies),
+		"overrides", len(archive.Overrides))
+	return archive, nil
+}
+
+// ParsePolicyArchive parses and validates an archive written by ExportPolicies.
+func ParsePolicyArchive(data []byte) (*PolicyArchive, error) {
+	var archive PolicyArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("failed to parse policy archive: %w", err)
+	}
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// Validate checks the archive's format and version and that its references resolve.
+func (a *PolicyArchive) Validate() error {
+	if a.Format != policyArchiveFormat {
+		return fmt.Errorf("not a policy archive (format %q)", a.Format)
+	}
+	if a.Version != policyArchiveVersion {
+		return fmt.Errorf("unsupported policy archive version %d (expected %d)", a.Version, policyArchiveVersion)
+	}
+
+	var errs []error
+	refs := map[string]bool{}
+	names := map[string]bool{}
+	for i, p := range a.StaticPolicies {
+		if p.Ref == "" || refs[p.Ref] {
+			errs = append(errs, fmt.Errorf("static_policies[%d]: missing or duplicate ref %q", i, p.Ref))
+		}
+		if names[p.Name] {
+			errs = append(errs, fmt.Errorf("static policy %q is archived twice", p.Name))
+		}
+		refs[p.Ref] = true
+		names[p.Name] = true
+	}
+	names = map[string]bool{}
+	for i, p := range a.DynamicPolicies {
+		if p.Ref == "" || refs[p.Ref] {
+			errs = append(errs, fmt.Errorf("dynamic_policies[%d]: missing or duplicate ref %q", i, p.Ref))
+		}
+		if names[p.Name] {
+			errs = append(errs, fmt.Errorf("dynamic policy %q is archived twice", p.Name))
+		}
+		refs[p.Ref] = true
+		names[p.Name] = true
+	}
+	for i, o := range a.Overrides {
+		switch {
+		case (o.PolicyRef == "") == (o.SystemPolicy == ""):
+			errs = append(errs, fmt.Errorf("overrides[%d]: exactly one of policy_ref and system_policy is required", i))
+		case o.PolicyRef != "" && !refs[o.PolicyRef]:
+			errs = append(errs, fmt.Errorf("overrides[%d]: unknown policy_ref %q", i, o.PolicyRef))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ConflictStrategy selects what ImportPolicies does with a policy whose name already
+// exists in the target tenant, or an override of a policy that already has one.
+type ConflictStrategy string
+
+const (
+	// ConflictSkip keeps the existing resource. Overrides in the archive then apply to
+	// the existing policy.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictOverwrite replaces the existing resource with the archived one
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// ConflictRename imports the policy under a new name: the name with
+	// ImportOptions.RenameSuffix, then a number, appended. Overrides cannot be renamed
+	// and are skipped.
+	ConflictRename ConflictStrategy = "rename"
+)
+
+// ImportOptions configures ImportPolicies.
+type ImportOptions struct {
+	// OnConflict is the conflict strategy (default: ConflictSkip)
+	OnConflict ConflictStrategy
+	// RenameSuffix is appended to renamed policies (default: "-imported")
+	RenameSuffix string
+	// OrganizationID is the organization of imported organization-tier policies
+	// (Enterprise)
+	OrganizationID string
+	// DryRun reports what would be imported without changing anything
+	DryRun bool
+}
+
+// ImportStatus is the outcome of importing one resource.
+type ImportStatus string
+
+const (
+	ImportCreated     ImportStatus = "created"
+	ImportOverwritten ImportStatus = "overwritten"
+	ImportRenamed     ImportStatus = "renamed"
+	ImportSkipped     ImportStatus = "skipped"
+	ImportFailed      ImportStatus = "failed"
+)
+
+// ImportResult is the outcome of importing one archived resource.
+type ImportResult struct {
+	Kind ResourceKind `json:"kind"`
+	Ref  string       `json:"ref,omitempty"` // Archive ref; for overrides, of the policy
+	// Name is the name in the target tenant; for overrides, of the overridden policy
+	Name string `json:"name"`
+	// Original is the archived name of a renamed policy
+	Original string       `json:"original,omitempty"`
+	ID       string       `json:"id,omitempty"` // ID in the target tenant (empty in a dry run)
+	Status   ImportStatus `json:"status"`
+	Err      error        `json:"-"`
+}
+
+// ImportReport describes what ImportPolicies did.
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Results []ImportResult `json:"results"`
+	// IDs maps the archive refs of the imported policies to their IDs in the target
+	// tenant, whether created, overwritten or kept.
+	IDs map[string]string `json:"ids"`
+}
+
+// Count returns the number of resources with the given status.
+func (r *ImportReport) Count(status ImportStatus) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// ImportPolicies imports an archive into the client's tenant: static policies first,
+// then dynamic policies, then overrides, which are remapped to the IDs of the policies
+// in the target tenant. Resources that fail are reported and the import continues;
+// the error joins their errors.
+//
+//	archive, err := staging.ExportPolicies(ctx, nil)
+//	report, err := production.ImportPolicies(ctx, archive, axonflow.ImportOptions{
+//	    OnConflict: axonflow.ConflictOverwrite,
+//	})
+func (c *AxonFlowClient) ImportPolicies(ctx context.Context, archive *PolicyArchive, options ImportOptions) (*ImportReport, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	switch options.OnConflict {
+	case "":
+		options.OnConflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("unknown conflict strategy %q", options.OnConflict)
+	}
+	if options.RenameSuffix == "" {
+		options.RenameSuffix = "-imported"
+	}
+
+	state, err := c.loadPolicyState(ctx, len(archive.Overrides) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &policyImport{client: c, options: options, state: state,
+		report: &ImportReport{DryRun: options.DryRun, IDs: map[string]string{}}}
+	for _, p := range archive.StaticPolicies {
+		imp.static(ctx, p)
+	}
+	for _, p := range archive.DynamicPolicies {
+		imp.dynamic(ctx, p)
+	}
+	for _, o := range archive.Overrides {
+		imp.override(ctx, o)
+	}
+
+	report := imp.report
+	c.logger.InfoContext(ctx, "AxonFlow policies imported",
+		"dry_run", options.DryRun,
+		"created", report.Count(ImportCreated),
+		"overwritten", report.Count(ImportOverwritten),
+		"renamed", report.Count(ImportRenamed),
+		"skipped", report.Count(ImportSkipped),
+		"failed", report.Count(ImportFailed))
+	return report, errors.Join(imp.errs...)
+}
+
+// policyImport is the state of one ImportPolicies call.
+type policyImport struct {
+	client  *AxonFlowClient
+	options ImportOptions
+	state   *policyState
+	report  *ImportReport
+	errs    []error
+}
+
+// finish records the result of importing one resource.
+func (imp *policyImport) finish(result ImportResult, err error) {
+	if err != nil {
+		result.Status = ImportFailed
+		result.Err = err
+		imp.errs = append(imp.errs, fmt.Errorf("failed to import %s %q: %w", result.Kind, result.Name, err))
+	} else if result.Ref != "" && result.ID != "" && result.Kind != ResourceOverride {
+		imp.report.IDs[result.Ref] = result.ID
+	}
+	imp.report.Results = append(imp.report.Results, result)
+}
+
+// rename returns name with the rename suffix, numbered until it is not taken.
+func (imp *policyImport) rename(name string, taken func(string) bool) string {
+	renamed := name + imp.options.RenameSuffix
+	for n := 2; taken(renamed); n++ {
+		renamed = name + imp.options.RenameSuffix + "-" + strconv.Itoa(n)
+	}
+	return renamed
+}
+
+func (imp *policyImport) static(ctx context.Context, p ArchivedStaticPolicy) {
+	c := imp.client
+	result := ImportResult{Kind: ResourceStaticPolicy, Ref: p.Ref, Name: p.Name, Status: ImportCreated}
+	taken := func(name string) bool {
+		_, tenant := imp.state.static[name]
+		_, system := imp.state.system[name]
+		return tenant || system
+	}
+
+	if taken(p.Name) {
+		existing, ok := imp.state.static[p.Name]
+		switch imp.options.OnConflict {
+		case ConflictSkip:
+			result.Status = ImportSkipped
+			result.ID = existing.ID
+			if !ok {
+				result.ID = imp.state.system[p.Name].ID
+			}
+			imp.finish(result, nil)
+			return
+		case ConflictOverwrite:
+			if !ok {
+				imp.finish(result, fmt.Errorf("a system policy has this name"))
+				return
+			}
+			result.Status = ImportOverwritten
+			result.ID = existing.ID
+			if !imp.options.DryRun {
+				_, err := c.UpdateStaticPolicyContext(ctx, existing.ID, &UpdateStaticPolicyRequest{
+					Description: &p.Description,
+					Category:    &p.Category,
+					Pattern:     &p.Pattern,
+					Severity:    &p.Severity,
+					Enabled:     &p.Enabled,
+					Action:      &p.Action,
+				})
+				imp.finish(result, err)
+				return
+			}
+			imp.finish(result, nil)
+			return
+		default:
+			result.Status = ImportRenamed
+			result.Original = p.Name
+			result.Name = imp.rename(p.Name, taken)
+		}
+	}
+
+	// Reserve the name for later policies in the archive
+	imp.state.static[result.Name] = StaticPolicy{Name: result.Name}
+	if imp.options.DryRun {
+		imp.finish(result, nil)
+		return
+	}
+	policy, err := c.CreateStaticPolicyContext(ctx, &CreateStaticPolicyRequest{
+		Name:           result.Name,
+		Description:    p.Description,
+		Category:       p.Category,
+		Tier:           p.Tier,
+		OrganizationID: imp.options.OrganizationID,
+		Pattern:        p.Pattern,
+		Severity:       p.Severity,
+		Enabled:        p.Enabled,
+		Action:         p.Action,
+	})
+	if err == nil {
+		result.ID = policy.ID
+		imp.state.static[result.Name] = *policy
+	}
+	imp.finish(result, err)
+}
+
+func (imp *policyImport) dynamic(ctx context.Context, p ArchivedDynamicPolicy) {
+	c := imp.client
+	result := ImportResult{Kind: ResourceDynamicPolicy, Ref: p.Ref, Name: p.Name, Status: ImportCreated}
+	taken := func(name string) bool {
+		_, ok := imp.state.dynamic[name]
+		return ok
+	}
+
+	if existing, ok := imp.state.dynamic[p.Name]; ok {
+		switch imp.options.OnConflict {
+		case ConflictSkip:
+			result.Status = ImportSkipped
+			result.ID = existing.ID
+			imp.finish(result, nil)
+			return
+		case ConflictOverwrite:
+			result.Status = ImportOverwritten
+			result.ID = existing.ID
+			if !imp.options.DryRun {
+				_, err := c.UpdateDynamicPolicyContext(ctx, existing.ID, &UpdateDynamicPolicyRequest{
+					Description: &p.Description,
+					Type:        &p.Type,
+					Category:    &p.Category,
+					Conditions:  p.Conditions,
+					Actions:     p.Actions,
+					Priority:    &p.Priority,
+					Enabled:     &p.Enabled,
+				})
+				imp.finish(result, err)
+				return
+			}
+			imp.finish(result, nil)
+			return
+		default:
+			result.Status = ImportRenamed
+			result.Original = p.Name
+			result.Name = imp.rename(p.Name, taken)
+		}
+	}
+
+	imp.state.dynamic[result.Name] = DynamicPolicy{Name: result.Name}
+	if imp.options.DryRun {
+		imp.finish(result, nil)
+		return
+	}
+	policy, err := c.CreateDynamicPolicyContext(ctx, &CreateDynamicPolicyRequest{
+		Name:        result.Name,
+		Description: p.Description,
+		Type:        p.Type,
+		Category:    p.Category,
+		Conditions:  p.Conditions,
+		Actions:     p.Actions,
+		Priority:    p.Priority,
+		Enabled:     p.Enabled,
+	})
+	if err == nil {
+		result.ID = policy.ID
+		imp.state.dynamic[result.Name] = *policy
+	}
+	imp.finish(result, err)
+}
+
+func (imp *policyImport) override(ctx context.Context, o ArchivedOverride) {
+	c := imp.client
+	result := ImportResult{Kind: ResourceOverride, Ref: o.PolicyRef, Name: o.SystemPolicy, Status: ImportCreated}
+
+	// Remap the overridden policy to its ID in the target tenant
+	if o.PolicyRef != "" {
+		for _, r := range imp.report.Results {
+			if r.Kind == ResourceStaticPolicy && r.Ref == o.PolicyRef {
+				result.Name = r.Name
+				break
+			}
+		}
+		result.ID = imp.report.IDs[o.PolicyRef]
+		if result.ID == "" && !imp.options.DryRun {
+			imp.finish(result, fmt.Errorf("policy %s was not imported", o.PolicyRef))
+			return
+		}
+	} else {
+		system, ok := imp.state.system[o.SystemPolicy]
+		if !ok {
+			imp.finish(result, fmt.Errorf("no such system policy"))
+			return
+		}
+		result.ID = system.ID
+	}
+
+	if _, exists := imp.state.overrides[result.ID]; exists && result.ID != "" {
+		switch imp.options.OnConflict {
+		case ConflictOverwrite:
+			result.Status = ImportOverwritten
+		default:
+			result.Status = ImportSkipped
+			imp.finish(result, nil)
+			return
+		}
+	}
+	if imp.options.DryRun {
+		imp.finish(result, nil)
+		return
+	}
+
+	if result.Status == ImportOverwritten {
+		if err := c.DeletePolicyOverrideContext(ctx, result.ID); err != nil {
+			imp.finish(result, err)
+			return
+		}
+	}
+	_, err := c.CreatePolicyOverrideContext(ctx, result.ID, &CreatePolicyOverrideRequest{
+		Action:    o.Action,
+		Reason:    o.Reason,
+		ExpiresAt: o.ExpiresAt,
+	})
+	imp.finish(result, err)
+}
@@ -0,0 +1,213 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exportTestArchive exports the bundle test policies with an override of a tenant
+// policy and one of a system policy.
+func exportTestArchive(t *testing.T) *PolicyArchive {
+	t.Helper()
+	static, dynamic := serverPolicies()
+	store, client := newPolicyStore(t, static, dynamic)
+	store.overrides["sp-hosts"] = PolicyOverride{PolicyID: "sp-hosts", Action: OverrideActionBlock, Reason: "incident", Active: true}
+	store.overrides["sys-email"] = PolicyOverride{PolicyID: "sys-email", Action: OverrideActionWarn, Reason: "support", Active: true}
+
+	archive, err := client.ExportPolicies(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func TestExportPolicies(t *testing.T) {
+	archive := exportTestArchive(t)
+	data, _ := json.Marshal(archive)
+	for _, leaked := range []string{"sp-hosts", "sys-email", "dp-eu", "created_at", `"id"`} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("expected %s to be stripped from %s", leaked, data)
+		}
+	}
+
+	parsed, err := ParsePolicyArchive(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Format != "axonflow-policy-archive" || parsed.Version != 1 || parsed.Source.Tenant != "tenant" || parsed.Source.SDKVersion != SDKVersion {
+		t.Errorf("unexpected header: %+v", parsed)
+	}
+	var names []string
+	for _, p := range parsed.StaticPolicies {
+		names = append(names, p.Ref+"="+p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"static-1=internal-hosts", "static-2=old-rule"}) {
+		t.Errorf("expected tenant static policies only, got %v", names)
+	}
+	if len(parsed.DynamicPolicies) != 1 || parsed.DynamicPolicies[0].Name != "eu-only" {
+		t.Errorf("expected non-system dynamic policies only, got %+v", parsed.DynamicPolicies)
+	}
+	want := []ArchivedOverride{
+		{PolicyRef: "static-1", Action: OverrideActionBlock, Reason: "incident"},
+		{SystemPolicy: "sys_pii_email", Action: OverrideActionWarn, Reason: "support"},
+	}
+	if !reflect.DeepEqual(parsed.Overrides, want) {
+		t.Errorf("unexpected overrides: %+v", parsed.Overrides)
+	}
+}
+
+func TestExportPoliciesFilter(t *testing.T) {
+	static, dynamic := serverPolicies()
+	store, client := newPolicyStore(t, static, dynamic)
+	store.overrides["sp-old"] = PolicyOverride{PolicyID: "sp-old", Action: OverrideActionBlock, Active: true}
+	store.static[1].Enabled = false
+
+	archive, err := client.ExportPolicies(context.Background(), &PolicyExportFilter{
+		Kinds:           []ResourceKind{ResourceStaticPolicy, ResourceOverride},
+		ExcludeDisabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.StaticPolicies) != 1 || archive.StaticPolicies[0].Name != "internal-hosts" ||
+		len(archive.DynamicPolicies) != 0 || len(archive.Overrides) != 0 {
+		t.Errorf("unexpected archive: %+v", archive)
+	}
+}
+
+func TestParsePolicyArchiveErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"format", `{"format": "zip", "version": 1}`, "not a policy archive"},
+		{"version", `{"format": "axonflow-policy-archive", "version": 9}`, "unsupported policy archive version 9"},
+		{"ref", `{"format": "axonflow-policy-archive", "version": 1, "overrides": [{"policy_ref": "static-9"}]}`, `unknown policy_ref "static-9"`},
+		{"target", `{"format": "axonflow-policy-archive", "version": 1, "overrides": [{}]}`, "exactly one of policy_ref and system_policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePolicyArchive([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// importTarget returns a tenant with a conflicting static policy and override.
+func importTarget(t *testing.T) (*policyStore, *AxonFlowClient) {
+	t.Helper()
+	store, client := newPolicyStore(t, []StaticPolicy{
+		{ID: "prod-sys-email", Name: "sys_pii_email", Tier: TierSystem, Category: CategoryPIIGlobal, Pattern: "@", Enabled: true},
+		{ID: "prod-hosts", Name: "internal-hosts", Tier: TierTenant, Category: CategorySecuritySQLI, Pattern: "old", Enabled: true},
+	}, nil)
+	store.overrides["prod-sys-email"] = PolicyOverride{PolicyID: "prod-sys-email", Action: OverrideActionLog, Active: true}
+	return store, client
+}
+
+func TestImportPolicies(t *testing.T) {
+	archive := exportTestArchive(t)
+
+	tests := []struct {
+		strategy ConflictStrategy
+		statuses []ImportStatus
+		calls    []string
+		hostsRef string
+	}{
+		{ConflictSkip,
+			[]ImportStatus{ImportSkipped, ImportCreated, ImportCreated, ImportCreated, ImportSkipped},
+			[]string{
+				"POST /api/v1/static-policies",
+				"POST /api/v1/dynamic-policies",
+				"POST /api/v1/static-policies/prod-hosts/override",
+			},
+			"prod-hosts"},
+		{ConflictOverwrite,
+			[]ImportStatus{ImportOverwritten, ImportCreated, ImportCreated, ImportCreated, ImportOverwritten},
+			[]string{
+				"PUT /api/v1/static-policies/prod-hosts",
+				"POST /api/v1/static-policies",
+				"POST /api/v1/dynamic-policies",
+				"POST /api/v1/static-policies/prod-hosts/override",
+				"DELETE /api/v1/static-policies/prod-sys-email/override",
+				"POST /api/v1/static-policies/prod-sys-email/override",
+			},
+			"prod-hosts"},
+		{ConflictRename,
+			[]ImportStatus{ImportRenamed, ImportCreated, ImportCreated, ImportCreated, ImportSkipped},
+			[]string{
+				"POST /api/v1/static-policies",
+				"POST /api/v1/static-policies",
+				"POST /api/v1/dynamic-policies",
+				"POST /api/v1/static-policies/sp-1/override",
+			},
+			"sp-1"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			store, client := importTarget(t)
+			report, err := client.ImportPolicies(context.Background(), archive, ImportOptions{OnConflict: tt.strategy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var statuses []ImportStatus
+			for _, r := range report.Results {
+				statuses = append(statuses, r.Status)
+			}
+			if !reflect.DeepEqual(statuses, tt.statuses) {
+				t.Errorf("unexpected statuses %v, want %v", statuses, tt.statuses)
+			}
+			if !reflect.DeepEqual(store.calls, tt.calls) {
+				t.Errorf("unexpected calls:\n%v\nwant:\n%v", store.calls, tt.calls)
+			}
+			if report.IDs["static-1"] != tt.hostsRef || report.Results[3].ID != tt.hostsRef {
+				t.Errorf("expected static-1 and its override mapped to %s, got %v", tt.hostsRef, report.IDs)
+			}
+		})
+	}
+}
+
+func TestImportPoliciesRenameAndDryRun(t *testing.T) {
+	archive := exportTestArchive(t)
+	store, client := importTarget(t)
+	store.static = append(store.static, StaticPolicy{ID: "prod-taken", Name: "internal-hosts-copy", Tier: TierTenant})
+
+	report, err := client.ImportPolicies(context.Background(), archive, ImportOptions{
+		OnConflict:   ConflictRename,
+		RenameSuffix: "-copy",
+		DryRun:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := report.Results[0]; r.Name != "internal-hosts-copy-2" || r.Original != "internal-hosts" || r.Status != ImportRenamed {
+		t.Errorf("unexpected rename: %+v", r)
+	}
+	if len(store.calls) != 0 || len(report.IDs) != 0 || !report.DryRun {
+		t.Errorf("expected nothing imported in a dry run, got %v", store.calls)
+	}
+
+	if _, err := client.ImportPolicies(context.Background(), archive, ImportOptions{OnConflict: "merge"}); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestImportPoliciesFailure(t *testing.T) {
+	archive := exportTestArchive(t)
+	store, client := newPolicyStore(t, nil, nil)
+	store.fail = "POST /api/v1/static-policies"
+
+	report, err := client.ImportPolicies(context.Background(), archive, ImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), `failed to import static_policy "internal-hosts"`) {
+		t.Errorf("expected the create error, got %v", err)
+	}
+	// The dynamic policy is still imported; the overrides have no policy to apply to
+	if report.Count(ImportFailed) != 4 || report.Count(ImportCreated) != 1 {
+		for _, r := range report.Results {
+			t.Logf("%s %q: %s %v", r.Kind, r.Name, r.Status, r.Err)
+		}
+		t.Errorf("unexpected report")
+	}
+}
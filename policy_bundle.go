@@ -0,0 +1,820 @@
+// Policy-as-code: declarative policy bundles synced with plan and apply
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// policyBundleVersion is the bundle format version written and understood by the SDK.
+const policyBundleVersion = 1
+
+// PolicyBundle declares the tenant's policies. Resources are identified by name, so
+// a bundle can be applied to several environments whose policy IDs differ.
+//
+// A bundle file is JSON:
+//
+//	{
+//	  "version": 1,
+//	  "static_policies": [
+//	    {"name": "block-internal-hosts", "category": "security-sqli", "pattern": "internal\\.corp",
+//	     "severity": "high", "action": "block"}
+//	  ],
+//	  "dynamic_policies": [
+//	    {"name": "eu-only", "type": "content", "priority": 10,
+//	     "actions": [{"type": "route", "config": {"allowed_providers": ["azure-eu"]}}]}
+//	  ],
+//	  "overrides": [
+//	    {"policy": "sys_pii_email", "action_override": "warn", "override_reason": "support desk"}
+//	  ]
+//	}
+//
+// Policies are enabled unless "enabled" is false. IDs, tiers, versions and timestamps
+// in a bundle are ignored.
+type PolicyBundle struct {
+	Version         int              `json:"version"`
+	StaticPolicies  []StaticPolicy   `json:"static_policies,omitempty"`
+	DynamicPolicies []DynamicPolicy  `json:"dynamic_policies,omitempty"`
+	Overrides       []BundleOverride `json:"overrides,omitempty"`
+}
+
+// BundleOverride declares a PolicyOverride. Policy is the name or ID of the static
+// policy, which may be a system policy or one declared in the bundle.
+type BundleOverride struct {
+	Policy    string         `json:"policy"`
+	Action    OverrideAction `json:"action_override"`
+	Reason    string         `json:"override_reason"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+}
+
+// rawPolicyBundle is a bundle file before defaults are applied.
+type rawPolicyBundle struct {
+	Version         int               `json:"version"`
+	StaticPolicies  []json.RawMessage `json:"static_policies"`
+	DynamicPolicies []json.RawMessage `json:"dynamic_policies"`
+	Overrides       []BundleOverride  `json:"overrides"`
+}
+
+// ParsePolicyBundle parses and validates a bundle file.
+func ParsePolicyBundle(data []byte) (*PolicyBundle, error) {
+	var raw rawPolicyBundle
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse policy bundle: %w", err)
+	}
+	if raw.Version != policyBundleVersion {
+		return nil, fmt.Errorf("unsupported policy bundle version %d (expected %d)", raw.Version, policyBundleVersion)
+	}
+
+	bundle := &PolicyBundle{Version: raw.Version, Overrides: raw.Overrides}
+	for i, data := range raw.StaticPolicies {
+		var p StaticPolicy
+		if err := decodeBundlePolicy(data, &p, &p.Enabled); err != nil {
+			return nil, fmt.Errorf("static_policies[%d]: %w", i, err)
+		}
+		bundle.StaticPolicies = append(bundle.StaticPolicies, p)
+	}
+	for i, data := range raw.DynamicPolicies {
+		var p DynamicPolicy
+		if err := decodeBundlePolicy(data, &p, &p.Enabled); err != nil {
+			return nil, fmt.Errorf("dynamic_policies[%d]: %w", i, err)
+		}
+		bundle.DynamicPolicies = append(bundle.DynamicPolicies, p)
+	}
+
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// decodeBundlePolicy decodes a policy, setting enabled unless the policy disables
+// itself.
+func decodeBundlePolicy(data []byte, policy interface{}, enabled *bool) error {
+	*enabled = true
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(policy)
+}
+
+// LoadPolicyBundle reads bundle files and merges them into one bundle. Each path is a
+// JSON file or a directory, of which all *.json files are read in name order.
+func LoadPolicyBundle(paths ...string) (*PolicyBundle, error) {
+	merged := &PolicyBundle{Version: policyBundleVersion}
+	for _, path := range paths {
+		files := []string{path}
+		if info, err := os.Stat(path); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+				return nil, err
+			}
+			sort.Strings(files)
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			bundle, err := ParsePolicyBundle(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			merged.StaticPolicies = append(merged.StaticPolicies, bundle.StaticPolicies...)
+			merged.DynamicPolicies = append(merged.DynamicPolicies, bundle.DynamicPolicies...)
+			merged.Overrides = append(merged.Overrides, bundle.Overrides...)
+		}
+	}
+
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Validate checks the bundle for missing fields and duplicate names. All problems are
+// reported, joined with errors.Join.
+func (b *PolicyBundle) Validate() error {
+	var errs []error
+	names := map[string]bool{}
+	for i, p := range b.StaticPolicies {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("static_policies[%d]: name is required", i))
+		case names[p.Name]:
+			errs = append(errs, fmt.Errorf("static policy %q is declared twice", p.Name))
+		}
+		names[p.Name] = true
+		if p.Pattern == "" {
+			errs = append(errs, fmt.Errorf("static policy %q: pattern is required", p.Name))
+		}
+		if p.Category == "" {
+			errs = append(errs, fmt.Errorf("static policy %q: category is required", p.Name))
+		}
+	}
+
+	names = map[string]bool{}
+	for i, p := range b.DynamicPolicies {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("dynamic_policies[%d]: name is required", i))
+		case names[p.Name]:
+			errs = append(errs, fmt.Errorf("dynamic policy %q is declared twice", p.Name))
+		}
+		names[p.Name] = true
+		if p.Type == "" {
+			errs = append(errs, fmt.Errorf("dynamic policy %q: type is required", p.Name))
+		}
+	}
+
+	names = map[string]bool{}
+	for i, o := range b.Overrides {
+		switch {
+		case o.Policy == "":
+			errs = append(errs, fmt.Errorf("overrides[%d]: policy is required", i))
+		case names[o.Policy]:
+			errs = append(errs, fmt.Errorf("override of %q is declared twice", o.Policy))
+		}
+		names[o.Policy] = true
+		if o.Action == "" {
+			errs = append(errs, fmt.Errorf("override of %q: action_override is required", o.Policy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ChangeAction is what a PolicyChange does.
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+// ResourceKind is the kind of resource a PolicyChange affects.
+type ResourceKind string
+
+const (
+	ResourceStaticPolicy  ResourceKind = "static_policy"
+	ResourceDynamicPolicy ResourceKind = "dynamic_policy"
+	ResourceOverride      ResourceKind = "override"
+)
+
+// PolicyChange is one change needed to bring the server in line with a bundle.
+type PolicyChange struct {
+	Action ChangeAction `json:"action"`
+	Kind   ResourceKind `json:"kind"`
+	// Name is the policy name; for overrides, the name of the overridden policy
+	Name string `json:"name"`
+	// ID is the ID of the existing resource (for overrides, of the overridden policy).
+	// After Apply creates a resource it is the new ID.
+	ID string `json:"id,omitempty"`
+	// Fields lists the fields an update changes
+	Fields []string `json:"fields,omitempty"`
+
+	static   *StaticPolicy
+	dynamic  *DynamicPolicy
+	override *BundleOverride
+}
+
+func (c PolicyChange) String() string {
+	sign := map[ChangeAction]string{ChangeCreate: "+", ChangeUpdate: "~", ChangeDelete: "-"}[c.Action]
+	s := fmt.Sprintf("%s %s %q", sign, c.Kind, c.Name)
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// PolicyPlan lists the changes needed to bring the server in line with a bundle, in
+// the order Apply makes them: static policies, dynamic policies and overrides are
+// created and updated first, then overrides, dynamic policies and static policies
+// are deleted. Within a kind, changes are ordered by name.
+type PolicyPlan struct {
+	Changes   []PolicyChange `json:"changes"`
+	Unchanged int            `json:"unchanged"` // Declared resources already up to date
+}
+
+// HasChanges reports whether the plan changes anything.
+func (p *PolicyPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// String lists the changes, one per line: "+" creates, "~" updates, "-" deletes.
+func (p *PolicyPlan) String() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d to change, %d unchanged\n", len(p.Changes), p.Unchanged)
+	return b.String()
+}
+
+// policyState is the server's current policies, as far as a bundle manages them.
+type policyState struct {
+	static    map[string]StaticPolicy  // Tenant and organization policies, by name
+	system    map[string]StaticPolicy  // System policies, by name
+	byID      map[string]StaticPolicy  // All static policies, by ID
+	dynamic   map[string]DynamicPolicy // Non-system dynamic policies, by name
+	overrides map[string]PolicyOverride
+}
+
+// policyPageSize is the number of policies loadPolicyState lists per request.
+const policyPageSize = 100
+
+// loadPolicyState lists the current policies, page by page. Overrides are only listed
+// when withOverrides is set; servers without overrides are treated as having none.
+func (c *AxonFlowClient) loadPolicyState(ctx context.Context, withOverrides bool) (*policyState, error) {
+	state := &policyState{
+		static:    map[string]StaticPolicy{},
+		system:    map[string]StaticPolicy{},
+		byID:      map[string]StaticPolicy{},
+		dynamic:   map[string]DynamicPolicy{},
+		overrides: map[string]PolicyOverride{},
+	}
+
+	var static []StaticPolicy
+	for {
+		page, err := c.ListStaticPoliciesContext(ctx, &ListStaticPoliciesOptions{Limit: policyPageSize, Offset: len(static)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list static policies: %w", err)
+		}
+		static = append(static, page...)
+		if len(page) < policyPageSize {
+			break
+		}
+	}
+	for _, p := range static {
+		state.byID[p.ID] = p
+		names := state.static
+		if p.Tier == TierSystem {
+			names = state.system
+		}
+		if _, dup := names[p.Name]; dup {
+			return nil, fmt.Errorf("static policy name %q is not unique on the server", p.Name)
+		}
+		names[p.Name] = p
+	}
+
+	var dynamic []DynamicPolicy
+	for {
+		page, err := c.ListDynamicPoliciesContext(ctx, &ListDynamicPoliciesOptions{Limit: policyPageSize, Offset: len(dynamic)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dynamic policies: %w", err)
+		}
+		dynamic = append(dynamic, page...)
+		if len(page) < policyPageSize {
+			break
+		}
+	}
+	for _, p := range dynamic {
+		if p.Tier == string(TierSystem) {
+			continue
+		}
+		if _, dup := state.dynamic[p.Name]; dup {
+			return nil, fmt.Errorf("dynamic policy name %q is not unique on the server", p.Name)
+		}
+		state.dynamic[p.Name] = p
+	}
+
+	if withOverrides {
+		overrides, err := c.ListPolicyOverridesContext(ctx)
+		if err != nil && !errors.Is(err, ErrFeatureUnavailable) && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("failed to list policy overrides: %w", err)
+		}
+		for _, o := range overrides {
+			state.overrides[o.PolicyID] = o
+		}
+	}
+	return state, nil
+}
+
+// resolve returns the static policy an override refers to, by name or ID.
+func (s *policyState) resolve(policy string) (StaticPolicy, bool) {
+	if p, ok := s.static[policy]; ok {
+		return p, true
+	}
+	if p, ok := s.system[policy]; ok {
+		return p, true
+	}
+	p, ok := s.byID[policy]
+	return p, ok
+}
+
+// PlanPolicyBundle compares bundle with the server's policies and returns the changes
+// needed to apply it. Tenant and organization static policies, non-system dynamic
+// policies and overrides that the bundle does not declare are planned for deletion;
+// ApplyPolicyBundle only deletes them with ApplyOptions.Prune.
+//
+// Static policy overrides need Enterprise. With a bundle without overrides, servers
+// without them are fine.
+func (c *AxonFlowClient) PlanPolicyBundle(ctx context.Context, bundle *PolicyBundle) (*PolicyPlan, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	state, err := c.loadPolicyState(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return planPolicyBundle(bundle, state)
+}
+
+func planPolicyBundle(bundle *PolicyBundle, state *policyState) (*PolicyPlan, error) {
+	plan := &PolicyPlan{}
+	var deletes []PolicyChange
+
+	// Static policies
+	declared := map[string]bool{}
+	for _, i := range sortedIndexes(len(bundle.StaticPolicies), func(i int) string { return bundle.StaticPolicies[i].Name }) {
+		p := &bundle.StaticPolicies[i]
+		declared[p.Name] = true
+		if _, ok := state.system[p.Name]; ok {
+			return nil, fmt.Errorf("static policy %q is a system policy; declare an override instead", p.Name)
+		}
+		current, ok := state.static[p.Name]
+		if !ok {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeCreate, Kind: ResourceStaticPolicy, Name: p.Name, static: p})
+		} else if fields := staticPolicyDiff(&current, p); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeUpdate, Kind: ResourceStaticPolicy, Name: p.Name, ID: current.ID, Fields: fields, static: p})
+		} else {
+			plan.Unchanged++
+		}
+	}
+	for _, name := range sortedNames(state.static) {
+		if !declared[name] {
+			deletes = append(deletes, PolicyChange{Action: ChangeDelete, Kind: ResourceStaticPolicy, Name: name, ID: state.static[name].ID})
+		}
+	}
+
+	// Dynamic policies
+	declared = map[string]bool{}
+	for _, i := range sortedIndexes(len(bundle.DynamicPolicies), func(i int) string { return bundle.DynamicPolicies[i].Name }) {
+		p := &bundle.DynamicPolicies[i]
+		declared[p.Name] = true
+		current, ok := state.dynamic[p.Name]
+		if !ok {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeCreate, Kind: ResourceDynamicPolicy, Name: p.Name, dynamic: p})
+		} else if fields := dynamicPolicyDiff(&current, p); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeUpdate, Kind: ResourceDynamicPolicy, Name: p.Name, ID: current.ID, Fields: fields, dynamic: p})
+		} else {
+			plan.Unchanged++
+		}
+	}
+	var dynamicDeletes []PolicyChange
+	for _, name := range sortedNames(state.dynamic) {
+		if !declared[name] {
+			dynamicDeletes = append(dynamicDeletes, PolicyChange{Action: ChangeDelete, Kind: ResourceDynamicPolicy, Name: name, ID: state.dynamic[name].ID})
+		}
+	}
+	deletes = append(dynamicDeletes, deletes...)
+
+	// Overrides, keyed by the overridden policy's ID. Policies the bundle creates have
+	// no ID yet.
+	declared = map[string]bool{}
+	for _, i := range sortedIndexes(len(bundle.Overrides), func(i int) string { return bundle.Overrides[i].Policy }) {
+		o := &bundle.Overrides[i]
+		target, ok := state.resolve(o.Policy)
+		if !ok {
+			if !bundleDeclares(bundle, o.Policy) {
+				return nil, fmt.Errorf("override of %q: no such static policy", o.Policy)
+			}
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeCreate, Kind: ResourceOverride, Name: o.Policy, override: o})
+			continue
+		}
+		declared[target.ID] = true
+		current, ok := state.overrides[target.ID]
+		if !ok {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeCreate, Kind: ResourceOverride, Name: target.Name, ID: target.ID, override: o})
+		} else if fields := overrideDiff(&current, o); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, PolicyChange{Action: ChangeUpdate, Kind: ResourceOverride, Name: target.Name, ID: target.ID, Fields: fields, override: o})
+		} else {
+			plan.Unchanged++
+		}
+	}
+	var overrideDeletes []PolicyChange
+	for _, id := range sortedNames(state.overrides) {
+		if !declared[id] {
+			name := id
+			if p, ok := state.byID[id]; ok {
+				name = p.Name
+			}
+			overrideDeletes = append(overrideDeletes, PolicyChange{Action: ChangeDelete, Kind: ResourceOverride, Name: name, ID: id})
+		}
+	}
+	deletes = append(overrideDeletes, deletes...)
+
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// bundleDeclares reports whether the bundle declares a static policy named name.
+func bundleDeclares(bundle *PolicyBundle, name string) bool {
+	for _, p := range bundle.StaticPolicies {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedIndexes returns the indexes 0..n-1 ordered by key.
+func sortedIndexes(n int, key func(i int) string) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool { return key(indexes[a]) < key(indexes[b]) })
+	return indexes
+}
+
+// sortedNames returns the keys of a policy map in order.
+func sortedNames(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.String()
+	}
+	sort.Strings(names)
+	return names
+}
+
+// staticPolicyDiff returns the fields of want that differ from current. Severity and
+// action are only compared when want sets them.
+func staticPolicyDiff(current, want *StaticPolicy) []string {
+	var fields []string
+	if current.Description != want.Description {
+		fields = append(fields, "description")
+	}
+	if current.Category != want.Category {
+		fields = append(fields, "category")
+	}
+	if current.Pattern != want.Pattern {
+		fields = append(fields, "pattern")
+	}
+	if want.Severity != "" && current.Severity != want.Severity {
+		fields = append(fields, "severity")
+	}
+	if want.Action != "" && current.Action != want.Action {
+		fields = append(fields, "action")
+	}
+	if current.Enabled != want.Enabled {
+		fields = append(fields, "enabled")
+	}
+	return fields
+}
+
+// dynamicPolicyDiff returns the fields of want that differ from current. Conditions
+// and actions are compared as JSON, so that values built in Go match values decoded
+// from the server.
+func dynamicPolicyDiff(current, want *DynamicPolicy) []string {
+	var fields []string
+	if current.Description != want.Description {
+		fields = append(fields, "description")
+	}
+	if current.Type != want.Type {
+		fields = append(fields, "type")
+	}
+	if want.Category != "" && current.Category != want.Category {
+		fields = append(fields, "category")
+	}
+	if !sameJSON(current.Conditions, want.Conditions) {
+		fields = append(fields, "conditions")
+	}
+	if !sameJSON(current.Actions, want.Actions) {
+		fields = append(fields, "actions")
+	}
+	if current.Priority != want.Priority {
+		fields = append(fields, "priority")
+	}
+	if current.Enabled != want.Enabled {
+		fields = append(fields, "enabled")
+	}
+	return fields
+}
+
+// overrideDiff returns the fields of want that differ from current.
+func overrideDiff(current *PolicyOverride, want *BundleOverride) []string {
+	var fields []string
+	if current.Action != want.Action {
+		fields = append(fields, "action_override")
+	}
+	if current.Reason != want.Reason {
+		fields = append(fields, "override_reason")
+	}
+	if (current.ExpiresAt == nil) != (want.ExpiresAt == nil) ||
+		(current.ExpiresAt != nil && !current.ExpiresAt.Equal(*want.ExpiresAt)) {
+		fields = append(fields, "expires_at")
+	}
+	return fields
+}
+
+// sameJSON reports whether a and b encode to the same JSON, treating empty and nil
+// slices alike.
+func sameJSON(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	if string(ja) == "null" {
+		ja = []byte("[]")
+	}
+	if string(jb) == "null" {
+		jb = []byte("[]")
+	}
+	if bytes.Equal(ja, jb) {
+		return true
+	}
+	// Map keys are sorted by Marshal, but numbers may differ in type: 1 vs 1.0
+	var va, vb interface{}
+	json.Unmarshal(ja, &va)
+	json.Unmarshal(jb, &vb)
+	return reflect.DeepEqual(va, vb)
+}
+
+// ApplyOptions configures ApplyPolicyBundle.
+type ApplyOptions struct {
+	// DryRun plans the changes without making them
+	DryRun bool
+	// Prune deletes policies and overrides the bundle does not declare. Without it,
+	// deletions are reported as skipped.
+	Prune bool
+	// ContinueOnError makes the remaining changes after one fails. By default Apply
+	// stops at the first failure.
+	ContinueOnError bool
+}
+
+// ChangeStatus is the outcome of a PolicyChange in an ApplyReport.
+type ChangeStatus string
+
+const (
+	ChangeApplied ChangeStatus = "applied"
+	ChangeFailed  ChangeStatus = "failed"
+	// ChangeSkipped marks deletions without ApplyOptions.Prune
+	ChangeSkipped ChangeStatus = "skipped"
+	// ChangePending marks changes not made: all of them in a dry run, and those after
+	// a failure
+	ChangePending ChangeStatus = "pending"
+)
+
+// ChangeResult is the outcome of one change.
+type ChangeResult struct {
+	PolicyChange
+	Status ChangeStatus `json:"status"`
+	Err    error        `json:"-"`
+}
+
+// ApplyReport describes what ApplyPolicyBundle did.
+type ApplyReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Results   []ChangeResult `json:"results"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// Count returns the number of changes with the given status.
+func (r *ApplyReport) Count(status ChangeStatus) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// String lists the changes and their status, one per line.
+func (r *ApplyReport) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "%s: %s", result.PolicyChange, result.Status)
+		if result.Err != nil {
+			fmt.Fprintf(&b, ": %v", result.Err)
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d applied, %d failed, %d skipped, %d pending, %d unchanged\n",
+		r.Count(ChangeApplied), r.Count(ChangeFailed), r.Count(ChangeSkipped), r.Count(ChangePending), r.Unchanged)
+	return b.String()
+}
+
+// ApplyPolicyBundle plans bundle against the server's policies and makes the changes,
+// in the order described on PolicyPlan. The report lists every planned change with its
+// outcome; the error joins the errors of the failed changes.
+//
+//	bundle, err := axonflow.LoadPolicyBundle("policies/")
+//	report, err := client.ApplyPolicyBundle(ctx, bundle, axonflow.ApplyOptions{Prune: true})
+//	fmt.Print(report)
+func (c *AxonFlowClient) ApplyPolicyBundle(ctx context.Context, bundle *PolicyBundle, options ApplyOptions) (*ApplyReport, error) {
+	plan, err := c.PlanPolicyBundle(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ApplyReport{DryRun: options.DryRun, Unchanged: plan.Unchanged}
+	created := map[string]string{} // IDs of created static policies, by name
+	var errs []error
+	for _, change := range plan.Changes {
+		result := ChangeResult{PolicyChange: change, Status: ChangePending}
+		switch {
+		case change.Action == ChangeDelete && !options.Prune:
+			result.Status = ChangeSkipped
+		case options.DryRun || (len(errs) > 0 && !options.ContinueOnError):
+		default:
+			if err := c.applyChange(ctx, &result.PolicyChange, created); err != nil {
+				result.Status = ChangeFailed
+				result.Err = err
+				errs = append(errs, fmt.Errorf("failed to %s %s %q: %w", change.Action, change.Kind, change.Name, err))
+			} else {
+				result.Status = ChangeApplied
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	c.logger.InfoContext(ctx, "AxonFlow policy bundle applied",
+		"dry_run", options.DryRun,
+		"applied", report.Count(ChangeApplied),
+		"failed", report.Count(ChangeFailed),
+		"skipped", report.Count(ChangeSkipped),
+		"unchanged", report.Unchanged)
+	return report, errors.Join(errs...)
+}
+
+// applyChange makes one change, recording the IDs of created resources.
+func (c *AxonFlowClient) applyChange(ctx context.Context, change *PolicyChange, created map[string]string) error {
+	switch change.Kind {
+	case ResourceStaticPolicy:
+		return c.applyStaticChange(ctx, change, created)
+	case ResourceDynamicPolicy:
+		return c.applyDynamicChange(ctx, change)
+	default:
+		return c.applyOverrideChange(ctx, change, created)
+	}
+}
+
+func (c *AxonFlowClient) applyStaticChange(ctx context.Context, change *PolicyChange, created map[string]string) error {
+	p := change.static
+	switch change.Action {
+	case ChangeCreate:
+		policy, err := c.CreateStaticPolicyContext(ctx, &CreateStaticPolicyRequest{
+			Name:        p.Name,
+			Description: p.Description,
+			Category:    p.Category,
+			Pattern:     p.Pattern,
+			Severity:    p.Severity,
+			Enabled:     p.Enabled,
+			Action:      p.Action,
+		})
+		if err != nil {
+			return err
+		}
+		change.ID = policy.ID
+		created[p.Name] = policy.ID
+		return nil
+	case ChangeUpdate:
+		req := &UpdateStaticPolicyRequest{}
+		for _, field := range change.Fields {
+			switch field {
+			case "description":
+				req.Description = &p.Description
+			case "category":
+				req.Category = &p.Category
+			case "pattern":
+				req.Pattern = &p.Pattern
+			case "severity":
+				req.Severity = &p.Severity
+			case "action":
+				req.Action = &p.Action
+			case "enabled":
+				req.Enabled = &p.Enabled
+			}
+		}
+		_, err := c.UpdateStaticPolicyContext(ctx, change.ID, req)
+		return err
+	default:
+		return c.DeleteStaticPolicyContext(ctx, change.ID)
+	}
+}
+
+func (c *AxonFlowClient) applyDynamicChange(ctx context.Context, change *PolicyChange) error {
+	p := change.dynamic
+	switch change.Action {
+	case ChangeCreate:
+		policy, err := c.CreateDynamicPolicyContext(ctx, &CreateDynamicPolicyRequest{
+			Name:        p.Name,
+			Description: p.Description,
+			Type:        p.Type,
+			Category:    p.Category,
+			Conditions:  p.Conditions,
+			Actions:     p.Actions,
+			Priority:    p.Priority,
+			Enabled:     p.Enabled,
+		})
+		if err != nil {
+			return err
+		}
+		change.ID = policy.ID
+		return nil
+	case ChangeUpdate:
+		req := &UpdateDynamicPolicyRequest{}
+		for _, field := range change.Fields {
+			switch field {
+			case "description":
+				req.Description = &p.Description
+			case "type":
+				req.Type = &p.Type
+			case "category":
+				req.Category = &p.Category
+			case "conditions":
+				req.Conditions = p.Conditions
+			case "actions":
+				req.Actions = p.Actions
+			case "priority":
+				req.Priority = &p.Priority
+			case "enabled":
+				req.Enabled = &p.Enabled
+			}
+		}
+		_, err := c.UpdateDynamicPolicyContext(ctx, change.ID, req)
+		return err
+	default:
+		return c.DeleteDynamicPolicyContext(ctx, change.ID)
+	}
+}
+
+// applyOverrideChange creates, replaces or deletes an override. Overrides cannot be
+// updated in place, so an update deletes the current override first.
+func (c *AxonFlowClient) applyOverrideChange(ctx context.Context, change *PolicyChange, created map[string]string) error {
+	if change.Action == ChangeDelete {
+		return c.DeletePolicyOverrideContext(ctx, change.ID)
+	}
+	if change.ID == "" {
+		// The policy was created by this apply
+		change.ID = created[change.Name]
+		if change.ID == "" {
+			return fmt.Errorf("static policy %q was not created", change.Name)
+		}
+	}
+	if change.Action == ChangeUpdate {
+		if err := c.DeletePolicyOverrideContext(ctx, change.ID); err != nil {
+			return err
+		}
+	}
+	o := change.override
+	_, err := c.CreatePolicyOverrideContext(ctx, change.ID, &CreatePolicyOverrideRequest{
+		Action:    o.Action,
+		Reason:    o.Reason,
+		ExpiresAt: o.ExpiresAt,
+	})
+	return err
+}
@@ -0,0 +1,387 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// policyStore is an in-memory policy API: static policies, overrides and dynamic
+// policies with create, update, delete and list.
+type policyStore struct {
+	mu        sync.Mutex
+	static    []StaticPolicy
+	dynamic   []DynamicPolicy
+	overrides map[string]PolicyOverride
+	nextID    int
+	calls     []string // "METHOD path" of each write
+	fail      string   // "METHOD path" to answer with 500
+	maxPage   int      // Largest page a list returns; 0 for no limit
+	lists     int      // Number of list requests
+}
+
+// page returns the bounds of the requested page of a list of n items.
+func (s *policyStore) page(r *http.Request, n int) (lo, hi int) {
+	s.lists++
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if s.maxPage > 0 && (limit <= 0 || limit > s.maxPage) {
+		limit = s.maxPage
+	}
+	lo, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	lo = min(lo, n)
+	if limit <= 0 {
+		return lo, n
+	}
+	return lo, min(lo+limit, n)
+}
+
+func newPolicyStore(t *testing.T, static []StaticPolicy, dynamic []DynamicPolicy) (*policyStore, *AxonFlowClient) {
+	t.Helper()
+	store := &policyStore{static: static, dynamic: dynamic, overrides: map[string]PolicyOverride{}}
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	client, err := New(server.URL, WithCredentials("tenant", "secret"), WithoutRetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close(context.Background()) })
+	return store, client
+}
+
+func (s *policyStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call := r.Method + " " + r.URL.Path
+	if r.Method != "GET" {
+		s.calls = append(s.calls, call)
+	}
+	if call == s.fail {
+		http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "static-policies" && r.Method == "GET":
+		lo, hi := s.page(r, len(s.static))
+		json.NewEncoder(w).Encode(staticPoliciesResponse{Policies: s.static[lo:hi]})
+	case path == "static-policies/overrides":
+		var list []PolicyOverride
+		for _, o := range s.overrides {
+			list = append(list, o)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"overrides": list, "count": len(list)})
+	case path == "static-policies" && r.Method == "POST":
+		var req CreateStaticPolicyRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.nextID++
+		p := StaticPolicy{ID: fmt.Sprintf("sp-%d", s.nextID), Name: req.Name, Description: req.Description,
+			Category: req.Category, Tier: req.Tier, Pattern: req.Pattern, Severity: req.Severity,
+			Enabled: req.Enabled, Action: req.Action}
+		if p.Severity == "" {
+			p.Severity = SeverityMedium
+		}
+		s.static = append(s.static, p)
+		json.NewEncoder(w).Encode(p)
+	case parts[0] == "static-policies" && len(parts) == 3 && parts[2] == "override":
+		if r.Method == "DELETE" {
+			delete(s.overrides, parts[1])
+			return
+		}
+		var req CreatePolicyOverrideRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		o := PolicyOverride{PolicyID: parts[1], Action: req.Action, Reason: req.Reason, ExpiresAt: req.ExpiresAt, Active: true}
+		s.overrides[parts[1]] = o
+		json.NewEncoder(w).Encode(o)
+	case parts[0] == "static-policies" && len(parts) == 2:
+		for i := range s.static {
+			if s.static[i].ID != parts[1] {
+				continue
+			}
+			if r.Method == "DELETE" {
+				s.static = append(s.static[:i], s.static[i+1:]...)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&s.static[i])
+			json.NewEncoder(w).Encode(s.static[i])
+			return
+		}
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	case path == "dynamic-policies" && r.Method == "GET":
+		lo, hi := s.page(r, len(s.dynamic))
+		json.NewEncoder(w).Encode(dynamicPoliciesResponse{Policies: s.dynamic[lo:hi]})
+	case path == "dynamic-policies" && r.Method == "POST":
+		var p DynamicPolicy
+		json.NewDecoder(r.Body).Decode(&p)
+		s.nextID++
+		p.ID = fmt.Sprintf("dp-%d", s.nextID)
+		p.Tier = "tenant"
+		s.dynamic = append(s.dynamic, p)
+		json.NewEncoder(w).Encode(dynamicPolicyResponse{Policy: p})
+	case parts[0] == "dynamic-policies" && len(parts) == 2:
+		for i := range s.dynamic {
+			if s.dynamic[i].ID != parts[1] {
+				continue
+			}
+			if r.Method == "DELETE" {
+				s.dynamic = append(s.dynamic[:i], s.dynamic[i+1:]...)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&s.dynamic[i])
+			json.NewEncoder(w).Encode(dynamicPolicyResponse{Policy: s.dynamic[i]})
+			return
+		}
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serverPolicies are the policies on the server in bundle tests.
+func serverPolicies() ([]StaticPolicy, []DynamicPolicy) {
+	return []StaticPolicy{
+		{ID: "sys-email", Name: "sys_pii_email", Tier: TierSystem, Category: CategoryPIIGlobal, Pattern: "@", Severity: SeverityHigh, Action: ActionRedact, Enabled: true},
+		{ID: "sp-old", Name: "old-rule", Tier: TierTenant, Category: CategorySecuritySQLI, Pattern: "x", Severity: SeverityLow, Action: ActionLog, Enabled: true},
+		{ID: "sp-hosts", Name: "internal-hosts", Tier: TierTenant, Category: CategorySecuritySQLI, Pattern: `internal\.corp`, Severity: SeverityHigh, Action: ActionWarn, Enabled: true},
+	}, []DynamicPolicy{
+		{ID: "dp-sys", Name: "system-risk", Tier: "system", Type: "risk", Enabled: true},
+		{ID: "dp-eu", Name: "eu-only", Tier: "tenant", Type: "content", Priority: 10, Enabled: true,
+			Actions: []DynamicPolicyAction{{Type: "route", Config: map[string]interface{}{"allowed_providers": []interface{}{"azure-eu"}}}}},
+	}
+}
+
+const testBundle = `{
+  "version": 1,
+  "static_policies": [
+    {"name": "internal-hosts", "category": "security-sqli", "pattern": "internal\\.corp", "severity": "high", "action": "block"},
+    {"name": "project-codes", "category": "pii-global", "pattern": "PRJ-\\d{4}", "action": "redact"}
+  ],
+  "dynamic_policies": [
+    {"name": "eu-only", "type": "content", "priority": 10,
+     "actions": [{"type": "route", "config": {"allowed_providers": ["azure-eu"]}}]},
+    {"name": "budget", "type": "cost", "priority": 5, "enabled": false}
+  ],
+  "overrides": [
+    {"policy": "sys_pii_email", "action_override": "warn", "override_reason": "support desk"},
+    {"policy": "project-codes", "action_override": "log", "override_reason": "pilot"}
+  ]
+}`
+
+func TestParsePolicyBundle(t *testing.T) {
+	bundle, err := ParsePolicyBundle([]byte(testBundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.StaticPolicies) != 2 || !bundle.StaticPolicies[0].Enabled || bundle.DynamicPolicies[1].Enabled {
+		t.Errorf("expected policies enabled unless disabled, got %+v", bundle)
+	}
+
+	tests := []struct {
+		name, data, want string
+	}{
+		{"version", `{"version": 2}`, "unsupported policy bundle version 2"},
+		{"unknown field", `{"version": 1, "static_policies": [{"name": "a", "patern": "x"}]}`, `unknown field "patern"`},
+		{"missing fields", `{"version": 1, "static_policies": [{"name": "a"}], "overrides": [{"policy": "a"}]}`,
+			`static policy "a": pattern is required`},
+		{"duplicate", `{"version": 1, "dynamic_policies": [{"name": "a", "type": "cost"}, {"name": "a", "type": "cost"}]}`,
+			`dynamic policy "a" is declared twice`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicyBundle([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyBundle(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"version": 1, "static_policies": [{"name": "a", "category": "pii-us", "pattern": "a"}]}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"version": 1, "static_policies": [{"name": "b", "category": "pii-us", "pattern": "b"}]}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600)
+	extra := filepath.Join(t.TempDir(), "extra.json")
+	os.WriteFile(extra, []byte(`{"version": 1, "static_policies": [{"name": "a", "category": "pii-us", "pattern": "c"}]}`), 0o600)
+
+	bundle, err := LoadPolicyBundle(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.StaticPolicies) != 2 || bundle.StaticPolicies[0].Name != "a" || bundle.StaticPolicies[1].Name != "b" {
+		t.Errorf("unexpected bundle: %+v", bundle.StaticPolicies)
+	}
+	if _, err := LoadPolicyBundle(dir, extra); err == nil || !strings.Contains(err.Error(), `"a" is declared twice`) {
+		t.Errorf("expected a duplicate across files, got %v", err)
+	}
+}
+
+func TestPlanPolicyBundle(t *testing.T) {
+	static, dynamic := serverPolicies()
+	store, client := newPolicyStore(t, static, dynamic)
+	store.overrides["sp-hosts"] = PolicyOverride{PolicyID: "sp-hosts", Action: OverrideActionLog, Active: true}
+	bundle, _ := ParsePolicyBundle([]byte(testBundle))
+
+	plan, err := client.PlanPolicyBundle(context.Background(), bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `~ static_policy "internal-hosts" (action)
++ static_policy "project-codes"
++ dynamic_policy "budget"
++ override "project-codes"
++ override "sys_pii_email"
+- override "internal-hosts"
+- static_policy "old-rule"
+7 to change, 1 unchanged
+`
+	if got := plan.String(); got != want {
+		t.Errorf("unexpected plan:\n%s\nwant:\n%s", got, want)
+	}
+	if plan.Changes[0].ID != "sp-hosts" || plan.Changes[4].ID != "sys-email" {
+		t.Errorf("expected IDs of existing resources, got %+v", plan.Changes)
+	}
+	if len(store.calls) != 0 {
+		t.Errorf("expected Plan not to change anything, got %v", store.calls)
+	}
+
+	bundle.StaticPolicies = append(bundle.StaticPolicies, StaticPolicy{Name: "sys_pii_email", Category: CategoryPIIGlobal, Pattern: "x"})
+	if _, err := client.PlanPolicyBundle(context.Background(), bundle); err == nil || !strings.Contains(err.Error(), "system policy") {
+		t.Errorf("expected system policies to be rejected, got %v", err)
+	}
+	bundle.StaticPolicies = bundle.StaticPolicies[:2]
+	bundle.Overrides = append(bundle.Overrides, BundleOverride{Policy: "missing", Action: OverrideActionLog})
+	if _, err := client.PlanPolicyBundle(context.Background(), bundle); err == nil || !strings.Contains(err.Error(), "no such static policy") {
+		t.Errorf("expected an unknown override target to be rejected, got %v", err)
+	}
+}
+
+func TestPlanPolicyBundlePages(t *testing.T) {
+	static, dynamic := serverPolicies()
+	// The bundle's policies come after more than two pages of others
+	for i := 0; i < 250; i++ {
+		static = append([]StaticPolicy{{ID: fmt.Sprintf("sp-gen-%d", i), Name: fmt.Sprintf("generated-%d", i),
+			Tier: TierTenant, Category: CategorySecuritySQLI, Pattern: "x", Enabled: true}}, static...)
+	}
+	for i := 0; i < 150; i++ {
+		dynamic = append([]DynamicPolicy{{ID: fmt.Sprintf("dp-gen-%d", i), Name: fmt.Sprintf("generated-%d", i),
+			Tier: "tenant", Type: "risk", Enabled: true}}, dynamic...)
+	}
+	store, client := newPolicyStore(t, static, dynamic)
+	store.maxPage = policyPageSize
+	bundle, _ := ParsePolicyBundle([]byte(testBundle))
+
+	plan, err := client.PlanPolicyBundle(context.Background(), bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[ChangeAction]int{}
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+		if change.Action == ChangeCreate && (change.Name == "internal-hosts" || change.Name == "eu-only") {
+			t.Errorf("expected %q on a later page to be found, got a create", change.Name)
+		}
+	}
+	// project-codes, budget and two overrides; internal-hosts; old-rule and the
+	// generated policies
+	if counts[ChangeCreate] != 4 || counts[ChangeUpdate] != 1 || counts[ChangeDelete] != 1+250+150 {
+		t.Errorf("unexpected plan: %v", counts)
+	}
+	if store.lists != 3+2 {
+		t.Errorf("expected 3 static and 2 dynamic pages, got %d lists", store.lists)
+	}
+}
+
+func TestApplyPolicyBundle(t *testing.T) {
+	static, dynamic := serverPolicies()
+	store, client := newPolicyStore(t, static, dynamic)
+	store.overrides["sp-hosts"] = PolicyOverride{PolicyID: "sp-hosts", Action: OverrideActionLog, Active: true}
+	bundle, _ := ParsePolicyBundle([]byte(testBundle))
+
+	// Dry run
+	report, err := client.ApplyPolicyBundle(context.Background(), bundle, ApplyOptions{DryRun: true, Prune: true})
+	if err != nil || report.Count(ChangePending) != 7 || len(store.calls) != 0 {
+		t.Fatalf("expected nothing applied in a dry run, got %v, %v", report, store.calls)
+	}
+
+	// Without Prune, deletions are skipped
+	report, err = client.ApplyPolicyBundle(context.Background(), bundle, ApplyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(ChangeApplied) != 5 || report.Count(ChangeSkipped) != 2 || report.Unchanged != 1 {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	wantCalls := []string{
+		"PUT /api/v1/static-policies/sp-hosts",
+		"POST /api/v1/static-policies",
+		"POST /api/v1/dynamic-policies",
+		"POST /api/v1/static-policies/sp-1/override",
+		"POST /api/v1/static-policies/sys-email/override",
+	}
+	if !reflect.DeepEqual(store.calls, wantCalls) {
+		t.Errorf("unexpected calls:\n%v\nwant:\n%v", store.calls, wantCalls)
+	}
+	if report.Results[1].ID != "sp-1" || report.Results[3].ID != "sp-1" {
+		t.Errorf("expected the created policy's ID in the report, got %+v", report.Results)
+	}
+
+	// Applying again only deletes, with Prune
+	store.calls = nil
+	report, err = client.ApplyPolicyBundle(context.Background(), bundle, ApplyOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCalls = []string{"DELETE /api/v1/static-policies/sp-hosts/override", "DELETE /api/v1/static-policies/sp-old"}
+	if !reflect.DeepEqual(store.calls, wantCalls) || report.Unchanged != 6 {
+		t.Errorf("unexpected calls %v, report:\n%s", store.calls, report)
+	}
+
+	plan, _ := client.PlanPolicyBundle(context.Background(), bundle)
+	if plan.HasChanges() {
+		t.Errorf("expected no changes after apply, got:\n%s", plan)
+	}
+}
+
+func TestApplyPolicyBundleFailure(t *testing.T) {
+	static, dynamic := serverPolicies()
+	bundle, _ := ParsePolicyBundle([]byte(testBundle))
+
+	for _, continueOnError := range []bool{false, true} {
+		t.Run(fmt.Sprint(continueOnError), func(t *testing.T) {
+			store, client := newPolicyStore(t, append([]StaticPolicy(nil), static...), append([]DynamicPolicy(nil), dynamic...))
+			store.fail = "POST /api/v1/static-policies"
+
+			report, err := client.ApplyPolicyBundle(context.Background(), bundle, ApplyOptions{ContinueOnError: continueOnError})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), `failed to create static_policy "project-codes"`) {
+				t.Errorf("expected the create error, got %v", err)
+			}
+			if report.Results[1].Status != ChangeFailed || report.Results[1].Err == nil {
+				t.Errorf("expected the create to fail, got %+v", report.Results[1])
+			}
+
+			if !continueOnError {
+				if report.Count(ChangeApplied) != 1 || report.Count(ChangePending) != 3 {
+					t.Errorf("expected Apply to stop, got:\n%s", report)
+				}
+				return
+			}
+			// The override of the policy that was not created fails too
+			if report.Count(ChangeApplied) != 3 || report.Count(ChangeFailed) != 2 {
+				t.Errorf("expected Apply to continue, got:\n%s", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,472 @@
+// Policy regression test suites
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultSuiteUser is the user token of test cases that set none.
+const defaultSuiteUser = "axonflow-policy-suite"
+
+// PolicyOutcome is the outcome of evaluating a prompt against the policies.
+type PolicyOutcome string
+
+const (
+	OutcomeAllow  PolicyOutcome = "allow"
+	OutcomeBlock  PolicyOutcome = "block"
+	OutcomeRedact PolicyOutcome = "redact"
+)
+
+// PolicySuite is a set of prompts with the outcome the policies must produce for each.
+// A suite file is JSON:
+//
+//	{
+//	  "name": "pii",
+//	  "cases": [
+//	    {"name": "ssn is redacted", "prompt": "My SSN is 123-45-6789",
+//	     "expect": "redact", "policies": ["sys_pii_ssn"]},
+//	    {"name": "drop table is blocked", "prompt": "'; DROP TABLE users; --",
+//	     "request_type": "sql", "expect": "block"},
+//	    {"name": "weather is allowed", "prompt": "What's the weather in Paris?", "expect": "allow"}
+//	  ]
+//	}
+type PolicySuite struct {
+	Name string `json:"name"`
+	// UserToken is the user token of cases that set none (default: "axonflow-policy-suite")
+	UserToken string           `json:"user_token,omitempty"`
+	Cases     []PolicyTestCase `json:"cases"`
+}
+
+// PolicyTestCase is one prompt of a PolicySuite.
+type PolicyTestCase struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+	// RequestType selects how a live target checks the prompt: "pre-check" (default)
+	// with a Gateway Mode pre-check, anything else ("chat", "sql", ...) with
+	// ExecuteQuery. Local targets ignore it. Query responses do not report redaction
+	// or matched policies, so only pre-check cases may expect redact or set Policies.
+	RequestType string                 `json:"request_type,omitempty"`
+	UserToken   string                 `json:"user_token,omitempty"`
+	DataSources []string               `json:"data_sources,omitempty"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	// Expect is the expected outcome
+	Expect PolicyOutcome `json:"expect"`
+	// Policies are IDs of policies that must be among the matched policies
+	Policies []string `json:"policies,omitempty"`
+}
+
+// ParsePolicySuite parses and validates a suite file.
+func ParsePolicySuite(data []byte) (*PolicySuite, error) {
+	var suite PolicySuite
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("failed to parse policy suite: %w", err)
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// LoadPolicySuite reads a suite file.
+func LoadPolicySuite(path string) (*PolicySuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := ParsePolicySuite(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return suite, nil
+}
+
+// Validate checks that every case has a unique name, a prompt and a valid expected
+// outcome, and that only pre-check cases expect redact or matched policies. All
+// problems are reported, joined with errors.Join.
+func (s *PolicySuite) Validate() error {
+	var errs []error
+	if len(s.Cases) == 0 {
+		errs = append(errs, errors.New("policy suite has no cases"))
+	}
+	names := map[string]bool{}
+	for i, tc := range s.Cases {
+		switch {
+		case tc.Name == "":
+			errs = append(errs, fmt.Errorf("cases[%d]: name is required", i))
+		case names[tc.Name]:
+			errs = append(errs, fmt.Errorf("case %q is declared twice", tc.Name))
+		}
+		names[tc.Name] = true
+		if tc.Prompt == "" {
+			errs = append(errs, fmt.Errorf("case %q: prompt is required", tc.Name))
+		}
+		switch tc.Expect {
+		case OutcomeAllow, OutcomeBlock, OutcomeRedact:
+		default:
+			errs = append(errs, fmt.Errorf("case %q: expect must be allow, block or redact, not %q", tc.Name, tc.Expect))
+		}
+		if tc.RequestType != "" && tc.RequestType != RequestTypePreCheck {
+			if tc.Expect == OutcomeRedact {
+				errs = append(errs, fmt.Errorf("case %q: only pre-check cases can expect redact", tc.Name))
+			}
+			if len(tc.Policies) > 0 {
+				errs = append(errs, fmt.Errorf("case %q: only pre-check cases can expect matched policies", tc.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PolicyObservation is what a PolicyTarget observed for a test case.
+type PolicyObservation struct {
+	Outcome  PolicyOutcome `json:"outcome"`
+	Policies []string      `json:"policies,omitempty"` // Matched or reported policy IDs
+	Reason   string        `json:"reason,omitempty"`   // Block reason
+}
+
+// PolicyTarget evaluates the prompts of a PolicySuite.
+type PolicyTarget interface {
+	// Name describes the target in reports
+	Name() string
+	// Check evaluates one test case. An error means the case could not be evaluated.
+	Check(ctx context.Context, tc PolicyTestCase) (*PolicyObservation, error)
+}
+
+// liveTarget checks test cases against AxonFlow.
+type liveTarget struct {
+	client *AxonFlowClient
+}
+
+// PolicyTarget returns a target that checks test cases against AxonFlow: pre-check
+// cases with GetPolicyApprovedContextWithContext and the others with ExecuteQuery,
+// bypassing the cache. Results produced by the FailurePolicy are errors, since AxonFlow
+// did not evaluate them.
+//
+// For pre-checks the reported policies are PolicyApprovalResult.Policies. Query
+// responses only say whether the query was blocked, so queries are observed as allow
+// or block, without policies.
+func (c *AxonFlowClient) PolicyTarget() PolicyTarget {
+	return &liveTarget{client: c}
+}
+
+func (t *liveTarget) Name() string {
+	return redactURL(t.client.config.Endpoint)
+}
+
+func (t *liveTarget) Check(ctx context.Context, tc PolicyTestCase) (*PolicyObservation, error) {
+	if tc.RequestType == "" || tc.RequestType == RequestTypePreCheck {
+		result, err := t.client.GetPolicyApprovedContextWithContext(ctx, tc.UserToken, tc.Prompt, tc.DataSources, tc.Context)
+		if err != nil {
+			return nil, err
+		}
+		if result.Fallback != "" {
+			return nil, fmt.Errorf("AxonFlow unavailable (%s result)", result.Fallback)
+		}
+		observed := &PolicyObservation{Outcome: OutcomeAllow, Policies: result.Policies, Reason: result.BlockReason}
+		if !result.Approved {
+			observed.Outcome = OutcomeBlock
+		} else if result.RequiresRedaction {
+			observed.Outcome = OutcomeRedact
+		}
+		return observed, nil
+	}
+
+	resp, err := t.client.ExecuteQueryContext(ContextWithCacheBypass(ctx), tc.UserToken, tc.Prompt, tc.RequestType, tc.Context)
+	var blocked *PolicyBlockedError
+	if errors.As(err, &blocked) {
+		return &PolicyObservation{Outcome: OutcomeBlock, Reason: blocked.BlockReason}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Fallback != "" {
+		return nil, fmt.Errorf("AxonFlow unavailable (%s result)", resp.Fallback)
+	}
+	observed := &PolicyObservation{Outcome: OutcomeAllow, Reason: resp.BlockReason}
+	if resp.Blocked {
+		observed.Outcome = OutcomeBlock
+	}
+	return observed, nil
+}
+
+// localTarget checks test cases with a LocalEvaluator.
+type localTarget struct {
+	evaluator *LocalEvaluator
+}
+
+// PolicyTarget returns a target that evaluates test cases against the evaluator's
+// static policies, without network calls. Request types, contexts and dynamic
+// policies do not apply. require_approval counts as block; log and warn as allow.
+func (e *LocalEvaluator) PolicyTarget() PolicyTarget {
+	return &localTarget{evaluator: e}
+}
+
+func (t *localTarget) Name() string {
+	return "local"
+}
+
+func (t *localTarget) Check(ctx context.Context, tc PolicyTestCase) (*PolicyObservation, error) {
+	result := t.evaluator.Evaluate(tc.Prompt)
+	observed := &PolicyObservation{Outcome: OutcomeAllow}
+	switch {
+	case result.Blocked:
+		observed.Outcome = OutcomeBlock
+		observed.Reason = result.blockReason()
+	case result.Action == ActionRedact:
+		observed.Outcome = OutcomeRedact
+	}
+	for _, m := range result.Matches {
+		observed.Policies = append(observed.Policies, m.PolicyID)
+	}
+	return observed, nil
+}
+
+// PolicySuiteOptions configures RunPolicySuite.
+type PolicySuiteOptions struct {
+	// Concurrency is the number of cases checked at once (default: 4)
+	Concurrency int
+	// Filter runs only the cases whose name contains it
+	Filter string
+}
+
+// PolicyTestResult is the result of one test case.
+type PolicyTestResult struct {
+	Case     PolicyTestCase     `json:"case"`
+	Observed *PolicyObservation `json:"observed,omitempty"`
+	Passed   bool               `json:"passed"`
+	// Failure explains why the case failed; empty if it passed or could not be checked
+	Failure  string        `json:"failure,omitempty"`
+	Err      error         `json:"-"`
+	Duration time.Duration `json:"duration"`
+}
+
+// PolicySuiteReport is the result of a suite run.
+type PolicySuiteReport struct {
+	Suite     string             `json:"suite"`
+	Target    string             `json:"target"`
+	StartedAt time.Time          `json:"started_at"`
+	Duration  time.Duration      `json:"duration"`
+	Results   []PolicyTestResult `json:"results"`
+	Passed    int                `json:"passed"`
+	Failed    int                `json:"failed"`
+	Errors    int                `json:"errors"` // Cases that could not be checked
+}
+
+// OK reports whether every case passed.
+func (r *PolicySuiteReport) OK() bool {
+	return r.Failed == 0 && r.Errors == 0
+}
+
+// RunPolicySuite checks every case of suite against target and compares the outcome
+// and matched policies with the expected ones. Cases run concurrently; the report lists
+// them in suite order. The error is only set if the suite is invalid or ctx is done:
+// failing cases are reported, not returned.
+//
+//	suite, _ := axonflow.LoadPolicySuite("policy-tests.json")
+//	report, err := axonflow.RunPolicySuite(ctx, suite, client.PolicyTarget(), nil)
+//	report.WriteJUnit(junitFile)
+//	if !report.OK() {
+//	    os.Exit(1)
+//	}
+func RunPolicySuite(ctx context.Context, suite *PolicySuite, target PolicyTarget, options *PolicySuiteOptions) (*PolicySuiteReport, error) {
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &PolicySuiteOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	var cases []PolicyTestCase
+	for _, tc := range suite.Cases {
+		if options.Filter != "" && !strings.Contains(tc.Name, options.Filter) {
+			continue
+		}
+		if tc.UserToken == "" {
+			tc.UserToken = suite.UserToken
+		}
+		if tc.UserToken == "" {
+			tc.UserToken = defaultSuiteUser
+		}
+		cases = append(cases, tc)
+	}
+
+	report := &PolicySuiteReport{Suite: suite.Name, Target: target.Name(), StartedAt: time.Now(), Results: make([]PolicyTestResult, len(cases))}
+	forEachLimit(len(cases), concurrency, func(i int) {
+		report.Results[i] = runPolicyTest(ctx, target, cases[i])
+	})
+	report.Duration = time.Since(report.StartedAt)
+
+	for _, result := range report.Results {
+		switch {
+		case result.Err != nil:
+			report.Errors++
+		case result.Passed:
+			report.Passed++
+		default:
+			report.Failed++
+		}
+	}
+	return report, ctx.Err()
+}
+
+// runPolicyTest checks one case.
+func runPolicyTest(ctx context.Context, target PolicyTarget, tc PolicyTestCase) PolicyTestResult {
+	start := time.Now()
+	observed, err := target.Check(ctx, tc)
+	result := PolicyTestResult{Case: tc, Observed: observed, Err: err, Duration: time.Since(start)}
+	if err != nil {
+		return result
+	}
+
+	var failures []string
+	if observed.Outcome != tc.Expect {
+		failures = append(failures, fmt.Sprintf("expected %s, got %s", tc.Expect, observed.Outcome))
+	}
+	matched := map[string]bool{}
+	for _, id := range observed.Policies {
+		matched[id] = true
+	}
+	var missing []string
+	for _, id := range tc.Policies {
+		if !matched[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		failures = append(failures, fmt.Sprintf("expected policies %s to match, matched [%s]",
+			strings.Join(missing, ", "), strings.Join(observed.Policies, ", ")))
+	}
+	result.Failure = strings.Join(failures, "; ")
+	result.Passed = len(failures) == 0
+	return result
+}
+
+// WriteJSON writes the report as indented JSON. Errors of cases that could not be
+// checked are included as "error".
+func (r *PolicySuiteReport) WriteJSON(w io.Writer) error {
+	type jsonResult struct {
+		PolicyTestResult
+		Error string `json:"error,omitempty"`
+	}
+	results := make([]jsonResult, len(r.Results))
+	for i, result := range r.Results {
+		results[i] = jsonResult{PolicyTestResult: result}
+		if result.Err != nil {
+			results[i].Error = result.Err.Error()
+		}
+	}
+
+	out := struct {
+		*PolicySuiteReport
+		Results []jsonResult `json:"results"`
+	}{r, results}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// junitTestSuites is the JUnit XML document written by WriteJUnit.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, the test report format CI systems
+// display. Each case is a testcase of a testsuite named after the suite; failed cases
+// have a failure and cases that could not be checked an error.
+func (r *PolicySuiteReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      r.Suite,
+		Tests:     len(r.Results),
+		Failures:  r.Failed,
+		Errors:    r.Errors,
+		Time:      junitSeconds(r.Duration),
+		Timestamp: r.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+	}
+	if r.Target != "" {
+		suite.Properties = []junitProperty{{Name: "target", Value: r.Target}}
+	}
+	for _, result := range r.Results {
+		tc := junitTestCase{Name: result.Case.Name, ClassName: r.Suite, Time: junitSeconds(result.Duration)}
+		switch {
+		case result.Err != nil:
+			tc.Error = &junitProblem{Message: result.Err.Error(), Type: "error", Details: result.Err.Error()}
+		case !result.Passed:
+			tc.Failure = &junitProblem{Message: result.Failure, Type: "policy", Details: junitDetails(result)}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitDetails describes a failed case for the failure body.
+func junitDetails(result PolicyTestResult) string {
+	policies := append([]string(nil), result.Observed.Policies...)
+	sort.Strings(policies)
+	details := fmt.Sprintf("prompt: %s\nexpected: %s\nobserved: %s\nmatched policies: [%s]",
+		result.Case.Prompt, result.Case.Expect, result.Observed.Outcome, strings.Join(policies, ", "))
+	if result.Observed.Reason != "" {
+		details += "\nreason: " + result.Observed.Reason
+	}
+	return details
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
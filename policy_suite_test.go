@@ -0,0 +1,204 @@
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testSuite = `{
+  "name": "governance",
+  "cases": [
+    {"name": "ssn is redacted", "prompt": "My SSN is 123-45-6789", "expect": "redact", "policies": ["pii-ssn"]},
+    {"name": "drop table is blocked", "prompt": "x'; DROP TABLE users", "request_type": "sql", "expect": "block"},
+    {"name": "weather is allowed", "prompt": "What's the weather in Paris?", "expect": "allow"},
+    {"name": "wrong expectation", "prompt": "My SSN is 123-45-6789", "expect": "allow", "policies": ["pii-email"]}
+  ]
+}`
+
+func TestParsePolicySuite(t *testing.T) {
+	suite, err := ParsePolicySuite([]byte(testSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != "governance" || len(suite.Cases) != 4 || suite.Cases[1].RequestType != "sql" {
+		t.Errorf("unexpected suite: %+v", suite)
+	}
+
+	_, err = ParsePolicySuite([]byte(`{"cases": [{"name": "a", "expect": "deny"}, {"name": "a", "prompt": "x", "expect": "allow"}]}`))
+	for _, want := range []string{`case "a": prompt is required`, "expect must be allow, block or redact", `case "a" is declared twice`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+
+	// Query responses report neither redaction nor matched policies
+	_, err = ParsePolicySuite([]byte(`{"cases": [{"name": "q", "prompt": "x", "request_type": "chat", "expect": "redact", "policies": ["p"]}]}`))
+	for _, want := range []string{`case "q": only pre-check cases can expect redact`, `case "q": only pre-check cases can expect matched policies`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "suite.json")
+	os.WriteFile(path, []byte(testSuite), 0o600)
+	if _, err := LoadPolicySuite(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunPolicySuiteLocal(t *testing.T) {
+	var policies atomic.Value
+	policies.Store(testPolicies)
+	server := newPolicyServer(t, &policies, new(int32))
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+	e, _ := client.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{RefreshInterval: -1})
+
+	suite, _ := ParsePolicySuite([]byte(testSuite))
+	report, err := RunPolicySuite(context.Background(), suite, e.PolicyTarget(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed != 3 || report.Failed != 1 || report.Errors != 0 || report.OK() || report.Target != "local" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	failure := report.Results[3].Failure
+	if failure != "expected allow, got redact; expected policies pii-email to match, matched [pii-ssn]" {
+		t.Errorf("unexpected failure: %q", failure)
+	}
+
+	report, _ = RunPolicySuite(context.Background(), suite, e.PolicyTarget(), &PolicySuiteOptions{Filter: "allowed"})
+	if len(report.Results) != 1 || !report.OK() {
+		t.Errorf("expected one passing case, got %+v", report.Results)
+	}
+}
+
+func TestRunPolicySuiteLive(t *testing.T) {
+	var queries int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/api/policy/pre-check":
+			if body["user_token"] != "ci" {
+				t.Errorf("expected the suite's user token, got %v", body["user_token"])
+			}
+			query := body["query"].(string)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"context_id":         "ctx",
+				"approved":           true,
+				"requires_redaction": strings.Contains(query, "SSN"),
+				"policies":           []string{"pii-ssn"},
+			})
+		case "/api/request":
+			atomic.AddInt32(&queries, 1)
+			if body["request_type"] != "sql" {
+				t.Errorf("expected a sql query, got %v", body["request_type"])
+			}
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false, "blocked": true, "block_reason": "SQL injection detected",
+				"policy_info": map[string]interface{}{"policies_evaluated": []string{"sqli-drop"}},
+			})
+		}
+	}))
+	defer server.Close()
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+
+	suite, _ := ParsePolicySuite([]byte(testSuite))
+	suite.UserToken = "ci"
+	report, err := RunPolicySuite(context.Background(), suite, client.PolicyTarget(), &PolicySuiteOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed != 3 || report.Failed != 1 || queries != 1 {
+		for _, r := range report.Results {
+			t.Logf("%s: %+v %s %v", r.Case.Name, r.Observed, r.Failure, r.Err)
+		}
+		t.Errorf("unexpected report: passed %d, failed %d, queries %d", report.Passed, report.Failed, queries)
+	}
+	if report.Target != server.URL {
+		t.Errorf("expected the endpoint as target, got %s", report.Target)
+	}
+
+	// Fail-open results are errors, not passes
+	down, _ := New(downURL(), WithCredentials("tenant", "secret"), WithoutRetry(),
+		WithFailurePolicy(FailurePolicy{PreCheck: FailOpen}))
+	defer down.Close(context.Background())
+	report, _ = RunPolicySuite(context.Background(), suite, down.PolicyTarget(), &PolicySuiteOptions{Filter: "weather"})
+	if report.Errors != 1 || report.OK() {
+		t.Errorf("expected an error for a fail-open result, got %+v", report.Results)
+	}
+
+	// Cases cut short by ctx are counted as errors
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = RunPolicySuite(ctx, suite, client.PolicyTarget(), nil)
+	if err != context.Canceled || report.Errors != len(suite.Cases) || report.OK() {
+		t.Errorf("expected every case to be an error, got %+v, %v", report, err)
+	}
+}
+
+func TestPolicySuiteReports(t *testing.T) {
+	report := &PolicySuiteReport{
+		Suite:  "governance",
+		Target: "local",
+		Results: []PolicyTestResult{
+			{Case: PolicyTestCase{Name: "ok", Expect: OutcomeAllow}, Observed: &PolicyObservation{Outcome: OutcomeAllow}, Passed: true},
+			{Case: PolicyTestCase{Name: "bad", Prompt: "p <&>", Expect: OutcomeBlock}, Observed: &PolicyObservation{Outcome: OutcomeAllow},
+				Failure: "expected block, got allow"},
+			{Case: PolicyTestCase{Name: "down"}, Err: context.DeadlineExceeded},
+		},
+		Passed: 1, Failed: 1, Errors: 1,
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	var parsed junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, junit.String())
+	}
+	suite := parsed.Suites[0]
+	if suite.Name != "governance" || suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 {
+		t.Errorf("unexpected suite: %+v", suite)
+	}
+	if len(suite.Properties) != 1 || suite.Properties[0] != (junitProperty{Name: "target", Value: "local"}) ||
+		strings.Contains(junit.String(), "hostname") {
+		t.Errorf("expected the target as a property, got:\n%s", junit.String())
+	}
+	if suite.Cases[0].Failure != nil || suite.Cases[1].Failure.Message != "expected block, got allow" ||
+		!strings.Contains(suite.Cases[1].Failure.Details, "prompt: p <&>") || suite.Cases[2].Error == nil {
+		t.Errorf("unexpected cases: %+v", suite.Cases)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Suite   string `json:"suite"`
+		Errors  int    `json:"errors"`
+		Results []struct {
+			Passed bool   `json:"passed"`
+			Error  string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Suite != "governance" || decoded.Errors != 1 || !decoded.Results[0].Passed || decoded.Results[2].Error != "context deadline exceeded" {
+		t.Errorf("unexpected JSON report: %s", out.String())
+	}
+}
@@ -0,0 +1,233 @@
+// Client-side rate limiting based on the quota reported by AxonFlow
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxQuotaBuckets bounds the number of tenant/user quotas tracked by a client.
+const maxQuotaBuckets = 10000
+
+// RateLimitConfig configures client-side throttling. The client always tracks the
+// quota AxonFlow reports in X-RateLimit-* headers and rate_limit response fields (see
+// AxonFlowClient.Quota); with Enabled set it also stops sending calls once the quota
+// is used up, until the window resets, instead of collecting 429 responses.
+//
+// Quotas are tracked per tenant and, for calls made on behalf of a user (queries,
+// plans and pre-checks), per user token.
+type RateLimitConfig struct {
+	Enabled bool // Reject calls while their quota is exhausted (default: false)
+	// Queue delays calls while their quota is exhausted instead of rejecting them
+	Queue bool
+	// MaxWait is the longest a queued call waits for its quota to reset (default: 30s).
+	// Calls that would have to wait longer are rejected.
+	MaxWait time.Duration
+}
+
+// quotaKey identifies a quota by tenant and user token.
+type quotaKey struct {
+	tenant, user string
+}
+
+// quotaBucket holds the tokens left in the current window: the quota last reported
+// by AxonFlow, less the calls sent since. It is refilled to limit at resetAt.
+type quotaBucket struct {
+	limit     int
+	remaining int
+	resetAt   time.Time // zero if unknown
+}
+
+// refill starts a new window once resetAt has passed.
+func (b *quotaBucket) refill(now time.Time) {
+	if !b.resetAt.IsZero() && !now.Before(b.resetAt) {
+		b.remaining = b.limit
+		b.resetAt = time.Time{}
+	}
+}
+
+// rateLimiter tracks reported quotas and throttles calls when enabled.
+type rateLimiter struct {
+	config  RateLimitConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[quotaKey]*quotaBucket
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if config.MaxWait == 0 {
+		config.MaxWait = 30 * time.Second
+	}
+	return &rateLimiter{config: config, now: time.Now, buckets: make(map[quotaKey]*quotaBucket)}
+}
+
+// acquire takes a token for key. While the quota is exhausted it waits for the
+// window to reset (with Queue, up to MaxWait) or returns a *RateLimitedError.
+// Unknown quotas never block.
+func (l *rateLimiter) acquire(ctx context.Context, key quotaKey) error {
+	if !l.config.Enabled {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		b := l.buckets[key]
+		if b == nil {
+			l.mu.Unlock()
+			return nil
+		}
+		now := l.now()
+		b.refill(now)
+		if b.remaining > 0 || b.resetAt.IsZero() {
+			if b.remaining > 0 {
+				b.remaining--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		limit, resetAt := b.limit, b.resetAt
+		l.mu.Unlock()
+
+		wait := resetAt.Sub(now)
+		if !l.config.Queue || wait > l.config.MaxWait {
+			return &RateLimitedError{
+				APIError: &APIError{
+					StatusCode: http.StatusTooManyRequests,
+					Message:    fmt.Sprintf("rate limit quota exhausted until %s (not sent)", resetAt.Format(time.RFC3339)),
+				},
+				RetryAfter: wait,
+				Limit:      limit,
+				ResetAt:    resetAt,
+				Local:      true,
+			}
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// observe updates the quota of key from a response, if it reports one.
+func (l *rateLimiter) observe(key quotaKey, resp *http.Response) {
+	info, ok := parseRateLimit(resp, l.now())
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxQuotaBuckets {
+			l.prune()
+		}
+		b = &quotaBucket{}
+		l.buckets[key] = b
+	}
+	b.limit = info.Limit
+	b.remaining = info.Remaining
+	b.resetAt = info.ResetAt
+}
+
+// prune drops quotas whose window has reset, or all of them if none has. Called
+// with l.mu held.
+func (l *rateLimiter) prune() {
+	now := l.now()
+	for key, b := range l.buckets {
+		if !b.resetAt.IsZero() && !now.Before(b.resetAt) {
+			delete(l.buckets, key)
+		}
+	}
+	if len(l.buckets) >= maxQuotaBuckets {
+		l.buckets = make(map[quotaKey]*quotaBucket)
+	}
+}
+
+// quota returns the current quota of key.
+func (l *rateLimiter) quota(key quotaKey) (RateLimitInfo, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	if b == nil {
+		return RateLimitInfo{}, false
+	}
+	b.refill(l.now())
+	return RateLimitInfo{Limit: b.limit, Remaining: b.remaining, ResetAt: b.resetAt}, true
+}
+
+// parseRateLimit reads the quota reported by a response: X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (Unix seconds) headers, or else a
+// rate_limit or rate_limit_info object in a JSON body. A 429 response without a
+// reset time is exhausted for its Retry-After delay.
+func parseRateLimit(resp *http.Response, now time.Time) (RateLimitInfo, bool) {
+	var info RateLimitInfo
+	found := false
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err == nil {
+		found = true
+		info.Remaining = remaining
+		info.Limit, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			info.ResetAt = time.Unix(reset, 0)
+		}
+	} else if body := peekBody(resp); bytes.Contains(body, []byte(`"rate_limit`)) {
+		found = parseRateLimitBody(body, &info)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		found = true
+		info.Remaining = 0
+		if info.ResetAt.IsZero() {
+			if d := parseRetryAfter(resp.Header.Get("Retry-After"), now); d > 0 {
+				info.ResetAt = now.Add(d)
+			}
+		}
+	}
+	return info, found
+}
+
+// parseRateLimitBody decodes a rate_limit or rate_limit_info object into info.
+func parseRateLimitBody(body []byte, info *RateLimitInfo) bool {
+	var fields struct {
+		RateLimit     *rawRateLimit `json:"rate_limit"`
+		RateLimitInfo *rawRateLimit `json:"rate_limit_info"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	raw := fields.RateLimit
+	if raw == nil {
+		raw = fields.RateLimitInfo
+	}
+	if raw == nil {
+		return false
+	}
+	info.Limit = raw.Limit
+	info.Remaining = raw.Remaining
+	if resetAt, err := parseTimeWithFallback(raw.ResetAt); err == nil {
+		info.ResetAt = resetAt
+	}
+	return true
+}
+
+// rawRateLimit is the rate limit object of a response body.
+type rawRateLimit struct {
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	ResetAt   string `json:"reset_at"`
+}
+
+// Quota returns the rate limit quota of the client's tenant for calls made on behalf
+// of user (the user token passed to ExecuteQuery, GeneratePlan, pre-checks, ...), or
+// for other calls if user is empty. The quota is the one AxonFlow last reported, less
+// the calls sent since when RateLimit.Enabled is set, and is refilled once ResetAt has
+// passed. ok is false if AxonFlow has not reported a quota yet.
+func (c *AxonFlowClient) Quota(user string) (info RateLimitInfo, ok bool) {
+	return c.limiter.quota(quotaKey{tenant: c.config.ClientID, user: user})
+}
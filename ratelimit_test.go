@@ -0,0 +1,202 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// quotaServer answers queries with a quota of limit requests per window, reported
+// in the rate_limit field of the body, and 429 once it is used up. The next failNext
+// requests within the quota are answered with 503.
+type quotaServer struct {
+	*httptest.Server
+	limit    int
+	window   time.Duration
+	requests int32
+	failNext int32
+	resetAt  time.Time
+	used     int
+}
+
+func newQuotaServer(t *testing.T, limit int, window time.Duration) *quotaServer {
+	t.Helper()
+	q := &quotaServer{limit: limit, window: window, resetAt: time.Now().Add(window)}
+	q.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&q.requests, 1)
+		if now := time.Now(); !now.Before(q.resetAt) {
+			q.used, q.resetAt = 0, now.Add(q.window)
+		}
+		q.used++
+		remaining := q.limit - q.used
+		if remaining < 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(q.resetAt).Seconds())+1))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "rate limit exceeded"}`))
+			return
+		}
+		if atomic.AddInt32(&q.failNext, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"rate_limit": map[string]interface{}{
+				"limit":     q.limit,
+				"remaining": remaining,
+				"reset_at":  q.resetAt.Format(time.RFC3339Nano),
+			},
+		})
+	}))
+	t.Cleanup(q.Close)
+	return q
+}
+
+func TestQuotaTracking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"), WithoutCache())
+
+	if _, ok := client.Quota("alice"); ok {
+		t.Error("expected no quota before the first call")
+	}
+	client.ExecuteQuery("alice", "q", "chat", nil)
+
+	quota, ok := client.Quota("alice")
+	if !ok || quota.Limit != 100 || quota.Remaining != 42 || quota.ResetAt.IsZero() {
+		t.Errorf("unexpected quota: %+v, %v", quota, ok)
+	}
+	if _, ok := client.Quota("bob"); ok {
+		t.Error("expected quotas to be tracked per user")
+	}
+}
+
+func TestRateLimitRejects(t *testing.T) {
+	server := newQuotaServer(t, 2, time.Hour)
+	client, _ := New(server.URL, WithoutCache(), WithoutRetry(), WithRateLimit(RateLimitConfig{Enabled: true}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ExecuteQuery("alice", "q", "chat", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := client.ExecuteQuery("alice", "q", "chat", nil)
+	var rl *RateLimitedError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rl) || !rl.Local || rl.Limit != 2 || rl.RetryAfter <= 0 {
+		t.Fatalf("expected a local RateLimitedError, got %v", err)
+	}
+	if server.requests != 2 {
+		t.Errorf("expected the throttled call not to be sent, got %d requests", server.requests)
+	}
+
+	// Other users have their own quota, so the client sends bob's call
+	client.ExecuteQuery("bob", "q", "chat", nil)
+	if server.requests != 3 {
+		t.Errorf("expected bob's call to be sent, got %d requests", server.requests)
+	}
+}
+
+func TestRateLimitThrottlesRetries(t *testing.T) {
+	server := newQuotaServer(t, 1, time.Hour)
+	server.failNext = 1
+	client, _ := New(server.URL, WithoutCache(), WithRateLimit(RateLimitConfig{Enabled: true}),
+		WithRetry(RetryConfig{Enabled: true, MaxAttempts: 3, InitialDelay: time.Millisecond}))
+
+	// The 503 used up the quota, so the retry is throttled instead of sent
+	_, err := client.ExecuteQuery("alice", "q", "chat", nil)
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) || !rl.Local {
+		t.Fatalf("expected a local RateLimitedError, got %v", err)
+	}
+	if server.requests != 1 {
+		t.Errorf("expected the retry not to be sent, got %d requests", server.requests)
+	}
+}
+
+func TestRateLimitQueues(t *testing.T) {
+	server := newQuotaServer(t, 1, 100*time.Millisecond)
+	client, _ := New(server.URL, WithoutCache(), WithoutRetry(),
+		WithRateLimit(RateLimitConfig{Enabled: true, Queue: true}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.ExecuteQuery("alice", "q", "chat", nil); err != nil {
+			t.Fatalf("call %d: expected the call to wait for the quota, got %v", i+1, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected calls to wait for two window resets, took %v", elapsed)
+	}
+	if server.requests != 3 {
+		t.Errorf("expected no 429s, got %d requests", server.requests)
+	}
+
+	// A wait beyond MaxWait is rejected right away
+	client, _ = New(newQuotaServer(t, 1, time.Hour).URL, WithoutCache(), WithoutRetry(),
+		WithRateLimit(RateLimitConfig{Enabled: true, Queue: true, MaxWait: time.Second}))
+	client.ExecuteQuery("alice", "q", "chat", nil)
+	if _, err := client.ExecuteQuery("alice", "q", "chat", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	client.limiter.config.MaxWait = 2 * time.Hour
+	if _, err := client.ExecuteQueryContext(ctx, "alice", "q", "chat", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a queued call to end with its context, got %v", err)
+	}
+}
+
+func TestRateLimit429(t *testing.T) {
+	server := newQuotaServer(t, 0, time.Hour)
+	client, _ := New(server.URL, WithoutCache(), WithoutRetry(), WithRateLimit(RateLimitConfig{Enabled: true}))
+
+	// The 429 is remembered until its Retry-After has passed
+	client.ExecuteQuery("alice", "q", "chat", nil)
+	_, err := client.ExecuteQuery("alice", "q", "chat", nil)
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) || !rl.Local {
+		t.Errorf("expected a local RateLimitedError, got %v", err)
+	}
+	if server.requests != 1 {
+		t.Errorf("expected one request, got %d", server.requests)
+	}
+}
+
+func TestQuotaRefill(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{Enabled: true})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	key := quotaKey{tenant: "t"}
+
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Limit", "10")
+	resp.Header.Set("X-RateLimit-Remaining", "1")
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+	l.observe(key, resp)
+
+	if err := l.acquire(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.acquire(context.Background(), key); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected the quota to be exhausted, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if quota, _ := l.quota(key); quota.Remaining != 10 {
+		t.Errorf("expected the quota to be refilled, got %+v", quota)
+	}
+	if err := l.acquire(context.Background(), key); err != nil {
+		t.Errorf("expected a token after the reset, got %v", err)
+	}
+}
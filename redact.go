@@ -0,0 +1,402 @@
+// Client-side redaction driven by redact-action static policies
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaskMode selects how a Redactor replaces matched values.
+type MaskMode string
+
+const (
+	// MaskFull replaces every character with RedactorConfig.MaskChar: "***-**-****"
+	MaskFull MaskMode = "full"
+	// MaskPartial masks letters and digits except the last RedactorConfig.KeepLast,
+	// keeping separators: "***-**-6789"
+	MaskPartial MaskMode = "partial"
+	// MaskHash replaces the value with a keyed hash: "[hash:3f1c0a9b2d4e5f60]"
+	MaskHash MaskMode = "hash"
+	// MaskToken replaces letters and digits with pseudo-random ones of the same kind,
+	// keeping length and separators: "123-45-6789" becomes e.g. "804-17-3352". The
+	// same value always gets the same token.
+	MaskToken MaskMode = "token"
+)
+
+// piiCategories are the categories a Redactor applies by default.
+var piiCategories = []PolicyCategory{CategoryPIIGlobal, CategoryPIIUS, CategoryPIIEU, CategoryPIIIndia}
+
+// RedactorConfig configures a Redactor.
+type RedactorConfig struct {
+	// Mode selects the masking (default: MaskFull)
+	Mode MaskMode
+	// Categories are the policy categories to apply (default: pii-global, pii-us,
+	// pii-eu and pii-india)
+	Categories []PolicyCategory
+	// MaskChar is the mask character of MaskFull and MaskPartial (default: '*')
+	MaskChar rune
+	// KeepLast is the number of letters and digits MaskPartial leaves visible
+	// (default: 4). Values with no more than KeepLast are masked completely.
+	KeepLast int
+	// Key is the HMAC key of MaskHash and MaskToken. Without a secret key, hashes and
+	// tokens of guessable values such as phone numbers can be reversed by trying them.
+	Key []byte
+}
+
+// Redaction counts the values one policy redacted at one location.
+type Redaction struct {
+	PolicyID   string         `json:"policy_id"`
+	PolicyName string         `json:"policy_name"`
+	Category   PolicyCategory `json:"category"`
+	// Path locates the value: "$" for a string, "$.user.email" or "$.items[0]" inside
+	// JSON values
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// RedactionReport describes what a Redactor redacted. It never contains the
+// redacted values.
+type RedactionReport struct {
+	Mode       MaskMode    `json:"mode"`
+	Count      int         `json:"count"` // Total number of values redacted
+	Redactions []Redaction `json:"redactions,omitempty"`
+}
+
+// Fields returns the paths of the redacted values, in order of first redaction.
+func (r *RedactionReport) Fields() []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, red := range r.Redactions {
+		if !seen[red.Path] {
+			seen[red.Path] = true
+			fields = append(fields, red.Path)
+		}
+	}
+	return fields
+}
+
+// PolicyIDs returns the IDs of the policies that redacted values, in order of first
+// redaction.
+func (r *RedactionReport) PolicyIDs() []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, red := range r.Redactions {
+		if !seen[red.PolicyID] {
+			seen[red.PolicyID] = true
+			ids = append(ids, red.PolicyID)
+		}
+	}
+	return ids
+}
+
+// Metadata returns the report as AuditLLMCall metadata:
+//
+//	result, report := redactor.RedactString(llmResponse)
+//	client.AuditLLMCall(contextID, summary, "openai", "gpt-4", usage, latency, report.Metadata())
+func (r *RedactionReport) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"redactions_applied": r.Count,
+		"redaction_mode":     string(r.Mode),
+		"redacted_policies":  r.PolicyIDs(),
+		"redacted_fields":    r.Fields(),
+	}
+}
+
+// add records a redaction by policy p at path.
+func (r *RedactionReport) add(p *compiledPolicy, path string) {
+	r.Count++
+	for i := range r.Redactions {
+		if red := &r.Redactions[i]; red.PolicyID == p.policy.ID && red.Path == path {
+			red.Count++
+			return
+		}
+	}
+	r.Redactions = append(r.Redactions, Redaction{
+		PolicyID:   p.policy.ID,
+		PolicyName: p.policy.Name,
+		Category:   p.policy.Category,
+		Path:       path,
+		Count:      1,
+	})
+}
+
+// Redactor masks the values matched by redact-action static policies, e.g. PII in
+// LLM responses in Gateway Mode, where AxonFlow does not see the response. A policy
+// applies when its effective action (taking active overrides into account) is redact
+// and its category is one of RedactorConfig.Categories.
+//
+// Like LocalEvaluator, a Redactor compiles patterns with Go's regexp package and
+// ignores policies whose pattern RE2 cannot compile. A Redactor is safe for
+// concurrent use.
+type Redactor struct {
+	config     RedactorConfig
+	categories map[PolicyCategory]bool
+	policies   func() []*compiledPolicy
+	now        func() time.Time
+}
+
+// NewRedactor returns a Redactor for policies, e.g. the result of
+// GetEffectiveStaticPolicies.
+func NewRedactor(policies []StaticPolicy, config RedactorConfig) (*Redactor, error) {
+	compiled, _ := compilePolicies(policies, nil)
+	return newRedactor(func() []*compiledPolicy { return compiled }, config)
+}
+
+// NewRedactor loads the effective static policies and returns a Redactor for them.
+func (c *AxonFlowClient) NewRedactor(ctx context.Context, config RedactorConfig) (*Redactor, error) {
+	policies, err := c.GetEffectiveStaticPoliciesContext(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load static policies: %w", err)
+	}
+	return NewRedactor(policies, config)
+}
+
+// Redactor returns a Redactor for the evaluator's policies. It shares their compiled
+// patterns and follows the evaluator's refreshes.
+func (e *LocalEvaluator) Redactor(config RedactorConfig) (*Redactor, error) {
+	return newRedactor(e.compiled, config)
+}
+
+func newRedactor(policies func() []*compiledPolicy, config RedactorConfig) (*Redactor, error) {
+	switch config.Mode {
+	case "":
+		config.Mode = MaskFull
+	case MaskFull, MaskPartial, MaskHash, MaskToken:
+	default:
+		return nil, fmt.Errorf("unknown mask mode %q", config.Mode)
+	}
+	if config.MaskChar == 0 {
+		config.MaskChar = '*'
+	}
+	if config.KeepLast == 0 {
+		config.KeepLast = 4
+	}
+	if len(config.Categories) == 0 {
+		config.Categories = piiCategories
+	}
+
+	categories := make(map[PolicyCategory]bool, len(config.Categories))
+	for _, category := range config.Categories {
+		categories[category] = true
+	}
+	return &Redactor{config: config, categories: categories, policies: policies, now: time.Now}, nil
+}
+
+// active returns the policies that apply now.
+func (r *Redactor) active() []*compiledPolicy {
+	now := r.now()
+	var active []*compiledPolicy
+	for _, p := range r.policies() {
+		if r.categories[p.policy.Category] && p.action(now) == ActionRedact {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// RedactString masks the values in s matched by the policies.
+func (r *Redactor) RedactString(s string) (string, *RedactionReport) {
+	report := &RedactionReport{Mode: r.config.Mode}
+	return r.redact(s, "$", r.active(), report), report
+}
+
+// RedactValue masks matched values in the strings of v, which may be a string or a
+// JSON value decoded into interface{}: maps, slices and scalars, nested to any depth.
+// Map keys are not redacted. v is not modified; the result is a copy.
+func (r *Redactor) RedactValue(v interface{}) (interface{}, *RedactionReport) {
+	report := &RedactionReport{Mode: r.config.Mode}
+	return r.redactValue(v, "$", r.active(), report), report
+}
+
+// RedactJSON masks matched values in the strings of a JSON document. data must hold
+// exactly one JSON value.
+func (r *Redactor) RedactJSON(data []byte) ([]byte, *RedactionReport, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	var trailing interface{}
+	if err := decoder.Decode(&trailing); err != io.EOF {
+		return nil, nil, fmt.Errorf("failed to parse JSON: unexpected content after the document")
+	}
+	redacted, report := r.RedactValue(v)
+	out, err := json.Marshal(redacted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return out, report, nil
+}
+
+func (r *Redactor) redactValue(v interface{}, path string, policies []*compiledPolicy, report *RedactionReport) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.redact(v, path, policies, report)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for _, k := range sortedMapKeys(v) {
+			out[k] = r.redactValue(v[k], path+"."+k, policies, report)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.redactValue(item, path+"["+strconv.Itoa(i)+"]", policies, report)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for _, k := range sortedMapKeys(v) {
+			out[k] = r.redact(v[k], path+"."+k, policies, report)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = r.redact(item, path+"["+strconv.Itoa(i)+"]", policies, report)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// sortedMapKeys returns the keys of a map[string]interface{} or map[string]string in
+// order, so that reports are deterministic.
+func sortedMapKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redact masks the matches of policies in s. Where matches overlap, the earliest
+// (and then longest) wins.
+func (r *Redactor) redact(s, path string, policies []*compiledPolicy, report *RedactionReport) string {
+	type span struct {
+		start, end int
+		policy     *compiledPolicy
+	}
+	var spans []span
+	for _, p := range policies {
+		for _, loc := range p.re.FindAllStringIndex(s, -1) {
+			if loc[0] < loc[1] {
+				spans = append(spans, span{loc[0], loc[1], p})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		if sp.start < last {
+			continue
+		}
+		b.WriteString(s[last:sp.start])
+		b.WriteString(r.mask(s[sp.start:sp.end]))
+		report.add(sp.policy, path)
+		last = sp.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// mask returns the replacement of value.
+func (r *Redactor) mask(value string) string {
+	switch r.config.Mode {
+	case MaskPartial:
+		hidden := -r.config.KeepLast
+		for _, c := range value {
+			if isAlnum(c) {
+				hidden++
+			}
+		}
+		if hidden <= 0 {
+			// Too short to show anything
+			return strings.Repeat(string(r.config.MaskChar), utf8.RuneCountInString(value))
+		}
+		var b strings.Builder
+		for _, c := range value {
+			if isAlnum(c) && hidden > 0 {
+				b.WriteRune(r.config.MaskChar)
+				hidden--
+			} else {
+				b.WriteRune(c)
+			}
+		}
+		return b.String()
+	case MaskHash:
+		return "[hash:" + hex.EncodeToString(r.sum(value, 0)[:8]) + "]"
+	case MaskToken:
+		var b strings.Builder
+		var stream []byte
+		for i, c := range value {
+			if !isAlnum(c) {
+				b.WriteRune(c)
+				continue
+			}
+			if len(stream) == 0 {
+				stream = r.sum(value, uint64(i))
+			}
+			n := stream[0]
+			stream = stream[1:]
+			switch {
+			case unicode.IsDigit(c):
+				b.WriteByte('0' + n%10)
+			case unicode.IsUpper(c):
+				b.WriteByte('A' + n%26)
+			default:
+				b.WriteByte('a' + n%26)
+			}
+		}
+		return b.String()
+	default:
+		return strings.Repeat(string(r.config.MaskChar), utf8.RuneCountInString(value))
+	}
+}
+
+// sum returns the HMAC-SHA256 of value and a block counter.
+func (r *Redactor) sum(value string, block uint64) []byte {
+	h := hmac.New(sha256.New, r.config.Key)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], block)
+	h.Write(counter[:])
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
+
+func isAlnum(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,175 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// redactPolicies are PII policies for redaction tests.
+var redactPolicies = []StaticPolicy{
+	{ID: "pii-ssn", Name: "US SSN", Category: CategoryPIIUS, Pattern: `\b\d{3}-\d{2}-\d{4}\b`, Action: ActionRedact, Enabled: true},
+	{ID: "pii-email", Name: "Email", Category: CategoryPIIGlobal, Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`, Action: ActionRedact, Enabled: true},
+	{ID: "pii-phone", Name: "Phone", Category: CategoryPIIGlobal, Pattern: `\d{3}-\d{4}`, Action: ActionWarn, Enabled: true},
+	{ID: "sqli-drop", Name: "SQL DROP", Category: CategorySecuritySQLI, Pattern: `(?i)drop table`, Action: ActionRedact, Enabled: true},
+}
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RedactorConfig
+		want   string
+	}{
+		{"full", RedactorConfig{}, "SSN ***********, mail ****************, drop table"},
+		{"partial", RedactorConfig{Mode: MaskPartial}, "SSN ***-**-6789, mail ****@******e.com, drop table"},
+		{"mask char", RedactorConfig{MaskChar: '#', KeepLast: 2, Mode: MaskPartial}, "SSN ###-##-##89, mail ####@#######.#om, drop table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRedactor(redactPolicies, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, report := r.RedactString("SSN 123-45-6789, mail john@example.com, drop table")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if report.Count != 2 || !reflect.DeepEqual(report.PolicyIDs(), []string{"pii-ssn", "pii-email"}) {
+				t.Errorf("unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestRedactHashAndToken(t *testing.T) {
+	hashed, _ := NewRedactor(redactPolicies, RedactorConfig{Mode: MaskHash, Key: []byte("secret")})
+	a, _ := hashed.RedactString("123-45-6789")
+	b, _ := hashed.RedactString("ssn: 123-45-6789")
+	if !strings.HasPrefix(a, "[hash:") || len(a) != len("[hash:]")+16 || b != "ssn: "+a {
+		t.Errorf("expected a stable hash, got %q and %q", a, b)
+	}
+	other, _ := NewRedactor(redactPolicies, RedactorConfig{Mode: MaskHash, Key: []byte("other")})
+	if c, _ := other.RedactString("123-45-6789"); c == a {
+		t.Error("expected the hash to depend on the key")
+	}
+
+	tokens, _ := NewRedactor(redactPolicies, RedactorConfig{Mode: MaskToken, Key: []byte("secret")})
+	token, _ := tokens.RedactString("123-45-6789")
+	again, _ := tokens.RedactString("123-45-6789")
+	if token == "123-45-6789" || token != again || !matchesWhole(redactPolicies[0], token) {
+		t.Errorf("expected a stable SSN-shaped token, got %q and %q", token, again)
+	}
+	email, _ := tokens.RedactString("John.Doe@example.com")
+	if len(email) != len("John.Doe@example.com") || email[4] != '.' || email[8] != '@' || email[0] < 'A' || email[0] > 'Z' {
+		t.Errorf("expected the email format to be kept, got %q", email)
+	}
+}
+
+// matchesWhole reports whether p's pattern matches the whole of s.
+func matchesWhole(p StaticPolicy, s string) bool {
+	compiled, _ := compilePolicies([]StaticPolicy{p}, nil)
+	loc := compiled[0].re.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+func TestRedactJSON(t *testing.T) {
+	r, _ := NewRedactor(redactPolicies, RedactorConfig{})
+	in := []byte(`{"user":{"email":"a@b.co","ssn":"123-45-6789","age":42},"notes":["ok","call 555-1234","x@y.io and z@y.io"],"n":1.50}`)
+	out, report, err := r.RedactJSON(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"n":1.50,"notes":["ok","call 555-1234","****** and ******"],"user":{"age":42,"email":"******","ssn":"***********"}}`
+	if string(out) != want {
+		t.Errorf("got %s\nwant %s", out, want)
+	}
+	if report.Count != 4 || !reflect.DeepEqual(report.Fields(), []string{"$.notes[2]", "$.user.email", "$.user.ssn"}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.Redactions[0].Count != 2 || report.Redactions[0].Category != CategoryPIIGlobal {
+		t.Errorf("expected two email redactions in notes[2], got %+v", report.Redactions[0])
+	}
+
+	metadata := report.Metadata()
+	if metadata["redactions_applied"] != 4 || metadata["redaction_mode"] != "full" ||
+		!reflect.DeepEqual(metadata["redacted_policies"], []string{"pii-email", "pii-ssn"}) {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+	if _, err := json.Marshal(metadata); err != nil {
+		t.Errorf("metadata is not JSON: %v", err)
+	}
+
+	for _, bad := range []string{`{`, `{"a": 1} {"b": 2}`, `"x" trailing`} {
+		if _, _, err := r.RedactJSON([]byte(bad)); err == nil {
+			t.Errorf("expected a parse error for %q", bad)
+		}
+	}
+	if _, _, err := r.RedactJSON([]byte("{}\n")); err != nil {
+		t.Errorf("expected trailing whitespace to be accepted, got %v", err)
+	}
+}
+
+func TestRedactValueCopies(t *testing.T) {
+	r, _ := NewRedactor(redactPolicies, RedactorConfig{Categories: []PolicyCategory{CategoryPIIUS}})
+	in := map[string]interface{}{
+		"ssn":   "123-45-6789",
+		"email": "a@b.co",
+		"tags":  []string{"123-45-6789"},
+	}
+	out, report := r.RedactValue(in)
+	if in["ssn"] != "123-45-6789" {
+		t.Error("expected the input to be left unchanged")
+	}
+	got := out.(map[string]interface{})
+	if got["ssn"] != "***********" || got["email"] != "a@b.co" || got["tags"].([]string)[0] != "***********" {
+		t.Errorf("expected only pii-us values redacted, got %v", got)
+	}
+	if report.Count != 2 {
+		t.Errorf("expected 2 redactions, got %d", report.Count)
+	}
+
+	if _, err := NewRedactor(nil, RedactorConfig{Mode: "scramble"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestLocalEvaluatorRedactor(t *testing.T) {
+	var policies atomic.Value
+	policies.Store(redactPolicies[:1])
+	server := newPolicyServer(t, &policies, new(int32))
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"))
+	defer client.Close(context.Background())
+
+	e, err := client.NewLocalEvaluator(context.Background(), LocalEvaluatorConfig{RefreshInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := e.Redactor(RedactorConfig{})
+	if got, _ := r.RedactString("123-45-6789 a@b.co"); got != "*********** a@b.co" {
+		t.Errorf("unexpected redaction: %q", got)
+	}
+
+	// The redactor follows the evaluator's refreshes, including overrides
+	email := redactPolicies[1]
+	email.Action = ActionWarn
+	email.HasOverride = true
+	email.Override = &PolicyOverride{Action: OverrideActionRedact, Active: true}
+	policies.Store([]StaticPolicy{redactPolicies[0], email})
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, report := r.RedactString("123-45-6789 a@b.co"); got != "*********** ******" || report.Count != 2 {
+		t.Errorf("unexpected redaction after refresh: %q", got)
+	}
+
+	loaded, err := client.NewRedactor(context.Background(), RedactorConfig{Mode: MaskPartial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := loaded.RedactString("123-45-6789"); got != "***-**-6789" {
+		t.Errorf("unexpected redaction: %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Retry policies for AxonFlow API calls
+package axonflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryAttempt describes a failed attempt passed to a RetryPolicy.
+type RetryAttempt struct {
+	// Attempt is the number of attempts made so far (1 after the first failure)
+	Attempt int
+	// Method is the HTTP method of the request
+	Method string
+	// Path is the URL path of the request
+	Path string
+	// Idempotent reports whether the request can be repeated without side effects.
+	// GET, HEAD, PUT and DELETE are idempotent, as are POSTs that only evaluate
+	// policies (ExecuteQuery, pre-check, MCPQuery, pattern tests, budget checks,
+	// audit search).
+	Idempotent bool
+	// Err is the error from the attempt. HTTP failures are typed errors (*APIError,
+	// *RateLimitedError, ...); anything else is a transport error.
+	Err error
+}
+
+// RetryPolicy decides whether a failed request is retried, and how long to wait first.
+// It is consulted after every failed attempt of every API call. Implementations must
+// be safe for concurrent use.
+type RetryPolicy interface {
+	// Backoff returns the delay before the next attempt, or false to give up and
+	// return the error to the caller.
+	Backoff(attempt RetryAttempt) (time.Duration, bool)
+}
+
+// RetryPolicyFunc adapts an ordinary function to the RetryPolicy interface.
+type RetryPolicyFunc func(attempt RetryAttempt) (time.Duration, bool)
+
+// Backoff calls f(attempt).
+func (f RetryPolicyFunc) Backoff(attempt RetryAttempt) (time.Duration, bool) {
+	return f(attempt)
+}
+
+// ExponentialBackoff is the default RetryPolicy. It retries errors accepted by
+// IsRetryable with an exponentially growing delay (InitialDelay, 2x, 4x, ...) capped
+// at MaxDelay, randomised with full jitter so that many clients recovering from the
+// same outage do not retry in lockstep.
+//
+// A 429 response with a Retry-After header is retried no sooner than the server
+// asked. If Retry-After exceeds MaxDelay the request is not retried, so the caller
+// receives the *RateLimitedError and can decide for itself.
+type ExponentialBackoff struct {
+	MaxAttempts  int           // Total attempts, including the first (default: 3)
+	InitialDelay time.Duration // Base delay before the first retry (default: 1s)
+	MaxDelay     time.Duration // Upper bound for a single delay (default: 30s)
+	NoJitter     bool          // Use the exact exponential delay instead of a random one in [0, delay]
+}
+
+// Backoff implements RetryPolicy.
+func (b ExponentialBackoff) Backoff(a RetryAttempt) (time.Duration, bool) {
+	maxAttempts := b.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	if a.Attempt >= maxAttempts || !IsRetryable(a.Err, a.Idempotent) {
+		return 0, false
+	}
+
+	initial := b.InitialDelay
+	if initial <= 0 {
+		initial = time.Second
+	}
+	maxDelay := b.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+
+	// initial * 2^(attempt-1), without overflowing
+	delay := initial
+	for i := 1; i < a.Attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if !b.NoJitter {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+
+	var rl *RateLimitedError
+	if errors.As(a.Err, &rl) && rl.RetryAfter > 0 {
+		if rl.RetryAfter > maxDelay {
+			return 0, false
+		}
+		if rl.RetryAfter > delay {
+			delay = rl.RetryAfter
+		}
+	}
+
+	return delay, true
+}
+
+// NoRetry is a RetryPolicy that never retries.
+var NoRetry RetryPolicy = RetryPolicyFunc(func(RetryAttempt) (time.Duration, bool) {
+	return 0, false
+})
+
+// IsRetryable reports whether err is a transient failure worth retrying.
+//
+// Rate limits (429), 503 responses and failures to connect are always retryable: the
+// server did not act on the request. Other 5xx responses, 408 and network errors
+// after the request was sent (timeouts, resets) are retryable only for idempotent
+// requests, since the server may already have acted on them. Other 4xx responses and
+// cancellation are never retryable.
+func IsRetryable(err error, idempotent bool) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rl *RateLimitedError
+	if errors.As(err, &rl) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusServiceUnavailable:
+			return true
+		case http.StatusRequestTimeout, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusGatewayTimeout:
+			return idempotent
+		}
+		return false
+	}
+
+	// Malformed addresses, unknown hosts and bad certificates are configuration errors,
+	// not outages
+	var addrErr *net.AddrError
+	var dnsErr *net.DNSError
+	if errors.As(err, &addrErr) || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || isCertificateError(err) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	// Connection resets and timeouts after the request was sent. *url.Error is itself
+	// a net.Error, so look at what it wraps (e.g. an unsupported scheme is permanent).
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return idempotent
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && idempotent
+}
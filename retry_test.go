@@ -0,0 +1,308 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry retries quickly so tests exercising retries stay fast.
+var fastRetry = RetryConfig{
+	Enabled: true,
+	Policy:  ExponentialBackoff{MaxAttempts: 3, InitialDelay: time.Millisecond, NoJitter: true},
+}
+
+func TestExponentialBackoffDelays(t *testing.T) {
+	b := ExponentialBackoff{
+		MaxAttempts:  10,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		NoJitter:     true,
+	}
+	unavailable := &APIError{StatusCode: 503}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second, // capped
+		time.Second,
+	}
+	for i, want := range expected {
+		got, retry := b.Backoff(RetryAttempt{Attempt: i + 1, Err: unavailable})
+		if !retry {
+			t.Fatalf("attempt %d: expected retry", i+1)
+		}
+		if got != want {
+			t.Errorf("attempt %d: expected delay %v, got %v", i+1, want, got)
+		}
+	}
+
+	// Large attempt numbers must not overflow
+	if got, _ := b.Backoff(RetryAttempt{Attempt: 9, Err: unavailable}); got != time.Second {
+		t.Errorf("expected capped delay, got %v", got)
+	}
+}
+
+func TestExponentialBackoffMaxAttempts(t *testing.T) {
+	b := ExponentialBackoff{MaxAttempts: 3, InitialDelay: time.Millisecond}
+	err := &APIError{StatusCode: 503}
+
+	if _, retry := b.Backoff(RetryAttempt{Attempt: 2, Err: err}); !retry {
+		t.Error("expected retry after attempt 2 of 3")
+	}
+	if _, retry := b.Backoff(RetryAttempt{Attempt: 3, Err: err}); retry {
+		t.Error("expected no retry after attempt 3 of 3")
+	}
+}
+
+func TestExponentialBackoffJitter(t *testing.T) {
+	b := ExponentialBackoff{MaxAttempts: 5, InitialDelay: 100 * time.Millisecond}
+	err := &APIError{StatusCode: 503}
+
+	distinct := map[time.Duration]bool{}
+	for i := 0; i < 50; i++ {
+		d, retry := b.Backoff(RetryAttempt{Attempt: 3, Err: err})
+		if !retry {
+			t.Fatal("expected retry")
+		}
+		if d < 0 || d > 400*time.Millisecond {
+			t.Fatalf("jittered delay %v outside [0, 400ms]", d)
+		}
+		distinct[d] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("expected jitter to produce varying delays")
+	}
+}
+
+func TestExponentialBackoffRetryAfter(t *testing.T) {
+	b := ExponentialBackoff{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Second}
+
+	limited := &RateLimitedError{APIError: &APIError{StatusCode: 429}, RetryAfter: 2 * time.Second}
+	d, retry := b.Backoff(RetryAttempt{Attempt: 1, Err: limited})
+	if !retry || d != 2*time.Second {
+		t.Errorf("expected retry after 2s, got %v (retry=%v)", d, retry)
+	}
+
+	tooLong := &RateLimitedError{APIError: &APIError{StatusCode: 429}, RetryAfter: time.Minute}
+	if _, retry := b.Backoff(RetryAttempt{Attempt: 1, Err: tooLong}); retry {
+		t.Error("expected no retry when Retry-After exceeds MaxDelay")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	dialErr := &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	resetErr := &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+
+	tests := []struct {
+		name       string
+		err        error
+		idempotent bool
+		expected   bool
+	}{
+		{"nil", nil, true, false},
+		{"503 non-idempotent", &APIError{StatusCode: 503}, false, true},
+		{"500 idempotent", &APIError{StatusCode: 500}, true, true},
+		{"500 non-idempotent", &APIError{StatusCode: 500}, false, false},
+		{"502 idempotent", &APIError{StatusCode: 502}, true, true},
+		{"501", &APIError{StatusCode: 501}, true, false},
+		{"429", &RateLimitedError{APIError: &APIError{StatusCode: 429}}, false, true},
+		{"400", &ValidationError{APIError: &APIError{StatusCode: 400}}, true, false},
+		{"401", &AuthError{APIError: &APIError{StatusCode: 401}}, true, false},
+		{"policy block", &PolicyBlockedError{APIError: &APIError{StatusCode: 403}}, true, false},
+		{"dial error non-idempotent", dialErr, false, true},
+		{"connection reset idempotent", resetErr, true, true},
+		{"connection reset non-idempotent", resetErr, false, false},
+		{"unexpected EOF idempotent", &url.Error{Op: "Get", URL: "http://x", Err: io.ErrUnexpectedEOF}, true, true},
+		{"invalid port", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: &net.AddrError{Err: "invalid port"}}}, true, false},
+		{"unsupported scheme", &url.Error{Op: "Get", URL: "/x", Err: errors.New("unsupported protocol scheme")}, true, false},
+		{"cancelled", &url.Error{Op: "Get", URL: "http://x", Err: context.Canceled}, true, false},
+		{"wrapped 503", fmt.Errorf("request failed: %w", &APIError{StatusCode: 503}), false, true},
+		{"plain error", errors.New("boom"), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err, tt.idempotent); got != tt.expected {
+				t.Errorf("IsRetryable(%v, %v) = %v, want %v", tt.err, tt.idempotent, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPolicyRequestRetriesTransient503(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": "agent restarting"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(staticPoliciesResponse{Policies: []StaticPolicy{sampleStaticPolicy}})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+	})
+
+	policies, err := client.ListStaticPolicies(nil)
+	if err != nil {
+		t.Fatalf("expected retry to recover from 503, got %v", err)
+	}
+	if len(policies) != 1 {
+		t.Errorf("expected 1 policy, got %d", len(policies))
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestNonIdempotentPostNotRetriedOn500(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+	})
+
+	_, err := client.CreateStaticPolicy(&CreateStaticPolicyRequest{Name: "p", Pattern: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Fatalf("expected HTTP 500 APIError, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("a POST that may have been applied must not be retried on 500, got %d calls", calls)
+	}
+}
+
+func TestNonIdempotentPostRetriedOn503WithSameBody(t *testing.T) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(sampleStaticPolicy)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+	})
+
+	if _, err := client.CreateStaticPolicy(&CreateStaticPolicyRequest{Name: "p", Pattern: "x"}); err != nil {
+		t.Fatalf("expected success after 503s, got %v", err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(bodies))
+	}
+	for i, b := range bodies {
+		if b == "" || b != bodies[0] {
+			t.Errorf("attempt %d sent body %q, want %q", i+1, b, bodies[0])
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    fastRetry,
+	})
+
+	_, err := client.GetBudget(context.Background(), "b-1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 503 {
+		t.Fatalf("expected HTTP 503 APIError, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestCustomRetryPolicy(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var seen []RetryAttempt
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry: RetryConfig{
+			Enabled: true,
+			Policy: RetryPolicyFunc(func(a RetryAttempt) (time.Duration, bool) {
+				seen = append(seen, a)
+				return 0, a.Attempt < 2
+			}),
+		},
+	})
+
+	_, err := client.ListDynamicPolicies(nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if calls != 2 || len(seen) != 2 {
+		t.Fatalf("expected 2 attempts and 2 policy calls, got %d and %d", calls, len(seen))
+	}
+	if seen[0].Method != "GET" || seen[0].Path != "/api/v1/dynamic-policies" || !seen[0].Idempotent {
+		t.Errorf("unexpected attempt info: %+v", seen[0])
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{
+		Endpoint: server.URL,
+		ClientID: "test",
+		Retry:    RetryConfig{Enabled: false, MaxAttempts: 3},
+	})
+
+	client.ListStaticPolicies(nil)
+	if calls != 1 {
+		t.Errorf("expected 1 call with retries disabled, got %d", calls)
+	}
+}
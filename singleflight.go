@@ -0,0 +1,172 @@
+// Request deduplication for identical concurrent governance checks
+package axonflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// flightGroup shares one in-flight call among concurrent callers with the same key.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a call in progress. It is cancelled once every caller waiting for it
+// has given up.
+type flight struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn once for all concurrent callers with the same key and returns its
+// result. shared is true for callers that joined a call started by another caller.
+//
+// fn runs with a context that keeps the values of the first caller's ctx but not its
+// cancellation, so that one caller giving up does not fail the others. Each caller
+// stops waiting when its own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (value interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f, shared := g.flights[key]
+	if shared {
+		f.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.flights[key] = f
+		go func() {
+			defer cancel()
+			f.value, f.err = fn(callCtx)
+			g.mu.Lock()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.err, shared
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Nobody is waiting for the result any more
+			f.cancel()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err(), shared
+	}
+}
+
+// collapse runs fn through the client's flight group when SingleFlight is enabled,
+// and directly otherwise. Callers that joined another call are reported to the
+// Observer.
+func (c *AxonFlowClient) collapse(ctx context.Context, key, requestType string, fn func(context.Context) (interface{}, error)) (interface{}, error, bool) {
+	if c.flights == nil || cacheBypassed(ctx) {
+		value, err := fn(ctx)
+		return value, err, false
+	}
+	value, err, shared := c.flights.do(ctx, key, fn)
+	if shared {
+		c.logger.DebugContext(ctx, "AxonFlow call joined an identical call in flight",
+			"request_type", requestType)
+		c.observer.OnRequestCollapsed(ctx, ObserverEvent{RequestType: requestType})
+	}
+	return value, err, shared
+}
+
+// preCheckKey returns the single-flight key of a pre-check, built like queryCacheKey.
+func (c *AxonFlowClient) preCheckKey(userToken, query string, dataSources []string, queryContext map[string]interface{}) string {
+	h := sha256.New()
+	for _, part := range []string{c.config.ClientID, userToken, query} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	// Length-prefixed, so that no two lists give the same bytes
+	for _, source := range dataSources {
+		h.Write([]byte(strconv.Itoa(len(source)) + ":" + source))
+	}
+	h.Write([]byte{0})
+	if len(queryContext) > 0 {
+		canonical, err := json.Marshal(queryContext)
+		if err != nil {
+			canonical = []byte(err.Error())
+		}
+		h.Write(canonical)
+	}
+	return "axonflow:pre-check:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// clone returns a copy of r for one caller of a collapsed call, so that callers can
+// modify their results independently.
+func (r *ClientResponse) clone() *ClientResponse {
+	copied := *r
+	copied.Data = cloneValue(r.Data)
+	copied.Metadata = cloneMap(r.Metadata)
+	if r.PolicyInfo != nil {
+		info := *r.PolicyInfo
+		info.PoliciesEvaluated = append([]string(nil), r.PolicyInfo.PoliciesEvaluated...)
+		info.StaticChecks = append([]string(nil), r.PolicyInfo.StaticChecks...)
+		copied.PolicyInfo = &info
+	}
+	return &copied
+}
+
+// clone returns a copy of r for one caller of a collapsed call.
+func (r *PolicyApprovalResult) clone() *PolicyApprovalResult {
+	copied := *r
+	copied.ApprovedData = cloneMap(r.ApprovedData)
+	copied.Policies = append([]string(nil), r.Policies...)
+	if r.RateLimitInfo != nil {
+		info := *r.RateLimitInfo
+		copied.RateLimitInfo = &info
+	}
+	return &copied
+}
+
+// cloneValue returns a deep copy of a decoded JSON value.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, e := range v {
+			copied[i] = cloneValue(e)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = cloneValue(v)
+	}
+	return copied
+}
@@ -0,0 +1,225 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingServer answers queries and pre-checks once release is closed.
+func newBlockingServer(t *testing.T, release chan struct{}) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		if r.URL.Path == "/api/policy/pre-check" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"context_id": "ctx-1", "approved": true})
+			return
+		}
+		json.NewEncoder(w).Encode(ClientResponse{Success: true, Data: "ok"})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// waitForWaiters waits until n callers wait for a call in flight.
+func waitForWaiters(t *testing.T, g *flightGroup, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		waiting := 0
+		for _, f := range g.flights {
+			waiting += f.waiters
+		}
+		g.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers", n)
+}
+
+func TestSingleFlightExecuteQuery(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newBlockingServer(t, release)
+	metrics := NewMetrics()
+	client, _ := New(server.URL, WithSingleFlight(), WithObserver(metrics))
+
+	var wg sync.WaitGroup
+	responses := make([]*ClientResponse, 10)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.ExecuteQuery("user", "same prompt", "chat", nil)
+			if err != nil {
+				t.Error(err)
+			}
+			responses[i] = resp
+		}(i)
+	}
+	waitForWaiters(t, client.flights, 10)
+	close(release)
+	wg.Wait()
+
+	if *requests != 1 {
+		t.Errorf("expected one HTTP call, got %d", *requests)
+	}
+	for _, resp := range responses[1:] {
+		if resp == nil || resp == responses[0] || resp.Data != "ok" {
+			t.Errorf("expected a separate copy of the shared response, got %+v", resp)
+		}
+	}
+
+	var out strings.Builder
+	metrics.WritePrometheus(&out)
+	if !strings.Contains(out.String(), `axonflow_requests_collapsed_total{request_type="chat"} 9`) {
+		t.Errorf("expected 9 collapsed calls, got:\n%s", out.String())
+	}
+}
+
+func TestSingleFlightPreCheck(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newBlockingServer(t, release)
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"), WithSingleFlight())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.GetPolicyApprovedContext("user", "same prompt", []string{"postgres"}, nil)
+			if err != nil || result.ContextID != "ctx-1" {
+				t.Errorf("unexpected result: %+v, %v", result, err)
+			}
+		}()
+	}
+	waitForWaiters(t, client.flights, 3)
+	close(release)
+	wg.Wait()
+
+	if *requests != 1 {
+		t.Errorf("expected one HTTP call, got %d", *requests)
+	}
+}
+
+func TestSingleFlightCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newBlockingServer(t, release)
+	client, _ := New(server.URL, WithSingleFlight(), WithoutCache())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.ExecuteQueryContext(ctx, "user", "q", "chat", nil)
+		first <- err
+	}()
+	waitForWaiters(t, client.flights, 1)
+	second := make(chan error, 1)
+	go func() {
+		_, err := client.ExecuteQuery("user", "q", "chat", nil)
+		second <- err
+	}()
+	waitForWaiters(t, client.flights, 2)
+
+	// The first caller giving up does not fail the call it started
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled caller to stop waiting, got %v", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("expected the second caller to get the result, got %v", err)
+	}
+
+	// Different queries and later calls are not collapsed
+	client.ExecuteQuery("user", "q", "chat", nil)
+	client.ExecuteQuery("user", "other", "chat", nil)
+	if *requests != 3 {
+		t.Errorf("expected 3 HTTP calls, got %d", *requests)
+	}
+}
+
+func TestSingleFlightDisabled(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	server, requests := newBlockingServer(t, release)
+	client, _ := New(server.URL, WithoutCache())
+
+	if client.flights != nil {
+		t.Fatal("expected single-flight to be off by default")
+	}
+	client.ExecuteQuery("user", "q", "chat", nil)
+	client.ExecuteQuery("user", "q", "chat", nil)
+	if *requests != 2 {
+		t.Errorf("expected 2 HTTP calls, got %d", *requests)
+	}
+}
+
+func TestSingleFlightResultsAreCopied(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if r.URL.Path == "/api/policy/pre-check" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"context_id": "ctx-1", "approved": true, "approved_data": map[string]interface{}{"rows": []interface{}{"a"}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true, "data": map[string]interface{}{"answer": "ok"}, "metadata": map[string]interface{}{"model": "m"},
+		})
+	}))
+	defer server.Close()
+	client, _ := New(server.URL, WithCredentials("tenant", "secret"), WithSingleFlight())
+
+	var wg sync.WaitGroup
+	responses := make([]*ClientResponse, 2)
+	results := make([]*PolicyApprovalResult, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = client.ExecuteQuery("user", "same prompt", "chat", nil)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = client.GetPolicyApprovedContext("user", "same prompt", nil, nil)
+		}(i)
+	}
+	waitForWaiters(t, client.flights, 4)
+	close(release)
+	wg.Wait()
+
+	responses[0].Data.(map[string]interface{})["answer"] = "changed"
+	responses[0].Metadata["model"] = "changed"
+	results[0].ApprovedData["rows"].([]interface{})[0] = "changed"
+	if responses[1].Data.(map[string]interface{})["answer"] != "ok" || responses[1].Metadata["model"] != "m" {
+		t.Errorf("expected an independent response, got %+v", responses[1])
+	}
+	if results[1].ApprovedData["rows"].([]interface{})[0] != "a" {
+		t.Errorf("expected independent approved data, got %+v", results[1].ApprovedData)
+	}
+}
+
+func TestPreCheckKeyDataSources(t *testing.T) {
+	client, _ := New("http://localhost")
+	joined := client.preCheckKey("user", "query", []string{"a\x00b"}, nil)
+	split := client.preCheckKey("user", "query", []string{"a", "b"}, nil)
+	if joined == split {
+		t.Error("expected different keys for different data source lists")
+	}
+	if client.preCheckKey("user", "query", nil, nil) != client.preCheckKey("user", "query", []string{}, nil) {
+		t.Error("expected nil and empty data sources to share a key")
+	}
+}
@@ -0,0 +1,412 @@
+// Multi-tenant client pool
+package axonflow
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TenantPoolConfig configures a TenantPool.
+type TenantPoolConfig struct {
+	// Config holds the settings shared by all tenants: endpoint, transport, TLS,
+	// cache, retries, circuit breaker, logger and observer. Its credentials (ClientID
+	// and ClientSecret, APIKey, TokenURL or Authenticator) are used by tenants for
+	// which Credentials returns none.
+	Config AxonFlowConfig
+
+	// Credentials returns a tenant's own credentials. It is called once, when the
+	// tenant's client is created; an error fails that call to For. If nil, all tenants
+	// use the shared credentials.
+	Credentials func(ctx context.Context, tenantID string) (TenantCredentials, error)
+
+	// IdleTimeout evicts tenants whose client has not been used for this long
+	// (default: 30m; negative: never)
+	IdleTimeout time.Duration
+	// MaxTenants bounds the number of tenants kept; the least recently used tenant is
+	// evicted first (default: unlimited)
+	MaxTenants int
+}
+
+// TenantCredentials are the credentials of one tenant in a TenantPool. The tenant ID
+// is used as the ClientID and sent as X-Tenant-ID. Set at most one of the fields;
+// ClientSecret is sent as Basic credentials, or exchanged for OAuth2 tokens if the
+// pool's Config has a TokenURL.
+type TenantCredentials struct {
+	ClientSecret  string
+	APIKey        string
+	Authenticator Authenticator
+}
+
+func (c TenantCredentials) isZero() bool {
+	return c.ClientSecret == "" && c.APIKey == "" && c.Authenticator == nil
+}
+
+// TenantStats are the counters of one tenant in a TenantPool.
+type TenantStats struct {
+	TenantID     string
+	Requests     int64 // HTTP calls to AxonFlow
+	Errors       int64 // calls that failed or returned an error status
+	CacheHits    int64
+	CacheMisses  int64
+	PolicyBlocks int64
+	FailOpen     int64 // results produced by the failure policy
+	Created      time.Time
+	LastUsed     time.Time
+}
+
+// TenantPool hands out one client per tenant. All clients share the pool's HTTP
+// transport (and so its connection pool), response cache, circuit breaker and
+// background audit queue, while each sends its own X-Tenant-ID and credentials.
+// Cached responses and FailCached decisions are keyed by tenant, so tenants never see
+// each other's entries.
+//
+// Clients are created on first use and evicted after IdleTimeout or when MaxTenants
+// is exceeded. Eviction only drops the pool's reference: a client still held by a
+// caller keeps working until the pool is closed.
+type TenantPool struct {
+	root        *AxonFlowClient
+	credentials func(ctx context.Context, tenantID string) (TenantCredentials, error)
+	idleTimeout time.Duration
+	maxTenants  int
+	now         func() time.Time
+
+	mu        sync.Mutex
+	tenants   map[string]*tenantEntry
+	order     *list.List // of *tenantEntry, front = most recently used
+	lastSweep time.Time
+}
+
+// tenantEntry is a tenant's client; ready is closed once it has been created.
+type tenantEntry struct {
+	id      string
+	ready   chan struct{}
+	client  *AxonFlowClient
+	err     error
+	stats   *tenantStats
+	element *list.Element
+}
+
+// NewTenantPool creates a pool. config.Config is validated like New and, like
+// NewClient, has retries and caching on unless Retry.MaxAttempts or Cache.TTL is set.
+func NewTenantPool(config TenantPoolConfig) (*TenantPool, error) {
+	if err := config.Config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.MaxTenants < 0 {
+		return nil, newValidationError("MaxTenants", "invalid config: MaxTenants must not be negative")
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	config.Config.enableByDefault()
+
+	return &TenantPool{
+		root:        newClient(config.Config),
+		credentials: config.Credentials,
+		idleTimeout: config.IdleTimeout,
+		maxTenants:  config.MaxTenants,
+		now:         time.Now,
+		tenants:     make(map[string]*tenantEntry),
+		order:       list.New(),
+	}, nil
+}
+
+// tenantKey is the context key for ContextWithTenant.
+type tenantKey struct{}
+
+// ContextWithTenant returns a context that selects tenantID in
+// TenantPool.ClientFromContext.
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set with ContextWithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// For returns the client for tenantID, creating it if needed.
+func (p *TenantPool) For(tenantID string) (*AxonFlowClient, error) {
+	return p.ForContext(context.Background(), tenantID)
+}
+
+// ForContext is like For but passes ctx to the Credentials callback and stops
+// waiting for a client being created when ctx is done.
+func (p *TenantPool) ForContext(ctx context.Context, tenantID string) (*AxonFlowClient, error) {
+	if tenantID == "" {
+		return nil, newValidationError("tenantID", "tenantID is required")
+	}
+	if err := p.root.checkOpen(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	now := p.now()
+	p.sweep(now)
+
+	entry, ok := p.tenants[tenantID]
+	if ok {
+		p.order.MoveToFront(entry.element)
+		p.mu.Unlock()
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		entry.stats.touch(p.now())
+		return entry.client, nil
+	}
+
+	entry = &tenantEntry{id: tenantID, ready: make(chan struct{}), stats: &tenantStats{created: now}}
+	entry.stats.touch(now)
+	entry.element = p.order.PushFront(entry)
+	p.tenants[tenantID] = entry
+	p.evictOverflow()
+	p.mu.Unlock()
+
+	entry.client, entry.err = p.newTenantClient(ctx, tenantID, entry.stats)
+	if entry.err != nil {
+		// Forget the failure so that the next call tries again
+		p.mu.Lock()
+		p.remove(entry)
+		p.mu.Unlock()
+	}
+	close(entry.ready)
+
+	return entry.client, entry.err
+}
+
+// ClientFromContext returns the client for the tenant set with ContextWithTenant.
+func (p *TenantPool) ClientFromContext(ctx context.Context) (*AxonFlowClient, error) {
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, newValidationError("tenantID", "no tenant in context (see ContextWithTenant)")
+	}
+	return p.ForContext(ctx, tenantID)
+}
+
+// newTenantClient creates a client sharing the pool's resources.
+func (p *TenantPool) newTenantClient(ctx context.Context, tenantID string, stats *tenantStats) (*AxonFlowClient, error) {
+	root := p.root
+	config := root.config
+	config.ClientID = tenantID
+	auth := root.auth
+
+	if p.credentials != nil {
+		creds, err := p.credentials(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !creds.isZero() {
+			config.ClientSecret = creds.ClientSecret
+			config.APIKey = creds.APIKey
+			config.Authenticator = creds.Authenticator
+			auth = config.authenticator(root.httpClient)
+		}
+	}
+
+	root.logger.Debug("AxonFlow tenant client created", "tenant_id", tenantID)
+
+	return &AxonFlowClient{
+		config:        config,
+		logger:        root.logger.With("tenant_id", tenantID),
+		observer:      &tenantObserver{next: root.observer, tenantID: tenantID, stats: stats, now: p.now},
+		httpClient:    root.httpClient,
+		mapHttpClient: root.mapHttpClient,
+		cache:         root.cache,
+		retryPolicy:   root.retryPolicy,
+		breaker:       root.breaker,
+		balancer:      root.balancer,
+		limiter:       root.limiter,
+		flights:       root.flights,
+		decisions:     root.decisions,
+		auth:          auth,
+		life:          root.life,
+		pooled:        true,
+	}, nil
+}
+
+// Evict removes a tenant's client from the pool. A new client is created on the next
+// call to For.
+func (p *TenantPool) Evict(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if entry, ok := p.tenants[tenantID]; ok {
+		p.remove(entry)
+	}
+}
+
+// Len returns the number of tenants in the pool.
+func (p *TenantPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tenants)
+}
+
+// Stats returns the counters of every tenant in the pool, ordered by tenant ID.
+func (p *TenantPool) Stats() []TenantStats {
+	p.mu.Lock()
+	entries := make([]*tenantEntry, 0, len(p.tenants))
+	for _, entry := range p.tenants {
+		entries = append(entries, entry)
+	}
+	p.mu.Unlock()
+
+	stats := make([]TenantStats, 0, len(entries))
+	for _, entry := range entries {
+		stats = append(stats, entry.stats.snapshot(entry.id))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TenantID < stats[j].TenantID })
+	return stats
+}
+
+// Close closes the pool and every client it handed out, like AxonFlowClient.Close:
+// it waits for pending async audits of all tenants until ctx is done.
+func (p *TenantPool) Close(ctx context.Context) error {
+	err := p.root.Close(ctx)
+
+	p.mu.Lock()
+	p.tenants = make(map[string]*tenantEntry)
+	p.order.Init()
+	p.mu.Unlock()
+
+	return err
+}
+
+// sweep evicts idle tenants, at most every half IdleTimeout. It must be called with
+// p.mu held.
+func (p *TenantPool) sweep(now time.Time) {
+	if p.idleTimeout < 0 || now.Sub(p.lastSweep) < p.idleTimeout/2 {
+		return
+	}
+	p.lastSweep = now
+
+	for _, entry := range p.tenants {
+		if now.Sub(entry.stats.lastUsedTime()) >= p.idleTimeout {
+			p.remove(entry)
+		}
+	}
+}
+
+// evictOverflow evicts least recently used tenants beyond MaxTenants. It must be
+// called with p.mu held.
+func (p *TenantPool) evictOverflow() {
+	for p.maxTenants > 0 && len(p.tenants) > p.maxTenants {
+		p.remove(p.order.Back().Value.(*tenantEntry))
+	}
+}
+
+// remove must be called with p.mu held.
+func (p *TenantPool) remove(entry *tenantEntry) {
+	if p.tenants[entry.id] == entry {
+		delete(p.tenants, entry.id)
+		p.order.Remove(entry.element)
+		p.root.logger.Debug("AxonFlow tenant client evicted", "tenant_id", entry.id)
+	}
+}
+
+// tenantStats holds a tenant's counters.
+type tenantStats struct {
+	created      time.Time
+	lastUsed     atomic.Int64 // UnixNano
+	requests     atomic.Int64
+	errors       atomic.Int64
+	cacheHits    atomic.Int64
+	cacheMisses  atomic.Int64
+	policyBlocks atomic.Int64
+	failOpen     atomic.Int64
+}
+
+func (s *tenantStats) touch(now time.Time) {
+	s.lastUsed.Store(now.UnixNano())
+}
+
+func (s *tenantStats) lastUsedTime() time.Time {
+	return time.Unix(0, s.lastUsed.Load())
+}
+
+func (s *tenantStats) snapshot(tenantID string) TenantStats {
+	return TenantStats{
+		TenantID:     tenantID,
+		Requests:     s.requests.Load(),
+		Errors:       s.errors.Load(),
+		CacheHits:    s.cacheHits.Load(),
+		CacheMisses:  s.cacheMisses.Load(),
+		PolicyBlocks: s.policyBlocks.Load(),
+		FailOpen:     s.failOpen.Load(),
+		Created:      s.created,
+		LastUsed:     s.lastUsedTime(),
+	}
+}
+
+// tenantObserver sets ObserverEvent.TenantID, counts the tenant's stats and forwards
+// events to the pool's observer.
+type tenantObserver struct {
+	next     Observer
+	tenantID string
+	stats    *tenantStats
+	now      func() time.Time
+}
+
+func (o *tenantObserver) OnRequestStart(ctx context.Context, e ObserverEvent) {
+	o.stats.touch(o.now())
+	e.TenantID = o.tenantID
+	o.next.OnRequestStart(ctx, e)
+}
+
+func (o *tenantObserver) OnRequestEnd(ctx context.Context, e ObserverEvent) {
+	o.stats.requests.Add(1)
+	if e.Err != nil {
+		o.stats.errors.Add(1)
+	}
+	e.TenantID = o.tenantID
+	o.next.OnRequestEnd(ctx, e)
+}
+
+func (o *tenantObserver) OnRetry(ctx context.Context, e ObserverEvent) {
+	e.TenantID = o.tenantID
+	o.next.OnRetry(ctx, e)
+}
+
+func (o *tenantObserver) OnCacheHit(ctx context.Context, e ObserverEvent) {
+	o.stats.cacheHits.Add(1)
+	e.TenantID = o.tenantID
+	o.next.OnCacheHit(ctx, e)
+}
+
+func (o *tenantObserver) OnCacheMiss(ctx context.Context, e ObserverEvent) {
+	o.stats.cacheMisses.Add(1)
+	e.TenantID = o.tenantID
+	o.next.OnCacheMiss(ctx, e)
+}
+
+func (o *tenantObserver) OnFailOpen(ctx context.Context, e ObserverEvent) {
+	o.stats.failOpen.Add(1)
+	e.TenantID = o.tenantID
+	o.next.OnFailOpen(ctx, e)
+}
+
+func (o *tenantObserver) OnPolicyBlock(ctx context.Context, e ObserverEvent) {
+	o.stats.policyBlocks.Add(1)
+	e.TenantID = o.tenantID
+	o.next.OnPolicyBlock(ctx, e)
+}
+
+func (o *tenantObserver) OnAuditFailure(ctx context.Context, e ObserverEvent) {
+	e.TenantID = o.tenantID
+	o.next.OnAuditFailure(ctx, e)
+}
+
+func (o *tenantObserver) OnRequestCollapsed(ctx context.Context, e ObserverEvent) {
+	e.TenantID = o.tenantID
+	o.next.OnRequestCollapsed(ctx, e)
+}
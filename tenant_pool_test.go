@@ -0,0 +1,324 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTenantServer answers ExecuteQuery with the X-Tenant-ID and Authorization
+// headers it received.
+func newTenantServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClientResponse{Success: true, Data: map[string]interface{}{
+			"tenant":        r.Header.Get("X-Tenant-ID"),
+			"authorization": r.Header.Get("Authorization"),
+		}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func tenantHeaders(t *testing.T, client *AxonFlowClient, query string) (tenant, authorization string) {
+	t.Helper()
+	resp, err := client.ExecuteQuery("user", query, "chat", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := resp.Data.(map[string]interface{})
+	return data["tenant"].(string), data["authorization"].(string)
+}
+
+func TestTenantPoolScopesTenants(t *testing.T) {
+	server := newTenantServer(t)
+	pool, err := NewTenantPool(TenantPoolConfig{
+		Config: AxonFlowConfig{Endpoint: server.URL, APIKey: "shared-key"},
+		Credentials: func(_ context.Context, tenantID string) (TenantCredentials, error) {
+			if tenantID == "acme" {
+				return TenantCredentials{APIKey: "acme-key"}, nil
+			}
+			return TenantCredentials{}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close(context.Background())
+
+	acme, _ := pool.For("acme")
+	globex, _ := pool.For("globex")
+
+	if tenant, auth := tenantHeaders(t, acme, "q"); tenant != "acme" || auth != "Bearer acme-key" {
+		t.Errorf("unexpected acme headers: %q, %q", tenant, auth)
+	}
+	if tenant, auth := tenantHeaders(t, globex, "q"); tenant != "globex" || auth != "Bearer shared-key" {
+		t.Errorf("unexpected globex headers: %q, %q (cached response of another tenant?)", tenant, auth)
+	}
+
+	if acme.httpClient != globex.httpClient || acme.cache != globex.cache || acme.life != globex.life {
+		t.Error("expected tenants to share the transport, cache and lifecycle")
+	}
+	if again, _ := pool.For("acme"); again != acme {
+		t.Error("expected the same client for the same tenant")
+	}
+}
+
+func TestTenantPoolCreatesClientsOnce(t *testing.T) {
+	var calls int32
+	pool, _ := NewTenantPool(TenantPoolConfig{
+		Config: AxonFlowConfig{Endpoint: "http://localhost:8080"},
+		Credentials: func(context.Context, string) (TenantCredentials, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(20 * time.Millisecond)
+			return TenantCredentials{ClientSecret: "s3cret"}, nil
+		},
+	})
+
+	var wg sync.WaitGroup
+	clients := make([]*AxonFlowClient, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = pool.For("acme")
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected one Credentials call, got %d", calls)
+	}
+	for _, client := range clients {
+		if client == nil || client != clients[0] {
+			t.Fatal("expected all callers to get the same client")
+		}
+	}
+}
+
+func TestTenantPoolCredentialsError(t *testing.T) {
+	vaultErr := errors.New("vault sealed")
+	var fail atomic.Bool
+	fail.Store(true)
+	pool, _ := NewTenantPool(TenantPoolConfig{
+		Config: AxonFlowConfig{Endpoint: "http://localhost:8080"},
+		Credentials: func(context.Context, string) (TenantCredentials, error) {
+			if fail.Load() {
+				return TenantCredentials{}, vaultErr
+			}
+			return TenantCredentials{APIKey: "key"}, nil
+		},
+	})
+
+	if _, err := pool.For("acme"); !errors.Is(err, vaultErr) {
+		t.Errorf("expected the Credentials error, got %v", err)
+	}
+	if pool.Len() != 0 {
+		t.Errorf("expected the failed tenant not to be kept, got %d tenants", pool.Len())
+	}
+
+	fail.Store(false)
+	if _, err := pool.For("acme"); err != nil {
+		t.Errorf("expected the next call to retry, got %v", err)
+	}
+}
+
+func TestTenantPoolEviction(t *testing.T) {
+	pool, _ := NewTenantPool(TenantPoolConfig{
+		Config:      AxonFlowConfig{Endpoint: "http://localhost:8080"},
+		IdleTimeout: 10 * time.Minute,
+		MaxTenants:  2,
+	})
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	a, _ := pool.For("a")
+	pool.For("b")
+	pool.For("a")
+	pool.For("c") // evicts b, the least recently used
+	if pool.Len() != 2 {
+		t.Fatalf("expected 2 tenants, got %d", pool.Len())
+	}
+	if again, _ := pool.For("a"); again != a {
+		t.Error("expected tenant a to be kept")
+	}
+	if stats := pool.Stats(); stats[0].TenantID != "a" || stats[1].TenantID != "c" {
+		t.Errorf("expected tenants a and c, got %+v", stats)
+	}
+
+	now = now.Add(11 * time.Minute)
+	pool.For("d")
+	if pool.Len() != 1 {
+		t.Errorf("expected idle tenants to be evicted, got %d tenants", pool.Len())
+	}
+	if again, _ := pool.For("a"); again == a {
+		t.Error("expected a new client after eviction")
+	}
+
+	pool.Evict("a")
+	if pool.Len() != 1 {
+		t.Errorf("expected Evict to remove the tenant, got %d tenants", pool.Len())
+	}
+}
+
+func TestTenantPoolStatsAndMetrics(t *testing.T) {
+	server := newTenantServer(t)
+	metrics := NewMetrics()
+	pool, _ := NewTenantPool(TenantPoolConfig{
+		Config: AxonFlowConfig{Endpoint: server.URL, Observer: metrics, Cache: CacheConfig{Enabled: true, TTL: time.Minute}},
+	})
+	defer pool.Close(context.Background())
+
+	ctx := ContextWithTenant(context.Background(), "acme")
+	client, err := pool.ClientFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ExecuteQueryContext(ctx, "user", "q", "chat", nil)
+	client.ExecuteQueryContext(ctx, "user", "q", "chat", nil)
+
+	stats := pool.Stats()
+	if len(stats) != 1 || stats[0].Requests != 1 || stats[0].CacheHits != 1 || stats[0].CacheMisses != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	var out strings.Builder
+	metrics.WritePrometheus(&out)
+	if want := `axonflow_tenant_requests_total{tenant="acme",status="200"} 1`; !strings.Contains(out.String(), want) {
+		t.Errorf("expected %q in metrics output:\n%s", want, out.String())
+	}
+
+	if _, err := pool.ClientFromContext(context.Background()); err == nil {
+		t.Error("expected an error without a tenant in the context")
+	}
+}
+
+func TestTenantPoolClose(t *testing.T) {
+	var audits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&audits, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "audit_id": "a-1"})
+	}))
+	defer server.Close()
+
+	pool, _ := NewTenantPool(TenantPoolConfig{Config: AxonFlowConfig{Endpoint: server.URL}})
+	acme, _ := pool.For("acme")
+	globex, _ := pool.For("globex")
+
+	// Closing a pooled client must not close the shared resources
+	if err := acme.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	acme.AuditLLMCallAsync(context.Background(), "ctx-1", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil)
+	globex.AuditLLMCallAsync(context.Background(), "ctx-2", "summary", "openai", "gpt-4", TokenUsage{}, 10, nil)
+
+	if err := pool.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if audits != 2 {
+		t.Errorf("expected pool Close to drain both tenants' audits, got %d", audits)
+	}
+	if _, err := pool.For("acme"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+	if _, err := acme.ListStaticPolicies(nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed from a pooled client, got %v", err)
+	}
+}
+
+func TestTenantPoolValidation(t *testing.T) {
+	if _, err := NewTenantPool(TenantPoolConfig{}); err == nil {
+		t.Error("expected an error without an endpoint")
+	}
+	pool, _ := NewTenantPool(TenantPoolConfig{Config: AxonFlowConfig{Endpoint: "http://localhost:8080"}})
+	var valErr *ValidationError
+	if _, err := pool.For(""); !errors.As(err, &valErr) {
+		t.Errorf("expected a validation error for an empty tenant, got %v", err)
+	}
+}
+
+func TestTenantPoolFailCachedScopesTenants(t *testing.T) {
+	var down int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"context_id":    "ctx-" + r.Header.Get("X-Tenant-ID"),
+			"approved":      true,
+			"approved_data": map[string]interface{}{"tenant": r.Header.Get("X-Tenant-ID")},
+			"expires_at":    time.Now().Add(time.Minute).Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	pool, err := NewTenantPool(TenantPoolConfig{Config: AxonFlowConfig{
+		Endpoint:      server.URL,
+		APIKey:        "shared-key",
+		Retry:         RetryConfig{MaxAttempts: 1},
+		FailurePolicy: FailurePolicy{PreCheck: FailCached},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close(context.Background())
+
+	acme, _ := pool.For("acme")
+	globex, _ := pool.For("globex")
+
+	if _, err := acme.PreCheck("u", "q", nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	atomic.StoreInt32(&down, 1)
+
+	result, err := acme.PreCheck("u", "q", nil, nil)
+	if err != nil || result.Fallback != FailCached || result.ContextID != "ctx-acme" {
+		t.Errorf("expected acme's cached decision, got %+v, %v", result, err)
+	}
+	if result, err := globex.PreCheck("u", "q", nil, nil); err == nil {
+		t.Errorf("expected globex to fail closed without a decision of its own, got %+v", result)
+	}
+}
+
+func TestTenantPoolRetriesByDefault(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ClientResponse{Success: true})
+	}))
+	defer server.Close()
+
+	pool, err := NewTenantPool(TenantPoolConfig{Config: AxonFlowConfig{
+		Endpoint: server.URL,
+		APIKey:   "shared-key",
+		Retry:    RetryConfig{InitialDelay: time.Millisecond},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close(context.Background())
+
+	acme, _ := pool.For("acme")
+	if _, err := acme.ExecuteQuery("user", "q", "chat", nil); err != nil {
+		t.Fatalf("expected the 503 to be retried like with New, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if acme.cache == nil {
+		t.Error("expected caching to be on by default")
+	}
+}
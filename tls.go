@@ -0,0 +1,267 @@
+// TLS configuration for connections to AxonFlow
+package axonflow
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCertificatePinMismatch is returned when PinnedSPKI is set and no certificate
+// presented by the server matches a pin. Like other certificate verification
+// failures, it is never retried and never triggers fail-open.
+var ErrCertificatePinMismatch = errors.New("axonflow: server certificate does not match any pinned public key")
+
+// TLSConfig configures TLS for all connections to AxonFlow, including MAP operations.
+// It applies to the default transport, or to a clone of a configured *http.Transport.
+type TLSConfig struct {
+	// RootCAFile and RootCAsPEM hold PEM-encoded CA certificates used to verify the
+	// server. If either is set, they replace the system roots.
+	RootCAFile string
+	RootCAsPEM []byte
+
+	// CertFile and KeyFile hold a PEM-encoded client certificate and key for mTLS.
+	// They are re-read on the next handshake after either file changes, so rotated
+	// certificates are picked up without restarting. If a reload fails (e.g. the files
+	// are mid-rotation), the previous certificate stays in use.
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM are a static client certificate, used when CertFile is unset.
+	CertPEM []byte
+	KeyPEM  []byte
+
+	MinVersion uint16 // Minimum TLS version, e.g. tls.VersionTLS13 (default: tls.VersionTLS12)
+	ServerName string // Overrides the server name used for SNI and certificate verification
+
+	// PinnedSPKI lists base64-encoded SHA-256 hashes of certificate public keys
+	// (SubjectPublicKeyInfo), optionally prefixed with "sha256/". When set, a
+	// connection is only accepted if the server's certificate or a certificate of its
+	// verified chain matches one.
+	PinnedSPKI []string
+
+	// InsecureSkipVerify disables certificate verification. Pins are still checked,
+	// against the server's certificate only. Only use it for local testing.
+	InsecureSkipVerify bool
+}
+
+// build creates the *tls.Config. Problems are reported as *ValidationError.
+func (t *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.MinVersion != 0 {
+		config.MinVersion = t.MinVersion
+	}
+
+	if t.RootCAFile != "" || len(t.RootCAsPEM) > 0 {
+		pool := x509.NewCertPool()
+		if t.RootCAFile != "" {
+			pem, err := os.ReadFile(t.RootCAFile)
+			if err != nil {
+				return nil, tlsError("TLS.RootCAFile", "cannot be read: %v", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, tlsError("TLS.RootCAFile", "contains no PEM certificates: %s", t.RootCAFile)
+			}
+		}
+		if len(t.RootCAsPEM) > 0 && !pool.AppendCertsFromPEM(t.RootCAsPEM) {
+			return nil, tlsError("TLS.RootCAsPEM", "contains no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	switch {
+	case t.CertFile != "" || t.KeyFile != "":
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, tlsError("TLS.CertFile", "and TLS.KeyFile must be set together")
+		}
+		reloader := &certReloader{certFile: t.CertFile, keyFile: t.KeyFile}
+		if err := reloader.reload(); err != nil {
+			return nil, tlsError("TLS.CertFile", "cannot be loaded: %v", err)
+		}
+		config.GetClientCertificate = reloader.clientCertificate
+	case len(t.CertPEM) > 0 || len(t.KeyPEM) > 0:
+		cert, err := tls.X509KeyPair(t.CertPEM, t.KeyPEM)
+		if err != nil {
+			return nil, tlsError("TLS.CertPEM", "cannot be loaded: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(t.PinnedSPKI) > 0 {
+		pins := make(map[string]bool, len(t.PinnedSPKI))
+		for _, pin := range t.PinnedSPKI {
+			sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, "sha256/"))
+			if err != nil || len(sum) != sha256.Size {
+				return nil, tlsError("TLS.PinnedSPKI", "must be base64-encoded SHA-256 hashes, got %q", pin)
+			}
+			pins[string(sum)] = true
+		}
+		config.VerifyConnection = verifyPins(pins)
+	}
+
+	return config, nil
+}
+
+func tlsError(field, format string, args ...interface{}) error {
+	return newValidationError(field, "invalid config: "+field+" "+fmt.Sprintf(format, args...))
+}
+
+// verifyPins accepts a connection if the server's certificate, or a certificate in a
+// verified chain, has a pinned public key. Other certificates the server presents are
+// not checked: they are unverified, and anyone can send a copy of a pinned CA.
+func verifyPins(pins map[string]bool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		matches := func(certs []*x509.Certificate) bool {
+			for _, cert := range certs {
+				sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				if pins[string(sum[:])] {
+					return true
+				}
+			}
+			return false
+		}
+		// The handshake proves the server holds the key of its own certificate
+		if len(cs.PeerCertificates) > 0 && matches(cs.PeerCertificates[:1]) {
+			return nil
+		}
+		// Empty when verification is skipped
+		for _, chain := range cs.VerifiedChains {
+			if matches(chain) {
+				return nil
+			}
+		}
+		return ErrCertificatePinMismatch
+	}
+}
+
+// SPKIPin returns the PinnedSPKI value for a certificate.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// certReloader serves a client certificate from files, reloading it when they change.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func (r *certReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reloadIfChanged(); err != nil && r.cert == nil {
+		return nil, err
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reloadIfChanged()
+}
+
+// reloadIfChanged loads the key pair if it has not been loaded or either file has a
+// new modification time. It must be called with r.mu held.
+func (r *certReloader) reloadIfChanged() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.certMod, r.keyMod = certInfo.ModTime(), keyInfo.ModTime()
+	return nil
+}
+
+// tlsTransport applies config to base, which must be nil (a new transport is created)
+// or an *http.Transport (it is cloned). Invalid settings produce a transport that fails
+// every request, so a client created with NewClient never silently skips them.
+func tlsTransport(base http.RoundTripper, config *TLSConfig) http.RoundTripper {
+	tlsConfig, err := config.build()
+	if err == nil {
+		var t *http.Transport
+		switch b := base.(type) {
+		case nil:
+			t = &http.Transport{}
+		case *http.Transport:
+			t = b.Clone()
+		default:
+			err = tlsError("TLS", "requires the base transport to be an *http.Transport, got %T", base)
+		}
+		if t != nil {
+			t.TLSClientConfig = tlsConfig
+			return t
+		}
+	}
+	return RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, err
+	})
+}
+
+// isCertificateError reports whether err is a certificate verification failure.
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var verification *tls.CertificateVerificationError
+	return errors.Is(err, ErrCertificatePinMismatch) ||
+		errors.As(err, &unknownAuthority) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &verification)
+}
+
+// tlsVersions maps config file names to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses "1.2" or "1.3" (an optional "TLS" prefix is ignored).
+func parseTLSVersion(s string) (uint16, error) {
+	v, ok := tlsVersions[strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q", s)
+	}
+	return v, nil
+}
+
+// tlsVersionName is the inverse of parseTLSVersion.
+func tlsVersionName(v uint16) string {
+	for name, version := range tlsVersions {
+		if version == v {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", v)
+}
@@ -0,0 +1,381 @@
+package axonflow
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPKI is a throwaway certificate authority.
+type testPKI struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pem    []byte
+	serial int64
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Internal CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testPKI{
+		cert:   cert,
+		key:    key,
+		pem:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial: 1,
+	}
+}
+
+// issue returns a PEM certificate and key signed by the CA. Server certificates are
+// valid for 127.0.0.1 and the given DNS names.
+func (p *testPKI) issue(t *testing.T, commonName string, client bool, dnsNames ...string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(p.serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	} else if len(dnsNames) > 0 {
+		template.DNSNames = dnsNames
+	} else {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.cert, &key.PublicKey, p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newTLSServer starts a policy-list server with a certificate from pki. If clientCAs is
+// set, client certificates are required; the last client common name is recorded.
+func newTLSServer(t *testing.T, pki *testPKI, clientCAs *x509.CertPool, dnsNames ...string) (*httptest.Server, func() string) {
+	t.Helper()
+	var mu sync.Mutex
+	var clientCN string
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			mu.Lock()
+			clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+			mu.Unlock()
+		}
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	certPEM, keyPEM := pki.issue(t, "agent", false, dnsNames...)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCAs != nil {
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+		server.TLS.ClientCAs = clientCAs
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return clientCN
+	}
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTLSTestClient(endpoint string, tlsConfig TLSConfig) *AxonFlowClient {
+	return NewClient(AxonFlowConfig{
+		Endpoint: endpoint,
+		ClientID: "test",
+		Retry:    RetryConfig{MaxAttempts: 1},
+		TLS:      &tlsConfig,
+	})
+}
+
+func TestTLSCustomRootCA(t *testing.T) {
+	pki := newTestPKI(t)
+	server, _ := newTLSServer(t, pki, nil)
+	caFile := writeTestFile(t, t.TempDir(), "ca.pem", pki.pem)
+
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAFile: caFile})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("expected the internal CA to be trusted, got %v", err)
+	}
+	if client.mapHttpClient.Transport != client.httpClient.Transport {
+		t.Error("expected MAP requests to use the same TLS transport")
+	}
+
+	client = newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("expected PEM roots to be trusted, got %v", err)
+	}
+}
+
+func TestTLSUntrustedCertificateDoesNotFailOpen(t *testing.T) {
+	server, _ := newTLSServer(t, newTestPKI(t), nil)
+
+	// Trust a different CA
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: newTestPKI(t).pem})
+	resp, err := client.ExecuteQuery("user", "hello", "chat", nil)
+	if err == nil {
+		t.Fatalf("expected a certificate error, got response %+v", resp)
+	}
+	if !isCertificateError(err) {
+		t.Errorf("expected a certificate verification error, got %v", err)
+	}
+	if IsRetryable(err, true) {
+		t.Error("certificate errors must not be retried")
+	}
+}
+
+func TestTLSClientCertificateReload(t *testing.T) {
+	pki := newTestPKI(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(pki.cert)
+	server, clientCN := newTLSServer(t, pki, clientCAs)
+
+	dir := t.TempDir()
+	certPEM, keyPEM := pki.issue(t, "service-v1", true)
+	certFile := writeTestFile(t, dir, "client.pem", certPEM)
+	keyFile := writeTestFile(t, dir, "client-key.pem", keyPEM)
+
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem, CertFile: certFile, KeyFile: keyFile})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	if got := clientCN(); got != "service-v1" {
+		t.Fatalf("expected client certificate service-v1, got %q", got)
+	}
+
+	// Rotate the certificate; the next handshake picks it up
+	certPEM, keyPEM = pki.issue(t, "service-v2", true)
+	writeTestFile(t, dir, "client.pem", certPEM)
+	writeTestFile(t, dir, "client-key.pem", keyPEM)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	os.Chtimes(keyFile, later, later)
+	client.httpClient.CloseIdleConnections()
+
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("request after rotation failed: %v", err)
+	}
+	if got := clientCN(); got != "service-v2" {
+		t.Errorf("expected rotated certificate service-v2, got %q", got)
+	}
+}
+
+func TestTLSReloadKeepsCertificateOnBadFiles(t *testing.T) {
+	pki := newTestPKI(t)
+	dir := t.TempDir()
+	certPEM, keyPEM := pki.issue(t, "service", true)
+	certFile := writeTestFile(t, dir, "client.pem", certPEM)
+	keyFile := writeTestFile(t, dir, "client-key.pem", keyPEM)
+
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := r.clientCertificate(nil)
+
+	// A half-written rotation: new certificate, old key
+	newCert, _ := pki.issue(t, "service-v2", true)
+	writeTestFile(t, dir, "client.pem", newCert)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+
+	after, err := r.clientCertificate(nil)
+	if err != nil || after != before {
+		t.Errorf("expected the previous certificate to stay in use, got %v, %v", after, err)
+	}
+}
+
+func TestTLSServerNameOverride(t *testing.T) {
+	pki := newTestPKI(t)
+	server, _ := newTLSServer(t, pki, nil, "agent.internal")
+
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem})
+	if _, err := client.ListStaticPolicies(nil); err == nil {
+		t.Fatal("expected a hostname mismatch without the override")
+	}
+
+	client = newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem, ServerName: "agent.internal"})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("expected ServerName to be used for verification, got %v", err)
+	}
+}
+
+func TestTLSPinning(t *testing.T) {
+	pki := newTestPKI(t)
+	server, _ := newTLSServer(t, pki, nil)
+
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem, PinnedSPKI: []string{SPKIPin(pki.cert)}})
+	if _, err := client.ListStaticPolicies(nil); err != nil {
+		t.Fatalf("expected the pinned CA key to match, got %v", err)
+	}
+
+	other := newTestPKI(t)
+	client = newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem, PinnedSPKI: []string{SPKIPin(other.cert)}})
+	_, err := client.ListStaticPolicies(nil)
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Fatalf("expected ErrCertificatePinMismatch, got %v", err)
+	}
+	if isUnavailableError(err) {
+		t.Error("a pin mismatch must not count as AxonFlow being unavailable")
+	}
+}
+
+func TestTLSPinningIgnoresUnverifiedCertificates(t *testing.T) {
+	pki, pinned := newTestPKI(t), newTestPKI(t)
+	certPEM, keyPEM := pki.issue(t, "agent", false)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An unpinned leaf followed by a copy of the pinned CA
+	cert.Certificate = append(cert.Certificate, pinned.cert.Raw)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	for _, tlsConfig := range []TLSConfig{
+		{RootCAsPEM: pki.pem, PinnedSPKI: []string{SPKIPin(pinned.cert)}},
+		{InsecureSkipVerify: true, PinnedSPKI: []string{SPKIPin(pinned.cert)}},
+	} {
+		client := newTLSTestClient(server.URL, tlsConfig)
+		if _, err := client.ListStaticPolicies(nil); !errors.Is(err, ErrCertificatePinMismatch) {
+			t.Errorf("expected ErrCertificatePinMismatch (skip verify: %v), got %v", tlsConfig.InsecureSkipVerify, err)
+		}
+	}
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	pki := newTestPKI(t)
+	server, _ := newTLSServer(t, pki, nil)
+	server.TLS.MaxVersion = tls.VersionTLS12
+
+	client := newTLSTestClient(server.URL, TLSConfig{RootCAsPEM: pki.pem, MinVersion: tls.VersionTLS13})
+	if _, err := client.ListStaticPolicies(nil); err == nil {
+		t.Error("expected the handshake to fail below the minimum version")
+	}
+}
+
+func TestTLSValidation(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := writeTestFile(t, dir, "ca.pem", []byte("not a certificate"))
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		field string
+	}{
+		{"missing CA file", []Option{WithTLS(TLSConfig{RootCAFile: filepath.Join(dir, "missing.pem")})}, "TLS.RootCAFile"},
+		{"CA file without certificates", []Option{WithTLS(TLSConfig{RootCAFile: notPEM})}, "TLS.RootCAFile"},
+		{"cert without key", []Option{WithTLS(TLSConfig{CertFile: notPEM})}, "TLS.CertFile"},
+		{"bad PEM pair", []Option{WithTLS(TLSConfig{CertPEM: []byte("x"), KeyPEM: []byte("y")})}, "TLS.CertPEM"},
+		{"bad pin", []Option{WithTLS(TLSConfig{PinnedSPKI: []string{"sha256/short"}})}, "TLS.PinnedSPKI"},
+		{"custom transport", []Option{WithTLS(TLSConfig{}), WithTransport(RoundTripperFunc(nil))}, "TLS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("https://agent.example.com", tt.opts...)
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) || valErr.Field != tt.field {
+				t.Errorf("expected a ValidationError for %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestTLSInvalidConfigFailsRequests(t *testing.T) {
+	client := newTLSTestClient("https://agent.example.com", TLSConfig{RootCAsPEM: []byte("junk")})
+	_, err := client.ListStaticPolicies(nil)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("expected requests to fail with the TLS configuration error, got %v", err)
+	}
+}
+
+func TestTLSFromConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `{"endpoint": "https://agent.example.com", "tls": {"ca_file": "/etc/axonflow/ca.pem", "min_version": "1.3", "server_name": "agent.internal"}}`)
+
+	config, err := LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfigProfile failed: %v", err)
+	}
+	if config.TLS == nil || config.TLS.RootCAFile != "/etc/axonflow/ca.pem" ||
+		config.TLS.MinVersion != tls.VersionTLS13 || config.TLS.ServerName != "agent.internal" {
+		t.Errorf("unexpected TLS config: %+v", config.TLS)
+	}
+
+	t.Setenv(EnvTLSMinVersion, "1.2")
+	t.Setenv(EnvTLSPinnedSPKI, "sha256/a, sha256/b")
+	config, err = LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfigProfile failed: %v", err)
+	}
+	if config.TLS.MinVersion != tls.VersionTLS12 || len(config.TLS.PinnedSPKI) != 2 {
+		t.Errorf("expected environment overrides, got %+v", config.TLS)
+	}
+
+	t.Setenv(EnvTLSMinVersion, "1.4")
+	if _, err := LoadConfigProfile(path, ""); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected an unknown TLS version to be rejected, got %v", err)
+	}
+}
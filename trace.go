@@ -0,0 +1,87 @@
+// W3C Trace Context propagation
+package axonflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// traceParentKey is the context key for an incoming traceparent.
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of ctx carrying a W3C traceparent header value
+// (e.g. from an incoming request). Calls made with the returned context join that
+// trace: AxonFlow receives a traceparent with the same trace ID and a new span ID.
+// Invalid values are ignored.
+func ContextWithTraceParent(ctx context.Context, traceparent string) context.Context {
+	if _, _, _, ok := parseTraceParent(traceparent); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, traceparent)
+}
+
+// TraceParentFromContext returns the traceparent stored by ContextWithTraceParent.
+func TraceParentFromContext(ctx context.Context) (string, bool) {
+	tp, ok := ctx.Value(traceParentKey{}).(string)
+	return tp, ok
+}
+
+// span identifies one call in a trace.
+type span struct {
+	traceID string
+	spanID  string
+	flags   string
+}
+
+// newSpan starts a span for a call, as a child of the trace in ctx if there is one,
+// otherwise as the root of a new, sampled trace.
+func newSpan(ctx context.Context) span {
+	s := span{spanID: randomHex(8), flags: "01"}
+	if tp, ok := TraceParentFromContext(ctx); ok {
+		s.traceID, _, s.flags, _ = parseTraceParent(tp)
+	} else {
+		s.traceID = randomHex(16)
+	}
+	return s
+}
+
+// header returns the traceparent header value for the span.
+func (s span) header() string {
+	return fmt.Sprintf("00-%s-%s-%s", s.traceID, s.spanID, s.flags)
+}
+
+// parseTraceParent parses a version 00 traceparent header value.
+func parseTraceParent(tp string) (traceID, parentID, flags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(tp), "-")
+	if len(parts) != 4 || parts[0] != "00" ||
+		!isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) ||
+		strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", "", false
+	}
+	return parts[1], parts[2], parts[3], true
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; never emit an all-zero ID
+		b[n-1] = 1
+	}
+	return hex.EncodeToString(b)
+}
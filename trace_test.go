@@ -0,0 +1,77 @@
+package axonflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", false},
+		{"garbage", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, _, _, ok := parseTraceParent(tt.value); ok != tt.valid {
+			t.Errorf("parseTraceParent(%q) valid = %v, want %v", tt.value, ok, tt.valid)
+		}
+	}
+}
+
+func TestTraceParentPropagation(t *testing.T) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("traceparent"))
+		json.NewEncoder(w).Encode(staticPoliciesResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClient(AxonFlowConfig{Endpoint: server.URL, ClientID: "test"})
+
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	ctx := ContextWithTraceParent(context.Background(), incoming)
+	client.ListStaticPoliciesContext(ctx, nil)
+	client.ListStaticPoliciesContext(context.Background(), nil)
+
+	if len(headers) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(headers))
+	}
+
+	traceID, spanID, flags, ok := parseTraceParent(headers[0])
+	if !ok {
+		t.Fatalf("invalid traceparent sent: %q", headers[0])
+	}
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || flags != "00" {
+		t.Errorf("expected the caller's trace and flags, got %q", headers[0])
+	}
+	if spanID == "00f067aa0ba902b7" {
+		t.Error("expected a new span ID for the outgoing call")
+	}
+
+	if _, _, flags, ok := parseTraceParent(headers[1]); !ok || flags != "01" {
+		t.Errorf("expected a new sampled trace without a caller trace, got %q", headers[1])
+	}
+	if strings.Contains(headers[1], "4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Error("expected a fresh trace ID without a caller trace")
+	}
+}
+
+func TestContextWithInvalidTraceParent(t *testing.T) {
+	ctx := ContextWithTraceParent(context.Background(), "not-a-traceparent")
+	if _, ok := TraceParentFromContext(ctx); ok {
+		t.Error("expected an invalid traceparent to be ignored")
+	}
+}
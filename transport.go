@@ -0,0 +1,381 @@
+// HTTP transport shared by all AxonFlow API calls
+package axonflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// authMode selects how a request is authenticated.
+type authMode int
+
+const (
+	// authClient sends X-Tenant-ID and the Authenticator's credentials (see addAuthHeaders)
+	authClient authMode = iota
+	// authNone sends no credentials
+	authNone
+	// authSession sends the Customer Portal session cookie
+	authSession
+)
+
+// request describes a single AxonFlow API call. The body is kept as bytes so that
+// the HTTP request can be rebuilt for every retry attempt.
+type request struct {
+	method string
+	url    string
+	body   []byte
+	// contentType is set as the Content-Type header when non-empty
+	contentType string
+	auth        authMode
+	// client overrides c.httpClient (MAP requests use the longer-timeout client)
+	client *http.Client
+	// retrySafe marks a POST as safe to repeat, e.g. a policy evaluation with no
+	// side effects. GET, HEAD, PUT and DELETE are always treated as idempotent.
+	retrySafe bool
+	// requestType is the governance request type reported to the Observer, if any
+	requestType string
+	// traceParent is the W3C traceparent header value, set by send
+	traceParent string
+	// endpoint is the Agent URL the current attempt is sent to, replacing the
+	// configured Endpoint in url (see Endpoints). Empty with a single endpoint.
+	endpoint string
+	// user is the user token the call is made for, whose quota it counts against
+	user string
+}
+
+// newRequest creates a request for the given method and full URL.
+func newRequest(method, fullURL string) *request {
+	return &request{method: method, url: fullURL}
+}
+
+// newJSONRequest creates a request with body marshalled as JSON (nil means no body).
+func newJSONRequest(method, fullURL string, body interface{}) (*request, error) {
+	r := &request{method: method, url: fullURL, contentType: "application/json"}
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		r.body = b
+	}
+	return r, nil
+}
+
+// idempotent reports whether the request may be repeated without side effects.
+func (r *request) idempotent() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return r.retrySafe
+}
+
+// path returns the URL path of the request, for logging and retry decisions.
+func (r *request) path() string {
+	if u, err := url.Parse(r.url); err == nil {
+		return u.Path
+	}
+	return r.url
+}
+
+// build creates the *http.Request for one attempt.
+func (c *AxonFlowClient) build(ctx context.Context, r *request) (*http.Request, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, c.targetURL(r), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.traceParent != "" {
+		req.Header.Set("traceparent", r.traceParent)
+	}
+
+	switch r.auth {
+	case authClient:
+		if err := c.addAuthHeaders(ctx, req); err != nil {
+			return nil, err
+		}
+	case authSession:
+		req.AddCookie(&http.Cookie{
+			Name:  "axonflow_session",
+			Value: c.sessionCookie,
+		})
+	}
+
+	return req, nil
+}
+
+// send performs r, retrying transient failures according to the client's RetryPolicy.
+// Every attempt passes through the rate limiter and the circuit breaker, if enabled; while it is open send
+// returns ErrCircuitOpen without contacting AxonFlow. Each call is a span in the
+// caller's trace (see ContextWithTraceParent) and is reported to the Observer.
+//
+// The response body is read in full and replaced with an in-memory reader, so callers
+// may read it (and close it) as usual. Non-2xx responses are returned with a nil error;
+// callers turn them into typed errors with newAPIError. A non-nil error means no
+// response was received, or the request could not be built.
+func (c *AxonFlowClient) send(ctx context.Context, r *request) (*http.Response, error) {
+	if err := c.checkOpen(ctx); err != nil {
+		return nil, err
+	}
+
+	sp := newSpan(ctx)
+	r.traceParent = sp.header()
+
+	event := ObserverEvent{
+		Method:      r.method,
+		Endpoint:    r.path(),
+		RequestType: r.requestType,
+		TraceID:     sp.traceID,
+		SpanID:      sp.spanID,
+	}
+	c.observer.OnRequestStart(ctx, event)
+
+	start := time.Now()
+	resp, attempts, err := c.sendWithRetry(ctx, r, event)
+	if rt := routeFrom(ctx); rt != nil && resp != nil {
+		rt.served = r.endpoint
+	}
+
+	event.Attempt = attempts
+	event.Duration = time.Since(start)
+	event.Err = err
+	if resp != nil {
+		event.Status = resp.StatusCode
+		if resp.StatusCode >= 400 {
+			event.Err = newAPIError(resp, peekBody(resp))
+		}
+	}
+	c.observer.OnRequestEnd(ctx, event)
+
+	return resp, err
+}
+
+// sendWithRetry runs the attempts of send and returns the number of attempts made.
+func (c *AxonFlowClient) sendWithRetry(ctx context.Context, r *request, event ObserverEvent) (*http.Response, int, error) {
+	client := r.client
+	if client == nil {
+		client = c.httpClient
+	}
+
+	// With several endpoints, each attempt goes to an endpoint this call has not
+	// tried yet, if possible
+	var tried map[string]bool
+	var pinned string
+	if c.balancer != nil && strings.HasPrefix(r.url, c.config.Endpoint) {
+		tried = make(map[string]bool)
+		if rt := routeFrom(ctx); rt != nil {
+			pinned = rt.pinned
+		}
+	}
+
+	reauthenticated := false
+	for attempt := 1; ; attempt++ {
+		// Every attempt, including retries, uses up quota
+		if r.auth == authClient {
+			if err := c.limiter.acquire(ctx, c.quotaKey(r)); err != nil {
+				c.logger.DebugContext(ctx, "AxonFlow request throttled",
+					"method", r.method,
+					"path", r.path(),
+					"attempt", attempt,
+					"error", err)
+				return nil, attempt, err
+			}
+		}
+		if c.breaker != nil {
+			if err := c.breaker.allow(); err != nil {
+				return nil, attempt, err
+			}
+		}
+		if tried != nil {
+			r.endpoint = c.balancer.pick(pinned, tried)
+		}
+
+		start := time.Now()
+		resp, err := c.sendOnce(ctx, client, r)
+		duration := time.Since(start)
+		c.logAttempt(ctx, r, attempt, resp, err, duration)
+		if resp != nil && r.auth == authClient {
+			c.limiter.observe(c.quotaKey(r), resp)
+		}
+
+		// Decide whether the attempt failed in a way worth retrying
+		failure := err
+		if err == nil && resp.StatusCode >= 400 {
+			failure = newAPIError(resp, peekBody(resp))
+		}
+		if c.breaker != nil {
+			c.breaker.done(failure)
+		}
+		if tried != nil && ctx.Err() == nil {
+			c.balancer.report(r.endpoint, failure, duration, false)
+			if isUnavailableError(failure) {
+				tried[r.endpoint] = true
+			}
+		}
+		if failure == nil || ctx.Err() != nil {
+			return resp, attempt, err
+		}
+
+		// Expired credentials: refresh them and try once more, regardless of the
+		// retry policy
+		if inv, ok := c.auth.(Invalidator); ok && r.auth == authClient && !reauthenticated &&
+			resp != nil && resp.StatusCode == http.StatusUnauthorized && resp.Request != nil {
+			reauthenticated = true
+			inv.Invalidate(resp.Request)
+			c.logger.DebugContext(ctx, "AxonFlow rejected credentials, refreshing",
+				"method", r.method,
+				"path", r.path())
+			continue
+		}
+
+		if c.retryPolicy == nil {
+			return resp, attempt, err
+		}
+
+		delay, retry := c.retryPolicy.Backoff(RetryAttempt{
+			Attempt:    attempt,
+			Method:     r.method,
+			Path:       r.path(),
+			Idempotent: r.idempotent(),
+			Err:        failure,
+		})
+		if !retry {
+			return resp, attempt, err
+		}
+		if tried != nil && c.balancer.hasUntried(tried) {
+			// Another agent can take the request right away
+			delay = 0
+		}
+
+		event.Attempt = attempt
+		event.Duration = duration
+		event.Err = failure
+		event.Status = 0
+		if resp != nil {
+			event.Status = resp.StatusCode
+		}
+		c.observer.OnRetry(ctx, event)
+
+		c.logger.WarnContext(ctx, "Retrying AxonFlow request",
+			"method", r.method,
+			"path", r.path(),
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", failure)
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return nil, attempt, sleepErr
+		}
+	}
+}
+
+// quotaKey returns the rate limit quota r counts against.
+func (c *AxonFlowClient) quotaKey(r *request) quotaKey {
+	return quotaKey{tenant: c.config.ClientID, user: r.user}
+}
+
+// logAttempt logs the outcome of a single attempt at debug level.
+func (c *AxonFlowClient) logAttempt(ctx context.Context, r *request, attempt int, resp *http.Response, err error, duration time.Duration) {
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", r.method),
+		slog.String("path", r.path()),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if r.endpoint != "" {
+		attrs = append(attrs, slog.String("endpoint", r.endpoint))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	} else {
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode),
+			slog.String("request_id", resp.Header.Get("X-Request-ID")))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "AxonFlow HTTP request", attrs...)
+}
+
+// sendOnce performs a single attempt and buffers the response body.
+func (c *AxonFlowClient) sendOnce(ctx context.Context, client *http.Client, r *request) (*http.Response, error) {
+	req, err := c.build(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// peekBody returns the buffered body of a response returned by send without
+// consuming it.
+func peekBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// sendJSON performs r and decodes a successful JSON response into result (if non-nil).
+// Non-2xx responses are returned as typed errors; empty and 204 responses leave result
+// untouched.
+func (c *AxonFlowClient) sendJSON(ctx context.Context, r *request, result interface{}) error {
+	body, err := c.sendRaw(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sendRaw performs r and returns the body of a successful response.
+func (c *AxonFlowClient) sendRaw(ctx context.Context, r *request) ([]byte, error) {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp, body)
+	}
+
+	return body, nil
+}